@@ -0,0 +1,118 @@
+package apikey
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/utils"
+	"github.com/lib/pq"
+)
+
+// API keys let integrations such as a recruiter's ATS call the API without a
+// password. A key is Prefix followed by 32 random bytes in hex. Only its
+// SHA-256 hash is stored, plus the first characters so users can tell keys apart.
+//
+// A key acts as its owner, limited to its scopes, and only on routes that
+// accept that scope. Other services check keys through the introspection
+// endpoint; auth-service looks them up directly.
+
+// Prefix marks a bearer token as an API key rather than a JWT
+const Prefix = "jpk_"
+
+// Scopes a key can be granted
+const (
+	ScopeJobsWrite        = "jobs:write"
+	ScopeApplicationsRead = "applications:read"
+)
+
+// Scopes lists every grantable scope
+var Scopes = []string{ScopeJobsWrite, ScopeApplicationsRead}
+
+// ErrInvalidKey is returned for unknown, revoked or expired keys and for keys of banned users
+var ErrInvalidKey = errors.New("invalid API key")
+
+// Identity is who an API key acts as
+type Identity struct {
+	KeyID         string   `json:"key_id"`
+	UserID        string   `json:"user_id"`
+	Email         string   `json:"email"`
+	Role          string   `json:"role"`
+	EmailVerified bool     `json:"email_verified"`
+	Scopes        []string `json:"scopes"`
+}
+
+// IsAPIKey reports whether a bearer token looks like an API key
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, Prefix)
+}
+
+// ValidScope reports whether scope can be granted
+func ValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingScope returns the first of the required scopes the identity lacks, or ""
+func (id *Identity) MissingScope(required ...string) string {
+	for _, want := range required {
+		found := false
+		for _, have := range id.Scopes {
+			if have == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return want
+		}
+	}
+	return ""
+}
+
+// Generate creates a new key, returning it with its display prefix and hash
+func Generate() (key, displayPrefix, hash string, err error) {
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", "", "", err
+	}
+	key = Prefix + secret
+	return key, key[:len(Prefix)+8], utils.HashToken(key), nil
+}
+
+// Lookup resolves a key to its owner. Last use is recorded at most once a minute.
+func Lookup(key string) (*Identity, error) {
+	if !IsAPIKey(key) {
+		return nil, ErrInvalidKey
+	}
+
+	var id Identity
+	err := config.DB.QueryRow(`
+		SELECT k.id, u.id, u.email, u.role, u.email_verified, k.scopes
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.key_hash = $1
+		  AND k.revoked_at IS NULL AND k.expires_at > CURRENT_TIMESTAMP
+		  AND u.banned_at IS NULL
+	`, utils.HashToken(key)).Scan(&id.KeyID, &id.UserID, &id.Email, &id.Role, &id.EmailVerified, pq.Array(&id.Scopes))
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidKey
+	} else if err != nil {
+		return nil, err
+	}
+
+	_, err = config.DB.Exec(`
+		UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')
+	`, id.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &id, nil
+}
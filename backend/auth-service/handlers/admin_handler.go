@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"database/sql"
+	"io"
+	"log"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/models"
+)
+
+// BanUser suspends an account and revokes all of its sessions (admin only)
+func BanUser(c *gin.Context) {
+	userID := c.Param("id")
+
+	// The body is optional; it only carries the reason
+	var req models.BanUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userID == c.GetString("user_id") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot ban your own account"})
+		return
+	}
+
+	var name string
+	err := config.DB.QueryRow(`
+		UPDATE users
+		SET banned_at = CURRENT_TIMESTAMP, ban_reason = NULLIF($1, ''), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING name
+	`, req.Reason, userID).Scan(&name)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	} else if err != nil {
+		log.Printf("BanUser: update error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to ban user"})
+		return
+	}
+
+	if err := revokeAllSessions(userID); err != nil {
+		log.Printf("BanUser: failed to revoke sessions for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User banned but sessions could not be revoked"})
+		return
+	}
+
+	log.Printf("User %s banned by admin %s", userID, c.GetString("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "User banned successfully", "user_name": name})
+}
+
+// UnbanUser lifts a suspension (admin only)
+func UnbanUser(c *gin.Context) {
+	userID := c.Param("id")
+
+	result, err := config.DB.Exec(`
+		UPDATE users
+		SET banned_at = NULL, ban_reason = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, userID)
+	if err != nil {
+		log.Printf("UnbanUser: update error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unban user"})
+		return
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	log.Printf("User %s unbanned by admin %s", userID, c.GetString("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "User unbanned successfully"})
+}
+
+// ChangeUserRole moves a user to another role and records who did it and why
+// (admin only). The user's access tokens are revoked so the new role applies
+// from their next refresh.
+func ChangeUserRole(c *gin.Context) {
+	userID := c.Param("id")
+	adminID := c.GetString("user_id")
+
+	var req models.ChangeRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if userID == adminID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot change your own role"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	var oldRole string
+	err = tx.QueryRow("SELECT role FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&oldRole)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if oldRole == req.Role {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User already has this role"})
+		return
+	}
+
+	_, err = tx.Exec("UPDATE users SET role = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", req.Role, userID)
+	if err != nil {
+		log.Printf("ChangeUserRole: update error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change role"})
+		return
+	}
+
+	if err := recordRoleChange(tx, userID, oldRole, req.Role, adminID, "admin", req.Reason); err != nil {
+		log.Printf("ChangeUserRole: audit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change role"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change role"})
+		return
+	}
+
+	if err := revokeUserTokens(userID); err != nil {
+		log.Printf("ChangeUserRole: failed to revoke tokens for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Role changed but existing tokens could not be revoked"})
+		return
+	}
+
+	log.Printf("User %s role changed from %s to %s by admin %s", userID, oldRole, req.Role, adminID)
+	c.JSON(http.StatusOK, gin.H{"message": "Role changed successfully", "old_role": oldRole, "role": req.Role})
+}
+
+// GetRoleChanges lists a user's role change history, newest first (admin only)
+func GetRoleChanges(c *gin.Context) {
+	rows, err := config.DB.Query(`
+		SELECT id, old_role, new_role, changed_by, source, reason, created_at
+		FROM role_change_audit
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	changes := []models.RoleChange{}
+	for rows.Next() {
+		var rc models.RoleChange
+		if err := rows.Scan(&rc.ID, &rc.OldRole, &rc.NewRole, &rc.ChangedBy, &rc.Source, &rc.Reason, &rc.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		changes = append(changes, rc)
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// GetUserLockout shows whether an account is locked out after failed logins (admin only)
+func GetUserLockout(c *gin.Context) {
+	email, ok := lookupUserEmail(c)
+	if !ok {
+		return
+	}
+
+	retryAfter, err := loginEmailLimiter.retryAfter(email)
+	if err != nil {
+		log.Printf("GetUserLockout: redis error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lockout status"})
+		return
+	}
+	locked, err := config.RedisClient.Exists(config.Ctx, loginEmailLimiter.keys(email)[2]).Result()
+	if err != nil {
+		log.Printf("GetUserLockout: redis error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lockout status"})
+		return
+	}
+	attempts, err := loginEmailLimiter.attempts(email)
+	if err != nil {
+		log.Printf("GetUserLockout: redis error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lockout status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LockoutStatus{
+		Locked:         locked == 1,
+		RetryAfter:     int64(math.Ceil(retryAfter.Seconds())),
+		FailedAttempts: attempts,
+	})
+}
+
+// UnlockUser clears an account's failed logins and lockout (admin only)
+func UnlockUser(c *gin.Context) {
+	email, ok := lookupUserEmail(c)
+	if !ok {
+		return
+	}
+
+	if err := loginEmailLimiter.reset(email); err != nil {
+		log.Printf("UnlockUser: redis error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+
+	log.Printf("User %s unlocked by admin %s", c.Param("id"), c.GetString("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+// lookupUserEmail resolves the :id route param to the user's email, answering 404 if absent
+func lookupUserEmail(c *gin.Context) (string, bool) {
+	var email string
+	err := config.DB.QueryRow("SELECT email FROM users WHERE id = $1", c.Param("id")).Scan(&email)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return "", false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return "", false
+	}
+	return email, true
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/apikey"
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/models"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultAPIKeyTTL = 90 * 24 * time.Hour
+	maxActiveAPIKeys = 10
+	apiKeyColumns    = "id, name, key_prefix, scopes, expires_at, last_used_at, revoked_at, created_at"
+)
+
+// CreateAPIKey issues an API key for the signed-in user. The key itself is only
+// returned here.
+func CreateAPIKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !apikey.ValidScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope: " + scope, "valid_scopes": apikey.Scopes})
+			return
+		}
+	}
+
+	userID := c.GetString("user_id")
+	var active int
+	err := config.DB.QueryRow(`
+		SELECT COUNT(*) FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+	`, userID).Scan(&active)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if active >= maxActiveAPIKeys {
+		c.JSON(http.StatusConflict, gin.H{"error": "Too many active API keys; revoke one first"})
+		return
+	}
+
+	key, prefix, hash, err := apikey.Generate()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+
+	ttl := defaultAPIKeyTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+
+	var resp models.CreateAPIKeyResponse
+	err = config.DB.QueryRow(`
+		INSERT INTO api_keys (user_id, name, key_prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+apiKeyColumns,
+		userID, req.Name, prefix, hash, pq.Array(req.Scopes), time.Now().Add(ttl)).
+		Scan(&resp.ID, &resp.Name, &resp.Prefix, pq.Array(&resp.Scopes), &resp.ExpiresAt,
+			&resp.LastUsedAt, &resp.RevokedAt, &resp.CreatedAt)
+	if err != nil {
+		log.Printf("CreateAPIKey: insert error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+	resp.Key = key
+
+	log.Printf("API key %s (%s) created by user %s", resp.ID, resp.Prefix, userID)
+	c.JSON(http.StatusCreated, resp)
+}
+
+// ListAPIKeys lists the signed-in user's API keys, without the keys themselves
+func ListAPIKeys(c *gin.Context) {
+	rows, err := config.DB.Query(`
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var k models.APIKey
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, pq.Array(&k.Scopes), &k.ExpiresAt,
+			&k.LastUsedAt, &k.RevokedAt, &k.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		keys = append(keys, k)
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey permanently disables one of the signed-in user's API keys
+func RevokeAPIKey(c *gin.Context) {
+	result, err := config.DB.Exec(`
+		UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`, c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	log.Printf("API key %s revoked by user %s", c.Param("id"), c.GetString("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked successfully"})
+}
+
+// IntrospectAPIKey tells other services who an API key belongs to and what it
+// may do. It is only mounted on the internal router. Unknown keys get 404, so
+// callers can tell them apart from a rejected service token.
+func IntrospectAPIKey(c *gin.Context) {
+	var req models.IntrospectAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	identity, err := apikey.Lookup(req.Key)
+	if err == apikey.ErrInvalidKey {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invalid or expired API key"})
+		return
+	} else if err != nil {
+		log.Printf("IntrospectAPIKey: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, identity)
+}
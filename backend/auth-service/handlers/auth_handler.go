@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"fmt"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -45,27 +47,23 @@ func Register(c *gin.Context) {
 	query := `
 		INSERT INTO users (name, email, password_hash, phone, role)
 		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, name, email, phone, role, created_at, updated_at
+		RETURNING id, name, email, phone, role, email_verified, created_at, updated_at
 	`
 	err = config.DB.QueryRow(query, req.Name, req.Email, hashedPassword, req.Phone, req.Role).
-		Scan(&user.ID, &user.Name, &user.Email, &user.Phone, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+		Scan(&user.ID, &user.Name, &user.Email, &user.Phone, &user.Role, &user.EmailVerified, &user.CreatedAt, &user.UpdatedAt)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-		return
+	// The account works right away, but stays unverified until the link is clicked
+	if err := sendVerificationEmail(user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
 	}
 
-	c.JSON(http.StatusCreated, models.AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	// Roles that require 2FA enroll before getting a session
+	respondWithSessionStatus(c, user, http.StatusCreated)
 }
 
 // Login handles user authentication
@@ -76,18 +74,26 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	ip := c.ClientIP()
+	if rejectIfLimited(c, limitCheck{loginEmailLimiter, req.Email}, limitCheck{loginIPLimiter, ip}) {
+		return
+	}
+
 	// Get user from database
 	var user models.User
 	var bio, resumeURL, profilePicURL sql.NullString
 	query := `
-		SELECT id, name, email, password_hash, phone, role, bio, resume_url, profile_pic_url, created_at, updated_at
+		SELECT id, name, email, password_hash, phone, role, bio, resume_url, profile_pic_url,
+		       email_verified, mfa_enabled, banned_at, deletion_scheduled_for, created_at, updated_at
 		FROM users WHERE email = $1
 	`
 	err := config.DB.QueryRow(query, req.Email).
 		Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.Phone, &user.Role,
-			&bio, &resumeURL, &profilePicURL, &user.CreatedAt, &user.UpdatedAt)
+			&bio, &resumeURL, &profilePicURL, &user.EmailVerified, &user.MFAEnabled, &user.BannedAt,
+			&user.DeletionScheduledFor, &user.CreatedAt, &user.UpdatedAt)
 
 	if err == sql.ErrNoRows {
+		recordFailedLogin(req.Email, ip, nil)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	} else if err != nil {
@@ -108,24 +114,139 @@ func Login(c *gin.Context) {
 
 	// Check password
 	if err := utils.CheckPassword(user.PasswordHash, req.Password); err != nil {
+		recordFailedLogin(req.Email, ip, &user)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role)
+	if err := loginEmailLimiter.reset(req.Email); err != nil {
+		log.Printf("Failed to reset login attempts for %s: %v", req.Email, err)
+	}
+
+	respondWithSession(c, user)
+}
+
+// respondWithSession finishes a login once the first factor checks out:
+// suspended accounts are refused, and accounts that need 2FA get an
+// mfa_pending challenge instead of a session
+func respondWithSession(c *gin.Context, user models.User) {
+	respondWithSessionStatus(c, user, http.StatusOK)
+}
+
+// respondWithSessionStatus is respondWithSession answering with the given
+// success status, e.g. 201 for a new account
+func respondWithSessionStatus(c *gin.Context, user models.User, status int) {
+	if user.BannedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account has been suspended"})
+		return
+	}
+
+	// Two-step login: the first factor alone only earns an mfa_pending token
+	mfaRequired, err := mfaRequiredForRole(user.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if user.MFAEnabled || mfaRequired {
+		mfaToken, err := utils.GenerateMFAPendingToken(user.ID, !user.MFAEnabled)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+		c.JSON(status, models.MFAChallengeResponse{
+			MFARequired:        true,
+			MFAToken:           mfaToken,
+			EnrollmentRequired: !user.MFAEnabled,
+			ExpiresIn:          int64(utils.MFAPendingTTL.Seconds()),
+		})
+		return
+	}
+
+	// Generate access and refresh tokens
+	resp, err := issueAuthResponse(user, clientInfoFrom(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(status, resp)
+}
+
+// RefreshToken exchanges a refresh token for a new access/refresh token pair
+func RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, sessionID, refreshToken, err := rotateRefreshToken(req.RefreshToken)
+	if err == errInvalidRefreshToken || err == errRefreshTokenReused {
+		if err == errRefreshTokenReused {
+			log.Printf("Refresh token reuse detected for user %s, session revoked", userID)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	// Reload the user so role changes take effect on the next access token
+	user, err := getUserByID(userID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if user.BannedAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account has been suspended"})
+		return
+	}
+
+	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role, user.EmailVerified, sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
 	c.JSON(http.StatusOK, models.AuthResponse{
-		Token: token,
-		User:  user,
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(utils.AccessTokenTTL().Seconds()),
+		User:         user,
 	})
 }
 
-// ForgotPassword generates a reset token and sends email via Kafka
+// Logout revokes the session the refresh token belongs to
+func Logout(c *gin.Context) {
+	var req models.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := revokeRefreshToken(req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	// Also revoke the caller's access token so it stops working right away
+	if parts := strings.Split(c.GetHeader("Authorization"), " "); len(parts) == 2 && parts[0] == "Bearer" {
+		if claims, err := utils.ValidateJWT(parts[1]); err == nil {
+			if err := revokeAccessToken(claims); err != nil {
+				log.Printf("Failed to revoke access token %s: %v", claims.ID, err)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// ForgotPassword generates a reset token and emails a reset link via Kafka
 func ForgotPassword(c *gin.Context) {
 	var req models.ForgotPasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -133,12 +254,27 @@ func ForgotPassword(c *gin.Context) {
 		return
 	}
 
+	// Don't reveal if user exists or not
+	response := gin.H{"message": "If the email exists, a reset link will be sent"}
+
+	// Every request counts, whether or not the account exists
+	ip := c.ClientIP()
+	if rejectIfLimited(c, limitCheck{forgotPasswordEmailLimiter, req.Email}, limitCheck{forgotPasswordIPLimiter, ip}) {
+		return
+	}
+	if _, err := forgotPasswordEmailLimiter.record(req.Email); err != nil {
+		log.Printf("Failed to record password reset request for %s: %v", req.Email, err)
+	}
+	if _, err := forgotPasswordIPLimiter.record(ip); err != nil {
+		log.Printf("Failed to record password reset request for IP %s: %v", ip, err)
+	}
+
 	// Check if user exists
-	var userID, userName string
-	err := config.DB.QueryRow("SELECT id, name FROM users WHERE email = $1", req.Email).Scan(&userID, &userName)
+	var userID, userName, userEmail string
+	err := config.DB.QueryRow("SELECT id, name, email FROM users WHERE email = $1", req.Email).
+		Scan(&userID, &userName, &userEmail)
 	if err == sql.ErrNoRows {
-		// Don't reveal if user exists or not
-		c.JSON(http.StatusOK, gin.H{"message": "If the email exists, a reset link will be sent"})
+		c.JSON(http.StatusOK, response)
 		return
 	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
@@ -150,20 +286,18 @@ func ForgotPassword(c *gin.Context) {
 
 	// Store token in Redis with 15-minute expiration
 	key := fmt.Sprintf("reset_token:%s", resetToken)
-	err = config.RedisClient.Set(config.Ctx, key, userID, 15*time.Minute).Err()
+	err = config.RedisClient.Set(config.Ctx, key, userID, resetTokenTTL).Err()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store reset token"})
 		return
 	}
 
-	// TODO: Send email via Kafka
-	// For now, return the token (in production, send via email)
-	// kafka.ProduceEmailEvent(userEmail, "Password Reset", resetToken)
+	// A delivery failure is only logged so the response doesn't depend on the account existing
+	if err := sendPasswordResetEmail(userName, userEmail, resetToken); err != nil {
+		log.Printf("Failed to send password reset email to %s: %v", userEmail, err)
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "If the email exists, a reset link will be sent",
-		"token":   resetToken, // Remove in production
-	})
+	c.JSON(http.StatusOK, response)
 }
 
 // ResetPassword resets password using the token
@@ -174,10 +308,18 @@ func ResetPassword(c *gin.Context) {
 		return
 	}
 
+	ip := c.ClientIP()
+	if rejectIfLimited(c, limitCheck{resetPasswordIPLimiter, ip}) {
+		return
+	}
+
 	// Get user ID from Redis
 	key := fmt.Sprintf("reset_token:%s", req.Token)
 	userID, err := config.RedisClient.Get(config.Ctx, key).Result()
 	if err != nil {
+		if _, err := resetPasswordIPLimiter.record(ip); err != nil {
+			log.Printf("Failed to record reset attempt for IP %s: %v", ip, err)
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 		return
 	}
@@ -200,9 +342,41 @@ func ResetPassword(c *gin.Context) {
 	// Delete token from Redis
 	config.RedisClient.Del(config.Ctx, key)
 
+	// Sign the user out everywhere; whoever had the old password loses access
+	if err := revokeAllSessions(userID); err != nil {
+		log.Printf("Failed to revoke sessions for user %s: %v", userID, err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
 }
 
+// getUserByID loads a user profile by ID
+func getUserByID(userID string) (models.User, error) {
+	var user models.User
+	var phone, bio, resumeURL, profilePicURL sql.NullString
+	query := `
+		SELECT id, name, email, phone, role, bio, resume_url, profile_pic_url,
+		       email_verified, mfa_enabled, banned_at, deletion_scheduled_for, created_at, updated_at
+		FROM users WHERE id = $1
+	`
+	err := config.DB.QueryRow(query, userID).
+		Scan(&user.ID, &user.Name, &user.Email, &phone, &user.Role,
+			&bio, &resumeURL, &profilePicURL, &user.EmailVerified, &user.MFAEnabled, &user.BannedAt,
+			&user.DeletionScheduledFor, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	user.Phone = phone.String
+	user.Bio = bio.String
+	user.ResumeURL = resumeURL.String
+	user.ProfilePicURL = profilePicURL.String
+	return user, nil
+}
+
+// resetTokenTTL is how long a password reset link stays valid
+const resetTokenTTL = 15 * time.Minute
+
 // generateResetToken generates a secure random token
 func generateResetToken() string {
 	bytes := make([]byte, 32)
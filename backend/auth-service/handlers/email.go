@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/job-portal/auth-service/kafka"
+	"github.com/job-portal/auth-service/models"
+	"github.com/job-portal/auth-service/utils"
+)
+
+// Emails are published as kafka.EmailEvent to the email-notifications topic
+// and delivered by utility-service's consumer.
+
+// frontendURL returns the base URL used in links sent by email (FRONTEND_URL)
+func frontendURL() string {
+	if base := os.Getenv("FRONTEND_URL"); base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	return "http://localhost:3000"
+}
+
+// frontendLink builds a frontend URL carrying a token in its query string
+func frontendLink(path, token string) string {
+	return fmt.Sprintf("%s%s?token=%s", frontendURL(), path, url.QueryEscape(token))
+}
+
+// sendVerificationEmail queues an email with a signed link confirming the user's address
+func sendVerificationEmail(user models.User) error {
+	token, err := utils.GenerateEmailVerificationToken(user.ID, user.Email)
+	if err != nil {
+		return err
+	}
+
+	return kafka.PublishEmail(kafka.EmailEvent{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\n"+
+			"The link expires in 24 hours. If you didn't create an account, you can ignore this email.",
+			user.Name, frontendLink("/verify-email", token)),
+		Type: "email-verification",
+	})
+}
+
+// sendPasswordResetEmail queues an email with a link to choose a new password
+func sendPasswordResetEmail(name, email, resetToken string) error {
+	return kafka.PublishEmail(kafka.EmailEvent{
+		To:      email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\n"+
+			"The link expires in %d minutes. If you didn't ask for a reset, you can ignore this email.",
+			name, frontendLink("/reset-password", resetToken), int(resetTokenTTL.Minutes())),
+		Type: "password-reset",
+	})
+}
+
+// sendMagicLinkEmail queues an email with a single-use sign-in link
+func sendMagicLinkEmail(name, email, token string) error {
+	return kafka.PublishEmail(kafka.EmailEvent{
+		To:      email,
+		Subject: "Your sign-in link",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen the link below in the same browser you requested it from to sign in:\n\n%s\n\n"+
+			"The link works once and expires in %d minutes. If you didn't ask to sign in, you can ignore this email.",
+			name, frontendLink("/magic-link", token), int(magicLinkTTL.Minutes())),
+		Type: "magic-link",
+	})
+}
+
+// sendAccountLockedEmail tells the owner their account was locked after failed logins
+func sendAccountLockedEmail(name, email string) error {
+	return kafka.PublishEmail(kafka.EmailEvent{
+		To:      email,
+		Subject: "Your account has been temporarily locked",
+		Body: fmt.Sprintf("Hi %s,\n\nWe locked your account for %d minutes after several failed sign-in attempts.\n\n"+
+			"If this wasn't you, consider resetting your password:\n\n%s/forgot-password",
+			name, int(loginEmailLimiter.lockout.Minutes()), frontendURL()),
+		Type: "account-locked",
+	})
+}
+
+// sendPasswordChangedEmail tells the owner their password was changed from a signed-in session
+func sendPasswordChangedEmail(name, email string) error {
+	return kafka.PublishEmail(kafka.EmailEvent{
+		To:      email,
+		Subject: "Your password was changed",
+		Body: fmt.Sprintf("Hi %s,\n\nThe password for your account was just changed and your other sessions were signed out.\n\n"+
+			"If this wasn't you, reset your password right away:\n\n%s/forgot-password",
+			name, frontendURL()),
+		Type: "password-changed",
+	})
+}
+
+// sendInvitationEmail queues an invitation with its signed sign-up link
+func sendInvitationEmail(inv models.Invitation, token string) error {
+	return kafka.PublishEmail(kafka.EmailEvent{
+		To:      inv.Email,
+		Subject: "You've been invited to create an account",
+		Body: fmt.Sprintf("Hi,\n\nYou've been invited to create an account with the %s role. Open the link below to sign up:\n\n%s\n\n"+
+			"The link expires on %s. If you weren't expecting this, you can ignore this email.",
+			inv.Role, frontendLink("/accept-invitation", token), inv.ExpiresAt.UTC().Format("2 Jan 2006 15:04 MST")),
+		Type: "role-invitation",
+	})
+}
+
+// sendAccountDeletionScheduledEmail confirms a deletion request and how to cancel it
+func sendAccountDeletionScheduledEmail(name, email string, scheduledFor time.Time) error {
+	return kafka.PublishEmail(kafka.EmailEvent{
+		To:      email,
+		Subject: "Your account is scheduled for deletion",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account and its data will be permanently deleted on %s.\n\n"+
+			"Changed your mind? Sign in before then and cancel the deletion from your account settings:\n\n%s/login",
+			name, scheduledFor.UTC().Format("2 Jan 2006 15:04 MST"), frontendURL()),
+		Type: "account-deletion-scheduled",
+	})
+}
+
+// sendAccountDeletedEmail confirms that an account has been erased
+func sendAccountDeletedEmail(name, email string) error {
+	return kafka.PublishEmail(kafka.EmailEvent{
+		To:      email,
+		Subject: "Your account has been deleted",
+		Body: fmt.Sprintf("Hi %s,\n\nAs you requested, your account and personal data have been deleted. "+
+			"Applications you sent remain visible to recruiters without your name or contact details.", name),
+		Type: "account-deleted",
+	})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/job-portal/auth-service/kafka"
+)
+
+func TestSendPasswordResetEmail(t *testing.T) {
+	t.Setenv("FRONTEND_URL", "https://jobs.example.com/")
+	publisher := kafka.NewMemoryPublisher()
+	kafka.SetPublisher(publisher)
+
+	if err := sendPasswordResetEmail("Jane", "jane@example.com", "tok+en/1"); err != nil {
+		t.Fatalf("sendPasswordResetEmail: %v", err)
+	}
+
+	events := publisher.Events()
+	if len(events) != 1 {
+		t.Fatalf("published %d events, want 1", len(events))
+	}
+	event := events[0]
+	if event.To != "jane@example.com" || event.Type != "password-reset" {
+		t.Errorf("event to %q of type %q, want jane@example.com, password-reset", event.To, event.Type)
+	}
+	for _, want := range []string{
+		"Hi Jane,",
+		"https://jobs.example.com/reset-password?token=tok%2Ben%2F1",
+		"expires in 15 minutes",
+	} {
+		if !strings.Contains(event.Body, want) {
+			t.Errorf("body %q does not contain %q", event.Body, want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/models"
+	"github.com/job-portal/auth-service/utils"
+)
+
+// impersonationTTL returns how long an impersonation token lasts (IMPERSONATION_TTL, default 15m)
+func impersonationTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("IMPERSONATION_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 15 * time.Minute
+}
+
+// ImpersonateUser issues a short-lived access token that lets an admin see the
+// API as another user. The token names the admin in its act claim (admin only).
+func ImpersonateUser(c *gin.Context) {
+	var req models.ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adminID := c.GetString("user_id")
+	targetID := c.Param("id")
+	if targetID == adminID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot impersonate yourself"})
+		return
+	}
+
+	user, err := getUserByID(targetID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Impersonating another admin would hand out their privileges
+	if user.Role == "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admins cannot be impersonated"})
+		return
+	}
+	if user.BannedAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Banned users cannot be impersonated"})
+		return
+	}
+
+	expiresAt := time.Now().Add(impersonationTTL())
+	var impersonationID string
+	err = config.DB.QueryRow(`
+		INSERT INTO impersonation_sessions (admin_id, target_user_id, reason, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, adminID, targetID, req.Reason, expiresAt).Scan(&impersonationID)
+	if err != nil {
+		log.Printf("ImpersonateUser: insert error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start impersonation"})
+		return
+	}
+
+	actor := utils.Actor{Subject: adminID, Email: c.GetString("user_email")}
+	token, err := utils.GenerateImpersonationToken(impersonationID, user.ID, user.Email, user.Role,
+		user.EmailVerified, actor, expiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	log.Printf("User %s impersonated by admin %s (impersonation %s): %s", targetID, adminID, impersonationID, req.Reason)
+	c.JSON(http.StatusCreated, models.ImpersonationResponse{
+		ImpersonationID: impersonationID,
+		Token:           token,
+		ExpiresIn:       int64(time.Until(expiresAt).Seconds()),
+		User:            user,
+	})
+}
+
+// ListImpersonations lists impersonations, newest first, optionally only those
+// of one user (admin only)
+func ListImpersonations(c *gin.Context) {
+	rows, err := config.DB.Query(`
+		SELECT id, admin_id, target_user_id, reason, started_at, expires_at, ended_at
+		FROM impersonation_sessions
+		WHERE $1 = '' OR target_user_id::text = $1
+		ORDER BY started_at DESC
+		LIMIT 100
+	`, c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	impersonations := []models.Impersonation{}
+	for rows.Next() {
+		var imp models.Impersonation
+		if err := rows.Scan(&imp.ID, &imp.AdminID, &imp.TargetUserID, &imp.Reason,
+			&imp.StartedAt, &imp.ExpiresAt, &imp.EndedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		impersonations = append(impersonations, imp)
+	}
+
+	c.JSON(http.StatusOK, impersonations)
+}
+
+// EndImpersonation revokes an impersonation token before it expires (admin only)
+func EndImpersonation(c *gin.Context) {
+	respondToEndImpersonation(c, c.Param("id"))
+}
+
+// StopImpersonating lets the holder of an impersonation token end it
+func StopImpersonating(c *gin.Context) {
+	claims, ok := c.Get("claims")
+	if !ok || claims.(*utils.Claims).Actor == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You are not impersonating anyone"})
+		return
+	}
+	respondToEndImpersonation(c, claims.(*utils.Claims).ID)
+}
+
+// respondToEndImpersonation marks an active impersonation ended and revokes its token
+func respondToEndImpersonation(c *gin.Context, impersonationID string) {
+	var expiresAt time.Time
+	err := config.DB.QueryRow(`
+		UPDATE impersonation_sessions SET ended_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND ended_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		RETURNING expires_at
+	`, impersonationID).Scan(&expiresAt)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No active impersonation found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// The token's jti is the impersonation ID
+	if ttl := time.Until(expiresAt); ttl > 0 {
+		err = config.RedisClient.Set(config.Ctx, fmt.Sprintf("revoked_jti:%s", impersonationID), 1, ttl).Err()
+		if err != nil {
+			log.Printf("Failed to revoke impersonation %s: %v", impersonationID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Impersonation ended but its token could not be revoked"})
+			return
+		}
+	}
+
+	log.Printf("Impersonation %s ended by user %s", impersonationID, c.GetString("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "Impersonation ended"})
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/models"
+	"github.com/job-portal/auth-service/utils"
+)
+
+// defaultInvitationTTL is how long an invitation link works unless the admin picks otherwise
+const defaultInvitationTTL = 72 * time.Hour
+
+// CreateInvitation invites an email address to sign up with a privileged role
+// and emails them a signed link (admin only)
+func CreateInvitation(c *gin.Context) {
+	var req models.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var existingID string
+	err := config.DB.QueryRow("SELECT id FROM users WHERE LOWER(email) = LOWER($1)", req.Email).Scan(&existingID)
+	if err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "A user with this email already exists; change their role instead"})
+		return
+	} else if err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	ttl := defaultInvitationTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	var inv models.Invitation
+	err = config.DB.QueryRow(`
+		INSERT INTO role_invitations (email, role, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, email, role, invited_by, expires_at, created_at
+	`, req.Email, req.Role, c.GetString("user_id"), time.Now().Add(ttl)).
+		Scan(&inv.ID, &inv.Email, &inv.Role, &inv.InvitedBy, &inv.ExpiresAt, &inv.CreatedAt)
+	if err != nil {
+		log.Printf("CreateInvitation: insert error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	token, err := utils.GenerateRoleInvitationToken(inv.ID, inv.Email, inv.Role, inv.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate invitation link"})
+		return
+	}
+
+	// The invitation stays valid if the email fails; the admin can revoke and resend
+	if err := sendInvitationEmail(inv, token); err != nil {
+		log.Printf("Failed to send invitation email to %s: %v", inv.Email, err)
+	}
+
+	log.Printf("Invitation %s (%s) created for %s by admin %s", inv.ID, inv.Role, inv.Email, c.GetString("user_id"))
+	c.JSON(http.StatusCreated, inv)
+}
+
+// ListInvitations lists invitations, newest first (admin only)
+func ListInvitations(c *gin.Context) {
+	rows, err := config.DB.Query(`
+		SELECT id, email, role, invited_by, expires_at, accepted_at, revoked_at, created_at
+		FROM role_invitations
+		ORDER BY created_at DESC
+		LIMIT 100
+	`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	invitations := []models.Invitation{}
+	for rows.Next() {
+		var inv models.Invitation
+		if err := rows.Scan(&inv.ID, &inv.Email, &inv.Role, &inv.InvitedBy, &inv.ExpiresAt,
+			&inv.AcceptedAt, &inv.RevokedAt, &inv.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		invitations = append(invitations, inv)
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// RevokeInvitation cancels a pending invitation so its link stops working (admin only)
+func RevokeInvitation(c *gin.Context) {
+	result, err := config.DB.Exec(`
+		UPDATE role_invitations SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+	`, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending invitation with this ID"})
+		return
+	}
+
+	log.Printf("Invitation %s revoked by admin %s", c.Param("id"), c.GetString("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+}
+
+// AcceptInvitation creates the invited account with the invitation's role
+func AcceptInvitation(c *gin.Context) {
+	var req models.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := utils.ValidateRoleInvitationToken(req.Token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired invitation"})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Lock the invitation so it can only be accepted once
+	var invitedBy sql.NullString
+	err = tx.QueryRow(`
+		SELECT invited_by FROM role_invitations
+		WHERE id = $1 AND email = $2 AND role = $3
+		  AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		FOR UPDATE
+	`, claims.Subject, claims.Email, claims.Role).Scan(&invitedBy)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired invitation"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// The link went to this address, so it counts as verified
+	var user models.User
+	err = tx.QueryRow(`
+		INSERT INTO users (name, email, password_hash, phone, role, email_verified, email_verified_at)
+		VALUES ($1, $2, $3, $4, $5, TRUE, CURRENT_TIMESTAMP)
+		ON CONFLICT (email) DO NOTHING
+		RETURNING id, name, email, phone, role, email_verified, created_at, updated_at
+	`, req.Name, claims.Email, hashedPassword, req.Phone, claims.Role).
+		Scan(&user.ID, &user.Name, &user.Email, &user.Phone, &user.Role, &user.EmailVerified, &user.CreatedAt, &user.UpdatedAt)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusConflict, gin.H{"error": "User with this email already exists"})
+		return
+	} else if err != nil {
+		log.Printf("AcceptInvitation: insert error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE role_invitations SET accepted_at = CURRENT_TIMESTAMP, accepted_user_id = $1 WHERE id = $2
+	`, user.ID, claims.Subject); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	if err := recordRoleChange(tx, user.ID, "", user.Role, invitedBy.String, "invitation",
+		fmt.Sprintf("Accepted invitation %s", claims.Subject)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	log.Printf("Invitation %s accepted: user %s created as %s", claims.Subject, user.ID, user.Role)
+	respondWithSession(c, user)
+}
+
+// recordRoleChange appends an entry to the role change audit log. oldRole is
+// empty for new accounts and changedBy for changes nobody signed in made.
+func recordRoleChange(tx *sql.Tx, userID, oldRole, newRole, changedBy, source, reason string) error {
+	_, err := tx.Exec(`
+		INSERT INTO role_change_audit (user_id, old_role, new_role, changed_by, source, reason)
+		VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, '')::uuid, $5, NULLIF($6, ''))
+	`, userID, oldRole, newRole, changedBy, source, reason)
+	return err
+}
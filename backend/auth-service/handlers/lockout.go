@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Attempt limiters count attempts per identifier (email or client IP) in a
+// sliding window. Too many attempts lock the identifier out for a while;
+// limiters with delayAfter set also make the caller wait progressively longer
+// between attempts once that many have piled up.
+//
+//	<name>:attempts:<id>   sorted set of attempt timestamps (ms) in the window
+//	<name>:delay:<id>      present while the caller has to wait before retrying
+//	<name>:lock:<id>       present while the identifier is locked out
+
+type attemptLimiter struct {
+	name        string
+	window      time.Duration
+	maxAttempts int
+	lockout     time.Duration
+	delayAfter  int // Attempts allowed before delays kick in; 0 disables delays
+}
+
+const maxAttemptDelay = 30 * time.Second
+
+var (
+	// Failed logins per account and per client IP
+	loginEmailLimiter = attemptLimiter{name: "login_email", window: 15 * time.Minute, maxAttempts: 5, lockout: 15 * time.Minute, delayAfter: 2}
+	loginIPLimiter    = attemptLimiter{name: "login_ip", window: 15 * time.Minute, maxAttempts: 20, lockout: 15 * time.Minute}
+
+	// Every reset request counts, so nobody can flood an inbox
+	forgotPasswordEmailLimiter = attemptLimiter{name: "forgot_password_email", window: time.Hour, maxAttempts: 3, lockout: time.Hour}
+	forgotPasswordIPLimiter    = attemptLimiter{name: "forgot_password_ip", window: time.Hour, maxAttempts: 10, lockout: time.Hour}
+
+	// Magic link requests, counted like password resets
+	magicLinkEmailLimiter = attemptLimiter{name: "magic_link_email", window: time.Hour, maxAttempts: 3, lockout: time.Hour}
+	magicLinkIPLimiter    = attemptLimiter{name: "magic_link_ip", window: time.Hour, maxAttempts: 10, lockout: time.Hour}
+
+	// Invalid magic links (or links opened without their nonce) per client IP
+	magicLinkVerifyIPLimiter = attemptLimiter{name: "magic_link_verify_ip", window: 15 * time.Minute, maxAttempts: 10, lockout: 15 * time.Minute, delayAfter: 3}
+
+	// Invalid reset tokens per client IP, against token guessing
+	resetPasswordIPLimiter = attemptLimiter{name: "reset_password_ip", window: 15 * time.Minute, maxAttempts: 10, lockout: 15 * time.Minute, delayAfter: 3}
+
+	// Wrong current passwords per account, so a stolen access token can't be used to guess it
+	changePasswordLimiter = attemptLimiter{name: "change_password_user", window: 15 * time.Minute, maxAttempts: 5, lockout: 15 * time.Minute, delayAfter: 2}
+
+	// Every data export counts; each one queries every service and downloads the user's files
+	dataExportLimiter = attemptLimiter{name: "data_export_user", window: time.Hour, maxAttempts: 3, lockout: time.Hour}
+)
+
+// recordAttemptScript adds an attempt to the window and applies the delay or
+// lockout it triggers. Returns {attempts in window, 1 if now locked}.
+var recordAttemptScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local max = tonumber(ARGV[3])
+local lockout = tonumber(ARGV[4])
+local delay_after = tonumber(ARGV[5])
+local max_delay = tonumber(ARGV[6])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+redis.call('ZADD', KEYS[1], now, ARGV[7])
+redis.call('PEXPIRE', KEYS[1], window)
+local count = redis.call('ZCARD', KEYS[1])
+
+if count >= max then
+	redis.call('SET', KEYS[3], count, 'PX', lockout)
+	redis.call('DEL', KEYS[1], KEYS[2])
+	return {count, 1}
+end
+
+if delay_after > 0 and count > delay_after then
+	local delay = math.min(1000 * 2 ^ (count - delay_after - 1), max_delay)
+	redis.call('SET', KEYS[2], 1, 'PX', delay)
+end
+return {count, 0}
+`)
+
+func (l attemptLimiter) keys(id string) []string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	return []string{
+		fmt.Sprintf("%s:attempts:%s", l.name, id),
+		fmt.Sprintf("%s:delay:%s", l.name, id),
+		fmt.Sprintf("%s:lock:%s", l.name, id),
+	}
+}
+
+// retryAfter returns how long id must wait before its next attempt (0 if it may go ahead)
+func (l attemptLimiter) retryAfter(id string) (time.Duration, error) {
+	keys := l.keys(id)
+	pipe := config.RedisClient.Pipeline()
+	delay := pipe.PTTL(config.Ctx, keys[1])
+	lock := pipe.PTTL(config.Ctx, keys[2])
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return 0, err
+	}
+	wait := delay.Val()
+	if lock.Val() > wait {
+		wait = lock.Val()
+	}
+	if wait < 0 {
+		return 0, nil
+	}
+	return wait, nil
+}
+
+// record counts an attempt and reports whether it locked id out
+func (l attemptLimiter) record(id string) (bool, error) {
+	now := time.Now()
+	res, err := recordAttemptScript.Run(config.Ctx, config.RedisClient, l.keys(id),
+		now.UnixMilli(), l.window.Milliseconds(), l.maxAttempts, l.lockout.Milliseconds(),
+		l.delayAfter, maxAttemptDelay.Milliseconds(), strconv.FormatInt(now.UnixNano(), 10)).Result()
+	if err != nil {
+		return false, err
+	}
+	fields, ok := res.([]interface{})
+	if !ok || len(fields) != 2 {
+		return false, fmt.Errorf("unexpected limiter result %v", res)
+	}
+	locked, _ := fields[1].(int64)
+	return locked == 1, nil
+}
+
+// reset clears the attempts, delay and lockout for id
+func (l attemptLimiter) reset(id string) error {
+	return config.RedisClient.Del(config.Ctx, l.keys(id)...).Err()
+}
+
+// attempts returns the number of attempts currently in the window for id
+func (l attemptLimiter) attempts(id string) (int64, error) {
+	key := l.keys(id)[0]
+	since := strconv.FormatInt(time.Now().Add(-l.window).UnixMilli(), 10)
+	return config.RedisClient.ZCount(config.Ctx, key, "("+since, "+inf").Result()
+}
+
+// recordFailedLogin counts a failed login against the account and the client IP.
+// user is nil when no account matches the email; it is counted all the same so
+// lockouts don't reveal which emails are registered.
+func recordFailedLogin(email, ip string, user *models.User) {
+	if _, err := loginIPLimiter.record(ip); err != nil {
+		log.Printf("Failed to record login attempt for IP %s: %v", ip, err)
+	}
+
+	locked, err := loginEmailLimiter.record(email)
+	if err != nil {
+		log.Printf("Failed to record login attempt for %s: %v", email, err)
+		return
+	}
+	if !locked || user == nil {
+		return
+	}
+
+	log.Printf("🔒 Account %s locked after too many failed logins", user.ID)
+	if os.Getenv("LOCKOUT_NOTIFY_EMAIL") == "true" {
+		if err := sendAccountLockedEmail(user.Name, user.Email); err != nil {
+			log.Printf("Failed to send lockout email to %s: %v", user.Email, err)
+		}
+	}
+}
+
+// limitCheck pairs a limiter with the identifier it is applied to
+type limitCheck struct {
+	limiter attemptLimiter
+	id      string
+}
+
+// rejectIfLimited answers 429 with Retry-After if any limiter is holding the caller back.
+// Redis errors let the request through so an outage doesn't block every login.
+func rejectIfLimited(c *gin.Context, checks ...limitCheck) bool {
+	var wait time.Duration
+	for _, check := range checks {
+		d, err := check.limiter.retryAfter(check.id)
+		if err != nil {
+			log.Printf("Failed to check %s limiter: %v", check.limiter.name, err)
+			continue
+		}
+		if d > wait {
+			wait = d
+		}
+	}
+	if wait == 0 {
+		return false
+	}
+
+	seconds := int64(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.FormatInt(seconds, 10))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Too many attempts, please try again later",
+		"retry_after": seconds,
+	})
+	return true
+}
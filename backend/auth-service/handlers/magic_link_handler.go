@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/models"
+	"github.com/job-portal/auth-service/utils"
+)
+
+// Magic links sign a user in from their inbox. Requesting one sets a nonce
+// cookie in the requesting browser; the link only works together with that
+// cookie, so an intercepted email alone can't be used to sign in.
+//
+//	magic_link:<token hash>    {user_id, nonce_hash}, 10 minutes, single-use
+
+const (
+	magicLinkTTL         = 10 * time.Minute
+	magicLinkNonceCookie = "magic_link_nonce"
+)
+
+type magicLink struct {
+	UserID    string `json:"user_id"`
+	NonceHash string `json:"nonce_hash"`
+}
+
+// RequestMagicLink emails a single-use sign-in link and binds it to the caller's browser
+func RequestMagicLink(c *gin.Context) {
+	var req models.MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Don't reveal if user exists or not
+	response := gin.H{"message": "If the email exists, a sign-in link will be sent"}
+
+	// Every request counts, whether or not the account exists
+	ip := c.ClientIP()
+	if rejectIfLimited(c, limitCheck{magicLinkEmailLimiter, req.Email}, limitCheck{magicLinkIPLimiter, ip}) {
+		return
+	}
+	if _, err := magicLinkEmailLimiter.record(req.Email); err != nil {
+		log.Printf("Failed to record magic link request for %s: %v", req.Email, err)
+	}
+	if _, err := magicLinkIPLimiter.record(ip); err != nil {
+		log.Printf("Failed to record magic link request for IP %s: %v", ip, err)
+	}
+
+	token, err1 := utils.GenerateRandomToken(32)
+	nonce, err2 := utils.GenerateRandomToken(16)
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate sign-in link"})
+		return
+	}
+
+	// The cookie is set for unknown emails too, so responses look the same
+	setMagicLinkNonce(c, nonce, int(magicLinkTTL.Seconds()))
+
+	var userID, userName, userEmail string
+	err := config.DB.QueryRow("SELECT id, name, email FROM users WHERE email = $1", req.Email).
+		Scan(&userID, &userName, &userEmail)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusOK, response)
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	data, _ := json.Marshal(magicLink{UserID: userID, NonceHash: utils.HashToken(nonce)})
+	err = config.RedisClient.Set(config.Ctx, "magic_link:"+utils.HashToken(token), data, magicLinkTTL).Err()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store sign-in link"})
+		return
+	}
+
+	// A delivery failure is only logged so the response doesn't depend on the account existing
+	if err := sendMagicLinkEmail(userName, userEmail, token); err != nil {
+		log.Printf("Failed to send magic link email to %s: %v", userEmail, err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// VerifyMagicLink exchanges a magic link token, opened in the browser that
+// requested it, for a session
+func VerifyMagicLink(c *gin.Context) {
+	var req models.VerifyMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ip := c.ClientIP()
+	if rejectIfLimited(c, limitCheck{magicLinkVerifyIPLimiter, ip}) {
+		return
+	}
+
+	key := "magic_link:" + utils.HashToken(req.Token)
+	raw, err := config.RedisClient.Get(config.Ctx, key).Result()
+	var link magicLink
+	if err != nil || json.Unmarshal([]byte(raw), &link) != nil {
+		if _, err := magicLinkVerifyIPLimiter.record(ip); err != nil {
+			log.Printf("Failed to record magic link attempt for IP %s: %v", ip, err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired link"})
+		return
+	}
+
+	// Checked before the token is spent, so a stolen link can't burn the owner's
+	nonce, _ := c.Cookie(magicLinkNonceCookie)
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(utils.HashToken(nonce)), []byte(link.NonceHash)) != 1 {
+		if _, err := magicLinkVerifyIPLimiter.record(ip); err != nil {
+			log.Printf("Failed to record magic link attempt for IP %s: %v", ip, err)
+		}
+		log.Printf("Magic link for user %s opened without its nonce from IP %s", link.UserID, ip)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Open the link in the browser you requested it from"})
+		return
+	}
+
+	// Single use: only one concurrent request gets the token
+	if deleted, err := config.RedisClient.Del(config.Ctx, key).Result(); err != nil || deleted == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired link"})
+		return
+	}
+	setMagicLinkNonce(c, "", -1)
+
+	// The link reached the inbox, which proves the address
+	_, err = config.DB.Exec(`
+		UPDATE users SET email_verified = TRUE, email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND NOT email_verified
+	`, link.UserID)
+	if err != nil {
+		log.Printf("Failed to mark email verified for user %s: %v", link.UserID, err)
+	}
+
+	user, err := getUserByID(link.UserID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired link"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if err := loginEmailLimiter.reset(user.Email); err != nil {
+		log.Printf("Failed to reset login attempts for %s: %v", user.Email, err)
+	}
+
+	respondWithSession(c, user)
+}
+
+// setMagicLinkNonce sets (or, with a negative maxAge, clears) the nonce cookie.
+// The frontend relays it, so it is Secure whenever the frontend is served over HTTPS.
+func setMagicLinkNonce(c *gin.Context, nonce string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     magicLinkNonceCookie,
+		Value:    nonce,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil || strings.HasPrefix(frontendURL(), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
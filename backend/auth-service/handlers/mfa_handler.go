@@ -0,0 +1,509 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/models"
+	"github.com/job-portal/auth-service/utils"
+)
+
+// Wrong second factors per user, on top of the per-account login limits
+var mfaLimiter = attemptLimiter{name: "mfa_user", window: 15 * time.Minute, maxAttempts: 5, lockout: 15 * time.Minute, delayAfter: 2}
+
+const recoveryCodeCount = 10
+
+// EnrollMFA starts 2FA setup by generating a TOTP secret for the user.
+// It only takes effect once EnableMFA confirms a code from the authenticator app.
+func EnrollMFA(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var email string
+	var enabled bool
+	err := config.DB.QueryRow("SELECT email, mfa_enabled FROM users WHERE id = $1", userID).Scan(&email, &enabled)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+	encrypted, err := utils.EncryptSecret(secret)
+	if err != nil {
+		log.Printf("EnrollMFA: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Two-factor authentication is not configured"})
+		return
+	}
+
+	_, err = config.DB.Exec("UPDATE users SET mfa_secret = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND mfa_enabled = FALSE",
+		encrypted, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start enrollment"})
+		return
+	}
+
+	uri := utils.TOTPURI(secret, mfaIssuer(), email)
+	c.JSON(http.StatusOK, models.MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURL: uri,
+		QRPayload:  uri,
+	})
+}
+
+// EnableMFA confirms enrollment with a code from the authenticator app and
+// returns the recovery codes
+func EnableMFA(c *gin.Context) {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := c.GetString("user_id")
+
+	if rejectIfLimited(c, limitCheck{mfaLimiter, userID}) {
+		return
+	}
+
+	var encrypted sql.NullString
+	var enabled bool
+	err := config.DB.QueryRow("SELECT mfa_secret, mfa_enabled FROM users WHERE id = $1", userID).Scan(&encrypted, &enabled)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if !encrypted.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start enrollment first"})
+		return
+	}
+
+	ok, err := checkTOTP(userID, encrypted.String, req.Code)
+	if err != nil {
+		log.Printf("EnableMFA: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+	if !ok {
+		recordFailedMFA(userID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	codes, err := enableMFA(userID)
+	if err != nil {
+		log.Printf("EnableMFA: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+	mfaLimiter.reset(userID)
+
+	resp := models.MFAEnableResponse{RecoveryCodes: codes}
+
+	// Enrolling with an mfa_pending token finishes the login it interrupted
+	if pending, ok := c.Get("mfa_pending"); ok {
+		auth, status, msg := completeMFALogin(pending.(*utils.MFAPendingClaims), clientInfoFrom(c))
+		if auth == nil {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+		resp.Auth = auth
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// DisableMFA turns 2FA off after checking a current TOTP or recovery code
+func DisableMFA(c *gin.Context) {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := c.GetString("user_id")
+
+	required, err := mfaRequiredForRole(c.GetString("user_role"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if required {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication is required for your role"})
+		return
+	}
+
+	if !verifySecondFactorOrReject(c, userID, req.Code) {
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE users
+		SET mfa_enabled = FALSE, mfa_secret = NULL, mfa_enabled_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, userID)
+	if err == nil {
+		_, err = tx.Exec("DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("DisableMFA: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a current TOTP code
+func RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := c.GetString("user_id")
+
+	if rejectIfLimited(c, limitCheck{mfaLimiter, userID}) {
+		return
+	}
+
+	var encrypted sql.NullString
+	var enabled bool
+	err := config.DB.QueryRow("SELECT mfa_secret, mfa_enabled FROM users WHERE id = $1", userID).Scan(&encrypted, &enabled)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !enabled || !encrypted.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	ok, err := checkTOTP(userID, encrypted.String, req.Code)
+	if err != nil {
+		log.Printf("RegenerateRecoveryCodes: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+	if !ok {
+		recordFailedMFA(userID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	codes, err := replaceRecoveryCodes(tx, userID)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("RegenerateRecoveryCodes: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MFAEnableResponse{RecoveryCodes: codes})
+}
+
+// VerifyMFA is the second login step: it exchanges an mfa_pending token and a
+// TOTP or recovery code for the session tokens
+func VerifyMFA(c *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pending, err := utils.ValidateMFAPendingToken(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+	if pending.EnrollmentRequired {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication must be set up first"})
+		return
+	}
+
+	if !verifySecondFactorOrReject(c, pending.Subject, req.Code) {
+		return
+	}
+
+	auth, status, msg := completeMFALogin(pending, clientInfoFrom(c))
+	if auth == nil {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+
+	c.JSON(http.StatusOK, auth)
+}
+
+// GetMFAPolicies lists which roles must use 2FA (admin only)
+func GetMFAPolicies(c *gin.Context) {
+	rows, err := config.DB.Query("SELECT role, required, updated_at FROM mfa_role_policies ORDER BY role")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	policies := []models.MFARolePolicy{}
+	for rows.Next() {
+		var p models.MFARolePolicy
+		if err := rows.Scan(&p.Role, &p.Required, &p.UpdatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		policies = append(policies, p)
+	}
+
+	c.JSON(http.StatusOK, policies)
+}
+
+// SetMFAPolicy requires (or stops requiring) 2FA for a role (admin only).
+// Users of that role without 2FA must set it up at their next login.
+func SetMFAPolicy(c *gin.Context) {
+	role := c.Param("role")
+	if role != "jobseeker" && role != "recruiter" && role != "admin" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	var req models.UpdateMFAPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var p models.MFARolePolicy
+	err := config.DB.QueryRow(`
+		INSERT INTO mfa_role_policies (role, required, updated_by, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (role) DO UPDATE
+		SET required = EXCLUDED.required, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+		RETURNING role, required, updated_at
+	`, role, *req.Required, c.GetString("user_id")).Scan(&p.Role, &p.Required, &p.UpdatedAt)
+	if err != nil {
+		log.Printf("SetMFAPolicy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update policy"})
+		return
+	}
+
+	log.Printf("2FA required=%t for role %s, set by admin %s", p.Required, role, c.GetString("user_id"))
+	c.JSON(http.StatusOK, p)
+}
+
+// mfaIssuer is the account issuer shown in authenticator apps (MFA_ISSUER)
+func mfaIssuer() string {
+	if issuer := os.Getenv("MFA_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Job Portal"
+}
+
+// mfaRequiredForRole reports whether admins have made 2FA mandatory for a role
+func mfaRequiredForRole(role string) (bool, error) {
+	var required bool
+	err := config.DB.QueryRow("SELECT required FROM mfa_role_policies WHERE role = $1", role).Scan(&required)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return required, err
+}
+
+// checkTOTP validates a code against the user's encrypted secret. Each time
+// step is accepted only once so an observed code can't be replayed.
+func checkTOTP(userID, encryptedSecret, code string) (bool, error) {
+	secret, err := utils.DecryptSecret(encryptedSecret)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	key := fmt.Sprintf("mfa_used_step:%s:%d", userID, step)
+	fresh, err := config.RedisClient.SetNX(config.Ctx, key, 1, 2*time.Minute).Result()
+	if err != nil {
+		return false, err
+	}
+	return fresh, nil
+}
+
+// useRecoveryCode spends one of the user's unused recovery codes
+func useRecoveryCode(userID, code string) (bool, error) {
+	result, err := config.DB.Exec(`
+		UPDATE mfa_recovery_codes SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return false, err
+	}
+	rows, _ := result.RowsAffected()
+	return rows == 1, nil
+}
+
+// verifySecondFactorOrReject checks a TOTP or recovery code for a user with 2FA
+// enabled, answering the request itself when the check fails
+func verifySecondFactorOrReject(c *gin.Context, userID, code string) bool {
+	if rejectIfLimited(c, limitCheck{mfaLimiter, userID}) {
+		return false
+	}
+
+	var encrypted sql.NullString
+	var enabled bool
+	err := config.DB.QueryRow("SELECT mfa_secret, mfa_enabled FROM users WHERE id = $1", userID).Scan(&encrypted, &enabled)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		return false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return false
+	}
+	if !enabled || !encrypted.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return false
+	}
+
+	ok, err := checkTOTP(userID, encrypted.String, code)
+	if err == nil && !ok {
+		ok, err = useRecoveryCode(userID, code)
+	}
+	if err != nil {
+		log.Printf("Failed to verify second factor for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return false
+	}
+	if !ok {
+		recordFailedMFA(userID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return false
+	}
+
+	mfaLimiter.reset(userID)
+	return true
+}
+
+// recordFailedMFA counts a wrong code against the user
+func recordFailedMFA(userID string) {
+	if _, err := mfaLimiter.record(userID); err != nil {
+		log.Printf("Failed to record 2FA attempt for user %s: %v", userID, err)
+	}
+}
+
+// enableMFA activates the pending secret and issues fresh recovery codes
+func enableMFA(userID string) ([]string, error) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE users
+		SET mfa_enabled = TRUE, mfa_enabled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := replaceRecoveryCodes(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return codes, tx.Commit()
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and stores new ones.
+// Only hashes are kept; the plain codes are returned to show to the user once.
+func replaceRecoveryCodes(tx *sql.Tx, userID string) ([]string, error) {
+	if _, err := tx.Exec("DELETE FROM mfa_recovery_codes WHERE user_id = $1", userID); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.Exec("INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)",
+			userID, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// completeMFALogin spends an mfa_pending token and issues the session tokens.
+// On failure it returns the status and message to answer with.
+func completeMFALogin(pending *utils.MFAPendingClaims, client clientInfo) (*models.AuthResponse, int, string) {
+	// Each mfa_pending token completes at most one login
+	ttl := utils.MFAPendingTTL
+	if pending.ExpiresAt != nil {
+		ttl = time.Until(pending.ExpiresAt.Time)
+	}
+	fresh, err := config.RedisClient.SetNX(config.Ctx, fmt.Sprintf("mfa_pending_used:%s", pending.ID), 1, ttl).Result()
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Failed to complete login"
+	}
+	if !fresh {
+		return nil, http.StatusUnauthorized, "Invalid or expired token"
+	}
+
+	user, err := getUserByID(pending.Subject)
+	if err == sql.ErrNoRows {
+		return nil, http.StatusUnauthorized, "Invalid or expired token"
+	} else if err != nil {
+		return nil, http.StatusInternalServerError, "Database error"
+	}
+	if user.BannedAt != nil {
+		return nil, http.StatusForbidden, "This account has been suspended"
+	}
+
+	resp, err := issueAuthResponse(user, client)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Failed to generate token"
+	}
+	return &resp, 0, ""
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/models"
+	"github.com/job-portal/auth-service/oauth"
+	"github.com/job-portal/auth-service/utils"
+)
+
+// Social login runs the authorization code flow with PKCE against the
+// provider, then hands the browser back to the frontend with a one-time code
+// that the frontend exchanges for our usual AuthResponse. Starting a login sets
+// a cookie in the browser; the callback only accepts the state together with
+// that cookie, so a callback URL started by someone else can't sign the
+// browser into their account (login CSRF):
+//
+//	oauth_state:<state>        {provider, nonce, code_verifier, browser_hash}, 10 minutes
+//	oauth_login:<code hash>    user ID, 2 minutes
+
+const (
+	oauthStateTTL     = 10 * time.Minute
+	oauthLoginCodeTTL = 2 * time.Minute
+
+	oauthBrowserCookie = "oauth_browser"
+)
+
+var errOAuthEmailUnverified = errors.New("provider did not return a verified email")
+
+type oauthState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	BrowserHash  string `json:"browser_hash"` // Of the oauth_browser cookie
+}
+
+// GetOAuthProviders lists the enabled social login providers
+func GetOAuthProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": oauth.Names()})
+}
+
+// StartOAuthLogin redirects the browser to the provider's consent page
+func StartOAuthLogin(c *gin.Context) {
+	provider, ok := oauth.Get(c.Param("provider"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown login provider"})
+		return
+	}
+
+	state, err1 := utils.GenerateRandomToken(16)
+	nonce, err2 := utils.GenerateRandomToken(16)
+	verifier, err3 := utils.GenerateRandomToken(32)
+	browser, err4 := utils.GenerateRandomToken(16)
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+
+	data, _ := json.Marshal(oauthState{Provider: provider.Name, Nonce: nonce, CodeVerifier: verifier,
+		BrowserHash: utils.HashToken(browser)})
+	if err := config.RedisClient.Set(config.Ctx, "oauth_state:"+state, data, oauthStateTTL).Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), oauthCallbackURL(provider.Name), state, nonce, verifier)
+	if err != nil {
+		log.Printf("OAuth %s: %v", provider.Name, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Login provider is unavailable"})
+		return
+	}
+
+	setOAuthBrowserCookie(c, browser, int(oauthStateTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OAuthCallback handles the provider's redirect: it checks the state, redeems
+// the code, links or creates the account and sends the browser back to the frontend
+func OAuthCallback(c *gin.Context) {
+	providerName := c.Param("provider")
+	provider, ok := oauth.Get(providerName)
+	if !ok {
+		redirectOAuthError(c, "unknown_provider")
+		return
+	}
+
+	if errParam := c.Query("error"); errParam != "" {
+		redirectOAuthError(c, "access_denied")
+		return
+	}
+
+	// State is single-use, must belong to this provider and must come back to
+	// the browser that started the login
+	raw, err := config.RedisClient.GetDel(config.Ctx, "oauth_state:"+c.Query("state")).Result()
+	var state oauthState
+	if err != nil || json.Unmarshal([]byte(raw), &state) != nil || state.Provider != provider.Name {
+		redirectOAuthError(c, "invalid_state")
+		return
+	}
+	browser, _ := c.Cookie(oauthBrowserCookie)
+	if browser == "" || subtle.ConstantTimeCompare([]byte(utils.HashToken(browser)), []byte(state.BrowserHash)) != 1 {
+		log.Printf("OAuth %s: callback without the browser cookie of its login from IP %s", provider.Name, c.ClientIP())
+		redirectOAuthError(c, "invalid_state")
+		return
+	}
+	setOAuthBrowserCookie(c, "", -1)
+
+	identity, err := provider.Authenticate(c.Request.Context(), c.Query("code"),
+		oauthCallbackURL(provider.Name), state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Printf("OAuth %s: authentication failed: %v", provider.Name, err)
+		redirectOAuthError(c, "authentication_failed")
+		return
+	}
+
+	user, err := linkOAuthIdentity(provider.Name, identity)
+	if err == errOAuthEmailUnverified {
+		redirectOAuthError(c, "email_unverified")
+		return
+	} else if err != nil {
+		log.Printf("OAuth %s: failed to link identity: %v", provider.Name, err)
+		redirectOAuthError(c, "server_error")
+		return
+	}
+
+	code, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		redirectOAuthError(c, "server_error")
+		return
+	}
+	key := "oauth_login:" + utils.HashToken(code)
+	if err := config.RedisClient.Set(config.Ctx, key, user.ID, oauthLoginCodeTTL).Err(); err != nil {
+		redirectOAuthError(c, "server_error")
+		return
+	}
+
+	c.Redirect(http.StatusFound, frontendLink("/oauth/callback", code))
+}
+
+// setOAuthBrowserCookie sets (or, with a negative maxAge, clears) the cookie
+// binding a login to the browser that started it. It is sent on the provider's
+// top-level redirect back to the callback, so SameSite=Lax is enough.
+func setOAuthBrowserCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oauthBrowserCookie,
+		Value:    value,
+		Path:     "/api/auth/oauth",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil || strings.HasPrefix(os.Getenv("OAUTH_CALLBACK_BASE_URL"), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ExchangeOAuthCode trades the one-time code from the callback redirect for a session
+func ExchangeOAuthCode(c *gin.Context) {
+	var req models.OAuthExchangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := config.RedisClient.GetDel(config.Ctx, "oauth_login:"+utils.HashToken(req.Code)).Result()
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired code"})
+		return
+	}
+
+	user, err := getUserByID(userID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired code"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	respondWithSession(c, user)
+}
+
+// linkOAuthIdentity finds the account for a provider identity. Unknown
+// identities are linked to the account with the same (provider-verified)
+// email, or get a new jobseeker account.
+func linkOAuthIdentity(provider string, identity *oauth.Identity) (models.User, error) {
+	var userID string
+	err := config.DB.QueryRow(`
+		UPDATE user_identities SET last_login_at = CURRENT_TIMESTAMP
+		WHERE provider = $1 AND subject = $2
+		RETURNING user_id
+	`, provider, identity.Subject).Scan(&userID)
+	if err == nil {
+		return getUserByID(userID)
+	} else if err != sql.ErrNoRows {
+		return models.User{}, err
+	}
+
+	// Only an email the provider has verified is trusted to claim an account
+	if identity.Email == "" || !identity.EmailVerified {
+		return models.User{}, errOAuthEmailUnverified
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return models.User{}, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow("SELECT id FROM users WHERE LOWER(email) = LOWER($1)", identity.Email).Scan(&userID)
+	if err == sql.ErrNoRows {
+		// Social-only accounts get an unusable random password; forgot-password can set a real one
+		randomPassword, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			return models.User{}, err
+		}
+		hashedPassword, err := utils.HashPassword(randomPassword)
+		if err != nil {
+			return models.User{}, err
+		}
+
+		name := identity.Name
+		if name == "" {
+			name = strings.Split(identity.Email, "@")[0]
+		}
+		err = tx.QueryRow(`
+			INSERT INTO users (name, email, password_hash, role, email_verified, email_verified_at)
+			VALUES ($1, $2, $3, 'jobseeker', TRUE, CURRENT_TIMESTAMP)
+			RETURNING id
+		`, name, identity.Email, hashedPassword).Scan(&userID)
+		if err != nil {
+			return models.User{}, err
+		}
+		log.Printf("Created user %s from %s login", userID, provider)
+	} else if err != nil {
+		return models.User{}, err
+	} else {
+		// The provider vouches for the address, so the account counts as verified
+		_, err = tx.Exec(`
+			UPDATE users
+			SET email_verified = TRUE, email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP)
+			WHERE id = $1
+		`, userID)
+		if err != nil {
+			return models.User{}, err
+		}
+		log.Printf("Linked %s identity to existing user %s", provider, userID)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO user_identities (user_id, provider, subject, email, last_login_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+	`, userID, provider, identity.Subject, identity.Email)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.User{}, err
+	}
+	return getUserByID(userID)
+}
+
+// oauthCallbackURL is the redirect URI registered with the provider (OAUTH_CALLBACK_BASE_URL)
+func oauthCallbackURL(provider string) string {
+	base := os.Getenv("OAUTH_CALLBACK_BASE_URL")
+	if base == "" {
+		base = "http://localhost:8001"
+	}
+	return fmt.Sprintf("%s/api/auth/oauth/%s/callback", strings.TrimSuffix(base, "/"), provider)
+}
+
+// redirectOAuthError sends the browser back to the frontend with an error code
+func redirectOAuthError(c *gin.Context, code string) {
+	c.Redirect(http.StatusFound, fmt.Sprintf("%s/oauth/callback?error=%s", frontendURL(), url.QueryEscape(code)))
+}
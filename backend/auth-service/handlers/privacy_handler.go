@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/models"
+	"github.com/job-portal/auth-service/privacy"
+	"github.com/job-portal/auth-service/utils"
+)
+
+// accountDeletionGrace returns how long a deletion request can be cancelled
+// before the account is erased (ACCOUNT_DELETION_GRACE, default 30 days)
+func accountDeletionGrace() time.Duration {
+	if grace, err := time.ParseDuration(os.Getenv("ACCOUNT_DELETION_GRACE")); err == nil && grace > 0 {
+		return grace
+	}
+	return 30 * 24 * time.Hour
+}
+
+// serviceURL returns a service's internal API base URL from env, or its local default
+func serviceURL(env, fallback string) string {
+	if url := os.Getenv(env); url != "" {
+		return url
+	}
+	return fallback
+}
+
+// privacyParticipants lists every service holding user data. auth-service's own
+// tables come last, since erasing them deletes the users row.
+func privacyParticipants() []privacy.Participant {
+	return []privacy.Participant{
+		privacy.Remote("user-service", serviceURL("USER_SERVICE_INTERNAL_URL", "http://localhost:8102")),
+		privacy.Remote("job-service", serviceURL("JOB_SERVICE_INTERNAL_URL", "http://localhost:8103")),
+		privacy.Remote("blog-service", serviceURL("BLOG_SERVICE_INTERNAL_URL", "http://localhost:8105")),
+		accountParticipant{},
+	}
+}
+
+// ExportAccountData sends the signed-in user a ZIP of everything stored about them
+func ExportAccountData(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if rejectIfLimited(c, limitCheck{dataExportLimiter, userID}) {
+		return
+	}
+	if _, err := dataExportLimiter.record(userID); err != nil {
+		log.Printf("Failed to record data export for user %s: %v", userID, err)
+	}
+
+	participants := privacyParticipants()
+	if err := privacy.CheckCoverage(participants); err != nil {
+		log.Printf("ExportAccountData: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Data export is unavailable right now"})
+		return
+	}
+
+	archive, err := privacy.Collect(userID, participants)
+	if err != nil {
+		log.Printf("ExportAccountData: user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export your data"})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="job-portal-data.zip"`)
+	c.Status(http.StatusOK)
+	if err := archive.Write(c.Writer); err != nil {
+		// Headers are already sent; the client gets a truncated archive
+		log.Printf("ExportAccountData: writing archive for user %s: %v", userID, err)
+		return
+	}
+	log.Printf("Data exported for user %s", userID)
+}
+
+// RequestAccountDeletion schedules the signed-in user's account for erasure
+// once the grace period is over. Until then it can be cancelled.
+func RequestAccountDeletion(c *gin.Context) {
+	var req models.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if rejectIfLimited(c, limitCheck{changePasswordLimiter, userID}) {
+		return
+	}
+
+	var name, email, passwordHash, role string
+	var scheduledFor *time.Time
+	err := config.DB.QueryRow(`
+		SELECT name, email, password_hash, role, deletion_scheduled_for FROM users WHERE id = $1
+	`, userID).Scan(&name, &email, &passwordHash, &role, &scheduledFor)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if err := utils.CheckPassword(passwordHash, req.Password); err != nil {
+		if _, err := changePasswordLimiter.record(userID); err != nil {
+			log.Printf("Failed to record password confirmation attempt for user %s: %v", userID, err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+		return
+	}
+	changePasswordLimiter.reset(userID)
+
+	// Blog posts and platform settings would be left without an owner
+	if role == "admin" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Admin accounts can't be deleted; ask another admin to change your role first"})
+		return
+	}
+	if scheduledFor != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Account deletion is already scheduled", "deletion_scheduled_for": scheduledFor})
+		return
+	}
+
+	var deletionAt time.Time
+	err = config.DB.QueryRow(`
+		UPDATE users
+		SET deletion_requested_at = CURRENT_TIMESTAMP, deletion_scheduled_for = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING deletion_scheduled_for
+	`, time.Now().Add(accountDeletionGrace()), userID).Scan(&deletionAt)
+	if err != nil {
+		log.Printf("RequestAccountDeletion: update error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule account deletion"})
+		return
+	}
+
+	if err := sendAccountDeletionScheduledEmail(name, email, deletionAt); err != nil {
+		log.Printf("Failed to send deletion scheduled email to %s: %v", email, err)
+	}
+
+	log.Printf("Account %s scheduled for deletion on %s", userID, deletionAt.Format(time.RFC3339))
+	c.JSON(http.StatusOK, gin.H{"message": "Account scheduled for deletion", "deletion_scheduled_for": deletionAt})
+}
+
+// CancelAccountDeletion keeps an account whose deletion is still pending
+func CancelAccountDeletion(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	// The row lock orders this against the purger claiming the account
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel account deletion"})
+		return
+	}
+	defer tx.Rollback()
+
+	var scheduled bool
+	err = tx.QueryRow("SELECT deletion_scheduled_for IS NOT NULL FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&scheduled)
+	if err != nil && err != sql.ErrNoRows {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel account deletion"})
+		return
+	}
+	if !scheduled {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No account deletion is pending"})
+		return
+	}
+	erasing, err := config.RedisClient.Exists(config.Ctx, accountErasingKey(userID)).Result()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel account deletion"})
+		return
+	}
+	if erasing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Account deletion is already in progress"})
+		return
+	}
+
+	_, err = tx.Exec(`
+		UPDATE users
+		SET deletion_requested_at = NULL, deletion_scheduled_for = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, userID)
+	if err != nil || tx.Commit() != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel account deletion"})
+		return
+	}
+
+	log.Printf("Account deletion cancelled for user %s", userID)
+	c.JSON(http.StatusOK, gin.H{"message": "Account deletion cancelled"})
+}
+
+// StartAccountPurger erases accounts whose deletion grace period is over. It
+// runs hourly; a Redis lock keeps replicas from purging at the same time.
+func StartAccountPurger() {
+	go func() {
+		// Give the other services a moment to come up
+		time.Sleep(time.Minute)
+		for {
+			purgeDeletedAccounts()
+			time.Sleep(time.Hour)
+		}
+	}()
+}
+
+func purgeDeletedAccounts() {
+	locked, err := config.RedisClient.SetNX(config.Ctx, "account_purge_lock", 1, 30*time.Minute).Result()
+	if err != nil || !locked {
+		return
+	}
+	defer config.RedisClient.Del(config.Ctx, "account_purge_lock")
+
+	rows, err := config.DB.Query(`
+		SELECT id, name, email FROM users
+		WHERE deletion_scheduled_for <= CURRENT_TIMESTAMP
+		ORDER BY deletion_scheduled_for
+		LIMIT 100
+	`)
+	if err != nil {
+		log.Printf("Account purge: query error: %v", err)
+		return
+	}
+	type pendingDeletion struct{ id, name, email string }
+	var pending []pendingDeletion
+	for rows.Next() {
+		var p pendingDeletion
+		if err := rows.Scan(&p.id, &p.name, &p.email); err != nil {
+			rows.Close()
+			log.Printf("Account purge: scan error: %v", err)
+			return
+		}
+		pending = append(pending, p)
+	}
+	rows.Close()
+	if len(pending) == 0 {
+		return
+	}
+
+	participants := privacyParticipants()
+	if err := privacy.CheckCoverage(participants); err != nil {
+		log.Printf("Account purge: not erasing %d accounts: %v", len(pending), err)
+		return
+	}
+
+	for _, p := range pending {
+		// The deletion may have been cancelled since the accounts were listed
+		claimed, err := claimAccountForErasure(p.id)
+		if err != nil {
+			log.Printf("Account purge: user %s: %v", p.id, err)
+			continue
+		}
+		if !claimed {
+			log.Printf("Account purge: deletion of user %s was cancelled, skipping", p.id)
+			continue
+		}
+
+		err = privacy.Erase(p.id, participants)
+		config.RedisClient.Del(config.Ctx, accountErasingKey(p.id))
+		if err != nil {
+			// Retried on the next run
+			log.Printf("Account purge: user %s: %v", p.id, err)
+			continue
+		}
+		if err := sendAccountDeletedEmail(p.name, p.email); err != nil {
+			log.Printf("Failed to send account deleted email to %s: %v", p.email, err)
+		}
+		log.Printf("Account %s erased", p.id)
+	}
+}
+
+// accountErasingKey marks an account the purger is erasing; cancelling its
+// deletion is refused meanwhile
+func accountErasingKey(userID string) string {
+	return "account_erasing:" + userID
+}
+
+// claimAccountForErasure checks, under the row lock CancelAccountDeletion also
+// takes, that the account's deletion is still due, and marks it as being
+// erased. The lock isn't held during the erasure itself, which calls the other
+// services and lets them update the users row.
+func claimAccountForErasure(userID string) (bool, error) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var due bool
+	err = tx.QueryRow(`
+		SELECT COALESCE(deletion_scheduled_for <= CURRENT_TIMESTAMP, FALSE) FROM users WHERE id = $1 FOR UPDATE
+	`, userID).Scan(&due)
+	if err == sql.ErrNoRows || (err == nil && !due) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if err := config.RedisClient.Set(config.Ctx, accountErasingKey(userID), 1, 30*time.Minute).Err(); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		config.RedisClient.Del(config.Ctx, accountErasingKey(userID))
+		return false, err
+	}
+	return true, nil
+}
+
+// accountParticipant covers the account itself and auth-service's tables
+type accountParticipant struct{}
+
+func (accountParticipant) Name() string { return "account" }
+
+func (accountParticipant) Tables() ([]string, error) {
+	return []string{
+		"users", "user_identities", "mfa_recovery_codes", "mfa_role_policies", "role_invitations",
+		"role_change_audit", "api_keys", "impersonation_sessions", "subscriptions",
+	}, nil
+}
+
+func (accountParticipant) Export(userID string) (*privacy.Export, error) {
+	user, err := getUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := listSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &privacy.Export{Records: map[string]json.RawMessage{}}
+	for name, value := range map[string]interface{}{"profile": user, "sessions": sessions} {
+		if export.Records[name], err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+
+	queries := map[string]string{
+		"social_logins": `SELECT provider, email, created_at, last_login_at FROM user_identities WHERE user_id = $1`,
+		"role_changes":  `SELECT old_role, new_role, source, reason, created_at FROM role_change_audit WHERE user_id = $1`,
+		"api_keys":      `SELECT name, key_prefix, scopes, expires_at, last_used_at, revoked_at, created_at FROM api_keys WHERE user_id = $1`,
+		"impersonations": `SELECT admin_id, reason, started_at, expires_at, ended_at
+			FROM impersonation_sessions WHERE target_user_id = $1`,
+		"subscriptions": `SELECT plan_type, expiry_date, created_at FROM subscriptions WHERE user_id = $1`,
+	}
+	for name, query := range queries {
+		if export.Records[name], err = privacy.QueryRecords(config.DB, query, userID); err != nil {
+			return nil, err
+		}
+	}
+	return export, nil
+}
+
+func (accountParticipant) Erase(userID string) error {
+	var email string
+	err := config.DB.QueryRow("SELECT email FROM users WHERE id = $1", userID).Scan(&email)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := revokeAllSessions(userID); err != nil {
+		return err
+	}
+	if err := loginEmailLimiter.reset(email); err != nil {
+		return err
+	}
+
+	// Everything else referencing the user cascades or is set to NULL
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM role_invitations WHERE LOWER(email) = LOWER($1)", email); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM users WHERE id = $1", userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/pkg/ratelimit"
+)
+
+// Every service publishes its rate limit policies to rate_limit_defaults and
+// applies overrides from rate_limit_policies (see pkg/ratelimit).
+// Changes take effect within 30 seconds, without a redeploy.
+
+// GetRateLimits lists each service's rate limit policies and any overrides (admin only)
+func GetRateLimits(c *gin.Context) {
+	policies := gin.H{}
+	for _, key := range []string{"rate_limit_defaults", "rate_limit_policies"} {
+		raw, err := config.RedisClient.HGetAll(config.Ctx, key).Result()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load rate limits"})
+			return
+		}
+		parsed := map[string]ratelimit.Policy{}
+		for name, data := range raw {
+			var policy ratelimit.Policy
+			if err := json.Unmarshal([]byte(data), &policy); err != nil {
+				log.Printf("GetRateLimits: invalid policy %s in %s: %v", name, key, err)
+				continue
+			}
+			parsed[name] = policy
+		}
+		policies[key] = parsed
+	}
+
+	c.JSON(http.StatusOK, gin.H{"defaults": policies["rate_limit_defaults"], "overrides": policies["rate_limit_policies"]})
+}
+
+// SetRateLimit overrides a rate limit policy (admin only)
+func SetRateLimit(c *gin.Context) {
+	name := c.Param("name")
+
+	var policy ratelimit.Policy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !policy.Disabled {
+		if _, err := ratelimit.ValidatePolicy(&policy); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Overrides for policies no service declares would never apply
+	exists, err := config.RedisClient.HExists(config.Ctx, "rate_limit_defaults", name).Result()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load rate limits"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rate limit policy not found"})
+		return
+	}
+
+	data, _ := json.Marshal(policy)
+	if err := config.RedisClient.HSet(config.Ctx, "rate_limit_policies", name, data).Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rate limit"})
+		return
+	}
+
+	log.Printf("Rate limit %s set to %s by admin %s", name, data, c.GetString("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "Rate limit updated", "name": name, "policy": policy})
+}
+
+// ResetRateLimit removes an override, restoring the service's own policy (admin only)
+func ResetRateLimit(c *gin.Context) {
+	name := c.Param("name")
+
+	removed, err := config.RedisClient.HDel(config.Ctx, "rate_limit_policies", name).Result()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset rate limit"})
+		return
+	}
+	if removed == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No override for this rate limit"})
+		return
+	}
+
+	log.Printf("Rate limit %s reset by admin %s", name, c.GetString("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "Rate limit reset"})
+}
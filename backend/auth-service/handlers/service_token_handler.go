@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/models"
+	"github.com/job-portal/auth-service/utils"
+)
+
+// Services call each other's internal APIs with short-lived tokens signed by
+// auth-service, whose subject is the calling service and whose audience is the
+// API being called. A service proves who it is with its secret, shared with
+// auth-service through <SERVICE>_SECRET (e.g. JOB_SERVICE_SECRET).
+
+// serviceAudiences lists the internal APIs each service may call
+var serviceAudiences = map[string][]string{
+	"user-service":    {"email", "api-keys"},
+	"job-service":     {"email", "api-keys"},
+	"utility-service": {"api-keys"},
+	"blog-service":    {"email", "api-keys"},
+}
+
+// serviceSecret returns the secret a service authenticates with, or "" if none is configured
+func serviceSecret(service string) string {
+	return os.Getenv(strings.ToUpper(strings.ReplaceAll(service, "-", "_")) + "_SECRET")
+}
+
+// IssueServiceToken exchanges a service's secret for a token for one audience.
+// It is only mounted on the internal router.
+func IssueServiceToken(c *gin.Context) {
+	var req models.ServiceTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	audiences, known := serviceAudiences[req.Service]
+	secret := serviceSecret(req.Service)
+	if !known || secret == "" || subtle.ConstantTimeCompare([]byte(req.Secret), []byte(secret)) != 1 {
+		log.Printf("Rejected service token request for %q from %s", req.Service, c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid service credentials"})
+		return
+	}
+
+	allowed := false
+	for _, audience := range audiences {
+		if audience == req.Audience {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": req.Service + " may not call " + req.Audience})
+		return
+	}
+
+	token, err := utils.GenerateServiceToken(req.Service, req.Audience)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue service token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ServiceTokenResponse{
+		Token:     token,
+		ExpiresIn: int64(utils.ServiceTokenTTL.Seconds()),
+	})
+}
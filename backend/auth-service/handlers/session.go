@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/models"
+	"github.com/job-portal/auth-service/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// Refresh tokens are opaque random strings. Redis only ever sees their SHA-256
+// hash. Every login starts a new token family; each refresh rotates the token
+// within that family and keeps the spent one around so a replay can be detected.
+//
+//	refresh_token:<hash>           hash {user_id, family_id, uses}
+//	refresh_family:<family_id>     set of token hashes issued in the family
+//	user_refresh_families:<user>   set of the user's live family IDs
+//	refresh_session:<family_id>    hash {user_id, ip, user_agent, created_at, last_seen_at}
+//
+// A family is what users see as a session; its ID is the access token's sid
+// claim. The session's IP and user agent are those of the sign-in, since
+// refreshes come from the frontend server rather than the browser.
+
+var (
+	errInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	errRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// consumeRefreshTokenScript marks a refresh token as used and returns
+// {uses, user_id, family_id}, or -1 if the token does not exist
+var consumeRefreshTokenScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return -1
+end
+local uses = redis.call('HINCRBY', KEYS[1], 'uses', 1)
+local fields = redis.call('HMGET', KEYS[1], 'user_id', 'family_id')
+return {uses, fields[1], fields[2]}
+`)
+
+// refreshTokenTTL returns the lifetime of refresh tokens (JWT_REFRESH_EXPIRY, default 7 days)
+func refreshTokenTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("JWT_REFRESH_EXPIRY")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 7 * 24 * time.Hour
+}
+
+func refreshTokenKey(hash string) string {
+	return fmt.Sprintf("refresh_token:%s", hash)
+}
+
+func refreshFamilyKey(familyID string) string {
+	return fmt.Sprintf("refresh_family:%s", familyID)
+}
+
+func userFamiliesKey(userID string) string {
+	return fmt.Sprintf("user_refresh_families:%s", userID)
+}
+
+func sessionKey(familyID string) string {
+	return fmt.Sprintf("refresh_session:%s", familyID)
+}
+
+// clientInfo describes where a sign-in came from
+type clientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+func clientInfoFrom(c *gin.Context) clientInfo {
+	return clientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// issueAuthResponse starts a new session and builds its access/refresh token
+// pair for a freshly authenticated user
+func issueAuthResponse(user models.User, client clientInfo) (models.AuthResponse, error) {
+	familyID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	token, err := utils.GenerateJWT(user.ID, user.Email, user.Role, user.EmailVerified, familyID)
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	refreshToken, err := issueRefreshToken(user.ID, familyID)
+	if err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	now := time.Now().Unix()
+	pipe := config.RedisClient.TxPipeline()
+	pipe.HSet(config.Ctx, sessionKey(familyID), "user_id", user.ID, "ip", client.IP,
+		"user_agent", client.UserAgent, "created_at", now, "last_seen_at", now)
+	pipe.Expire(config.Ctx, sessionKey(familyID), refreshTokenTTL())
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return models.AuthResponse{}, err
+	}
+
+	return models.AuthResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(utils.AccessTokenTTL().Seconds()),
+		User:         user,
+	}, nil
+}
+
+// issueRefreshToken stores a new refresh token in the given family
+func issueRefreshToken(userID, familyID string) (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	hash := utils.HashToken(token)
+	ttl := refreshTokenTTL()
+
+	pipe := config.RedisClient.TxPipeline()
+	pipe.HSet(config.Ctx, refreshTokenKey(hash), "user_id", userID, "family_id", familyID, "uses", 0)
+	pipe.Expire(config.Ctx, refreshTokenKey(hash), ttl)
+	pipe.SAdd(config.Ctx, refreshFamilyKey(familyID), hash)
+	pipe.Expire(config.Ctx, refreshFamilyKey(familyID), ttl)
+	pipe.SAdd(config.Ctx, userFamiliesKey(userID), familyID)
+	pipe.Expire(config.Ctx, userFamiliesKey(userID), ttl)
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// rotateRefreshToken spends a refresh token and issues its successor in the same family.
+// Presenting an already-spent token revokes the whole family.
+func rotateRefreshToken(token string) (userID, familyID, newToken string, err error) {
+	res, err := consumeRefreshTokenScript.Run(config.Ctx, config.RedisClient,
+		[]string{refreshTokenKey(utils.HashToken(token))}).Result()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	fields, ok := res.([]interface{})
+	if !ok || len(fields) != 3 {
+		return "", "", "", errInvalidRefreshToken
+	}
+	uses, _ := fields[0].(int64)
+	userID, _ = fields[1].(string)
+	familyID, _ = fields[2].(string)
+
+	if uses > 1 {
+		if err := revokeFamily(userID, familyID); err != nil {
+			return "", "", "", err
+		}
+		return userID, "", "", errRefreshTokenReused
+	}
+
+	newToken, err = issueRefreshToken(userID, familyID)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	pipe := config.RedisClient.TxPipeline()
+	pipe.HSet(config.Ctx, sessionKey(familyID), "last_seen_at", time.Now().Unix())
+	pipe.Expire(config.Ctx, sessionKey(familyID), refreshTokenTTL())
+	if _, err := pipe.Exec(config.Ctx); err != nil {
+		return "", "", "", err
+	}
+	return userID, familyID, newToken, nil
+}
+
+// revokeRefreshToken revokes the family the given refresh token belongs to
+func revokeRefreshToken(token string) error {
+	fields, err := config.RedisClient.HMGet(config.Ctx,
+		refreshTokenKey(utils.HashToken(token)), "user_id", "family_id").Result()
+	if err != nil {
+		return err
+	}
+	userID, _ := fields[0].(string)
+	familyID, _ := fields[1].(string)
+	if familyID == "" {
+		return nil
+	}
+	return revokeFamily(userID, familyID)
+}
+
+// revokeFamily ends a session: it deletes every refresh token issued in the
+// family and rejects the access tokens carrying its sid until they expire
+func revokeFamily(userID, familyID string) error {
+	hashes, err := config.RedisClient.SMembers(config.Ctx, refreshFamilyKey(familyID)).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{refreshFamilyKey(familyID), sessionKey(familyID)}
+	for _, hash := range hashes {
+		keys = append(keys, refreshTokenKey(hash))
+	}
+
+	pipe := config.RedisClient.TxPipeline()
+	pipe.Del(config.Ctx, keys...)
+	pipe.SRem(config.Ctx, userFamiliesKey(userID), familyID)
+	pipe.Set(config.Ctx, fmt.Sprintf("revoked_session:%s", familyID), 1, utils.AccessTokenTTL())
+	_, err = pipe.Exec(config.Ctx)
+	return err
+}
+
+// listSessions returns the user's live sessions, most recently used first.
+// Families whose tokens have all expired are pruned on the way.
+func listSessions(userID string) ([]models.Session, error) {
+	familyIDs, err := config.RedisClient.SMembers(config.Ctx, userFamiliesKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []models.Session{}
+	for _, familyID := range familyIDs {
+		live, err := config.RedisClient.Exists(config.Ctx, refreshFamilyKey(familyID)).Result()
+		if err != nil {
+			return nil, err
+		}
+		if live == 0 {
+			config.RedisClient.SRem(config.Ctx, userFamiliesKey(userID), familyID)
+			continue
+		}
+
+		fields, err := config.RedisClient.HGetAll(config.Ctx, sessionKey(familyID)).Result()
+		if err != nil {
+			return nil, err
+		}
+		session := models.Session{
+			ID:        familyID,
+			IP:        fields["ip"],
+			UserAgent: fields["user_agent"],
+			Device:    describeDevice(fields["user_agent"]),
+		}
+		if ts, err := strconv.ParseInt(fields["created_at"], 10, 64); err == nil {
+			t := time.Unix(ts, 0)
+			session.CreatedAt = &t
+		}
+		if ts, err := strconv.ParseInt(fields["last_seen_at"], 10, 64); err == nil {
+			t := time.Unix(ts, 0)
+			session.LastSeenAt = &t
+		}
+		sessions = append(sessions, session)
+	}
+
+	lastSeen := func(i int) int64 {
+		if sessions[i].LastSeenAt == nil {
+			return 0
+		}
+		return sessions[i].LastSeenAt.Unix()
+	}
+	sort.Slice(sessions, func(i, j int) bool { return lastSeen(i) > lastSeen(j) })
+	return sessions, nil
+}
+
+// revokeOtherSessions ends every session of the user except keepFamilyID
+func revokeOtherSessions(userID, keepFamilyID string) error {
+	familyIDs, err := config.RedisClient.SMembers(config.Ctx, userFamiliesKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	for _, familyID := range familyIDs {
+		if familyID == keepFamilyID {
+			continue
+		}
+		if err := revokeFamily(userID, familyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Access tokens are stateless, so revoking them means telling every service's
+// AuthMiddleware to reject them (see pkg/auth/revocation.go):
+//
+//	revoked_jti:<jti>                  a single token, kept until it would have expired
+//	user_tokens_valid_after:<user_id>  unix time in milliseconds; tokens issued earlier are rejected
+
+// revokeAccessToken revokes a single access token until it expires
+func revokeAccessToken(claims *utils.Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return config.RedisClient.Set(config.Ctx, fmt.Sprintf("revoked_jti:%s", claims.ID), 1, ttl).Err()
+}
+
+// revokeUserTokens rejects every access token issued to the user before now
+func revokeUserTokens(userID string) error {
+	// Kept as long as a refresh token lives, which outlasts any access token
+	key := fmt.Sprintf("user_tokens_valid_after:%s", userID)
+	return config.RedisClient.Set(config.Ctx, key, time.Now().UnixMilli(), refreshTokenTTL()).Err()
+}
+
+// revokeAllSessions signs the user out everywhere: all refresh token families
+// and all outstanding access tokens
+func revokeAllSessions(userID string) error {
+	familyIDs, err := config.RedisClient.SMembers(config.Ctx, userFamiliesKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	for _, familyID := range familyIDs {
+		if err := revokeFamily(userID, familyID); err != nil {
+			return err
+		}
+	}
+	return revokeUserTokens(userID)
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/models"
+	"github.com/job-portal/auth-service/utils"
+)
+
+// ChangePassword sets a new password for the signed-in user after checking the
+// current one, then signs out every other session
+func ChangePassword(c *gin.Context) {
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if rejectIfLimited(c, limitCheck{changePasswordLimiter, userID}) {
+		return
+	}
+
+	var name, email, passwordHash string
+	err := config.DB.QueryRow("SELECT name, email, password_hash FROM users WHERE id = $1", userID).
+		Scan(&name, &email, &passwordHash)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if err := utils.CheckPassword(passwordHash, req.CurrentPassword); err != nil {
+		if _, err := changePasswordLimiter.record(userID); err != nil {
+			log.Printf("Failed to record change-password attempt for user %s: %v", userID, err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+	changePasswordLimiter.reset(userID)
+
+	if utils.CheckPassword(passwordHash, req.NewPassword) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New password must be different from the current one"})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	_, err = config.DB.Exec("UPDATE users SET password_hash = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2",
+		hashedPassword, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
+	// Tokens issued before session IDs existed can't be told apart, so those
+	// callers are signed out everywhere, themselves included
+	currentSession := currentSessionID(c)
+	if currentSession == "" {
+		err = revokeAllSessions(userID)
+	} else {
+		err = revokeOtherSessions(userID, currentSession)
+	}
+	if err != nil {
+		log.Printf("Failed to revoke sessions for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Password changed but other sessions could not be signed out"})
+		return
+	}
+
+	if err := sendPasswordChangedEmail(name, email); err != nil {
+		log.Printf("Failed to send password changed email to %s: %v", email, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// GetSessions lists the signed-in user's active sessions
+func GetSessions(c *gin.Context) {
+	sessions, err := listSessions(c.GetString("user_id"))
+	if err != nil {
+		log.Printf("GetSessions: redis error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	current := currentSessionID(c)
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession signs out one of the user's sessions
+func RevokeSession(c *gin.Context) {
+	userID := c.GetString("user_id")
+	sessionID := c.Param("id")
+
+	// Only the owner's sessions can be revoked
+	owned, err := config.RedisClient.SIsMember(config.Ctx, userFamiliesKey(userID), sessionID).Result()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+	if !owned {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := revokeFamily(userID, sessionID); err != nil {
+		log.Printf("RevokeSession: redis error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeOtherSessions signs out every session except the caller's own
+func RevokeOtherSessions(c *gin.Context) {
+	current := currentSessionID(c)
+	if current == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sign in again to manage your sessions"})
+		return
+	}
+
+	if err := revokeOtherSessions(c.GetString("user_id"), current); err != nil {
+		log.Printf("RevokeOtherSessions: redis error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Other sessions revoked successfully"})
+}
+
+// currentSessionID returns the sid of the caller's access token
+func currentSessionID(c *gin.Context) string {
+	if claims, ok := c.Get("claims"); ok {
+		return claims.(*utils.Claims).SessionID
+	}
+	return ""
+}
+
+// describeDevice turns a user agent into a short label such as "Chrome on macOS"
+func describeDevice(userAgent string) string {
+	browsers := []struct{ token, name string }{
+		// Order matters: Edge and Opera also claim to be Chrome, and Chrome claims to be Safari
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	}
+	systems := []struct{ token, name string }{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+
+	browser, system := "", ""
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range systems {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+	return "Unknown device"
+}
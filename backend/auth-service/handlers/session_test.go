@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/job-portal/auth-service/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// useMiniredis points config.RedisClient at a fresh in-memory Redis
+func useMiniredis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	previous := config.RedisClient
+	config.RedisClient = client
+	t.Cleanup(func() {
+		config.RedisClient = previous
+		client.Close()
+	})
+	return mr
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	mr := useMiniredis(t)
+
+	token, err := issueRefreshToken("user-1", "family-1")
+	if err != nil {
+		t.Fatalf("issueRefreshToken: %v", err)
+	}
+
+	userID, familyID, next, err := rotateRefreshToken(token)
+	if err != nil {
+		t.Fatalf("rotateRefreshToken: %v", err)
+	}
+	if userID != "user-1" || familyID != "family-1" {
+		t.Errorf("rotated into user %q family %q, want user-1 family-1", userID, familyID)
+	}
+	if next == "" || next == token {
+		t.Fatalf("rotation issued %q, want a new token", next)
+	}
+
+	// The successor is good for exactly one more rotation
+	if _, _, _, err := rotateRefreshToken(next); err != nil {
+		t.Errorf("rotating the successor: %v", err)
+	}
+
+	if _, _, _, err := rotateRefreshToken("never-issued"); !errors.Is(err, errInvalidRefreshToken) {
+		t.Errorf("unknown token: got %v, want errInvalidRefreshToken", err)
+	}
+	if mr.Exists("revoked_session:family-1") {
+		t.Error("family revoked without any reuse")
+	}
+}
+
+func TestRotateRefreshTokenReuse(t *testing.T) {
+	mr := useMiniredis(t)
+
+	token, err := issueRefreshToken("user-1", "family-1")
+	if err != nil {
+		t.Fatalf("issueRefreshToken: %v", err)
+	}
+	other, err := issueRefreshToken("user-1", "family-2")
+	if err != nil {
+		t.Fatalf("issueRefreshToken: %v", err)
+	}
+	_, _, next, err := rotateRefreshToken(token)
+	if err != nil {
+		t.Fatalf("rotateRefreshToken: %v", err)
+	}
+
+	// Replaying the spent token revokes the family, including its successor
+	if _, _, _, err := rotateRefreshToken(token); !errors.Is(err, errRefreshTokenReused) {
+		t.Fatalf("replay: got %v, want errRefreshTokenReused", err)
+	}
+	if !mr.Exists("revoked_session:family-1") {
+		t.Error("replay did not set revoked_session:family-1")
+	}
+	if ttl := mr.TTL("revoked_session:family-1"); ttl <= 0 {
+		t.Errorf("revoked_session:family-1 has TTL %v, want it to expire with the access tokens", ttl)
+	}
+	for _, key := range []string{refreshFamilyKey("family-1"), sessionKey("family-1")} {
+		if mr.Exists(key) {
+			t.Errorf("%s survived the revocation", key)
+		}
+	}
+	if _, _, _, err := rotateRefreshToken(next); !errors.Is(err, errInvalidRefreshToken) {
+		t.Errorf("successor after revocation: got %v, want errInvalidRefreshToken", err)
+	}
+	if members, _ := mr.SMembers(userFamiliesKey("user-1")); len(members) != 1 || members[0] != "family-2" {
+		t.Errorf("user's families after revocation: got %v, want [family-2]", members)
+	}
+
+	// Other sessions are untouched
+	if _, _, _, err := rotateRefreshToken(other); err != nil {
+		t.Errorf("rotating another family's token: %v", err)
+	}
+}
+
+func TestRotateRefreshTokenDoubleSpend(t *testing.T) {
+	useMiniredis(t)
+
+	token, err := issueRefreshToken("user-1", "family-1")
+	if err != nil {
+		t.Fatalf("issueRefreshToken: %v", err)
+	}
+
+	const attempts = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _, err := rotateRefreshToken(token)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	successes := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			successes++
+		case !errors.Is(err, errRefreshTokenReused) && !errors.Is(err, errInvalidRefreshToken):
+			// Once a replay has revoked the family the token is simply gone
+			t.Errorf("concurrent rotation: got %v, want success or a rejected token", err)
+		}
+	}
+	if successes != 1 {
+		t.Errorf("%d of %d concurrent rotations succeeded, want exactly 1", successes, attempts)
+	}
+}
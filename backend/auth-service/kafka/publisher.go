@@ -0,0 +1,53 @@
+package kafka
+
+import "sync"
+
+// Publisher delivers email events. Handlers publish through it rather than the
+// Kafka writer directly so tests can swap in a MemoryPublisher.
+type Publisher interface {
+	PublishEmail(event EmailEvent) error
+}
+
+// writerPublisher publishes to the email-notifications topic
+type writerPublisher struct{}
+
+func (writerPublisher) PublishEmail(event EmailEvent) error {
+	return ProduceEmailEvent(event)
+}
+
+var publisher Publisher = writerPublisher{}
+
+// SetPublisher replaces the publisher used by PublishEmail
+func SetPublisher(p Publisher) {
+	publisher = p
+}
+
+// PublishEmail sends an email event through the configured publisher
+func PublishEmail(event EmailEvent) error {
+	return publisher.PublishEmail(event)
+}
+
+// MemoryPublisher records email events instead of sending them
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []EmailEvent
+}
+
+// NewMemoryPublisher creates an empty in-memory publisher
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (m *MemoryPublisher) PublishEmail(event EmailEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+	return nil
+}
+
+// Events returns a copy of the events published so far
+func (m *MemoryPublisher) Events() []EmailEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]EmailEvent(nil), m.events...)
+}
@@ -3,14 +3,26 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/job-portal/auth-service/config"
 	"github.com/job-portal/auth-service/handlers"
+	"github.com/job-portal/auth-service/kafka"
 	"github.com/job-portal/auth-service/middleware"
+	"github.com/job-portal/auth-service/oauth"
+	"github.com/job-portal/auth-service/policy"
+	"github.com/job-portal/auth-service/utils"
+	"github.com/job-portal/pkg/auth"
+	"github.com/job-portal/pkg/ratelimit"
 	"github.com/joho/godotenv"
 )
 
+// Rate limit policies; admins can override them at runtime (see pkg/ratelimit)
+var rateLimitPolicies = map[string]ratelimit.Policy{
+	"auth-service:default": {Limit: 600, Period: "1m", Burst: 100, By: "ip"},
+}
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load("../../.env"); err != nil {
@@ -25,15 +37,48 @@ func main() {
 	config.InitRedis()
 	defer config.CloseRedis()
 
+	// Load rate limit policies and admin overrides
+	ratelimit.Init(config.RedisClient, rateLimitPolicies)
+
+	// Initialize Kafka producer for verification emails
+	kafka.InitProducer()
+	defer kafka.CloseProducer()
+
+	// Load JWT signing keys
+	utils.InitSigningKeys()
+
+	// Check tokens this service signed against its own keys
+	auth.Init(auth.Config{Service: utils.ServiceName, Redis: config.RedisClient, Keyfunc: utils.VerificationKey})
+
+	// Load social login providers
+	oauth.InitProviders()
+
+	// Erase accounts whose deletion grace period is over
+	handlers.StartAccountPurger()
+
 	// Set up Gin router
 	router := gin.Default()
 
+	// Only trust X-Forwarded-For from known proxies (by default the frontend
+	// server on a local or private network), otherwise clients could spoof the
+	// IP used by the login and rate limits
+	proxies := os.Getenv("TRUSTED_PROXIES")
+	if proxies == "" {
+		proxies = "127.0.0.1,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"
+	}
+	if err := router.SetTrustedProxies(strings.Split(proxies, ",")); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
 	// CORS middleware
 	router.Use(middleware.CORSMiddleware())
 
 	// Prometheus metrics middleware
 	router.Use(middleware.PrometheusMiddleware())
 
+	// Default per-IP rate limit; expensive routes add their own
+	router.Use(ratelimit.Limit("auth-service:default"))
+
 	// Start metrics server on separate port
 	middleware.StartMetricsServer("9101")
 	log.Println("📊 Metrics server started on port 9101")
@@ -43,15 +88,99 @@ func main() {
 		c.JSON(200, gin.H{"status": "ok", "service": "auth-service"})
 	})
 
+	// Public signing keys for token verification
+	router.GET("/.well-known/jwks.json", handlers.GetJWKS)
+
 	// Auth routes
-	auth := router.Group("/api/auth")
+	public := router.Group("/api/auth")
 	{
-		auth.POST("/register", handlers.Register)
-		auth.POST("/login", handlers.Login)
-		auth.POST("/forgot-password", handlers.ForgotPassword)
-		auth.POST("/reset-password", handlers.ResetPassword)
+		public.POST("/register", handlers.Register)
+		public.POST("/login", handlers.Login)
+		public.POST("/forgot-password", handlers.ForgotPassword)
+		public.POST("/reset-password", handlers.ResetPassword)
+		public.POST("/magic-link", handlers.RequestMagicLink)
+		public.POST("/magic-link/verify", handlers.VerifyMagicLink)
+		public.POST("/refresh", handlers.RefreshToken)
+		public.POST("/logout", handlers.Logout)
+		public.POST("/verify-email", handlers.VerifyEmail)
+		public.POST("/resend-verification", handlers.ResendVerification)
+		public.POST("/invitations/accept", handlers.AcceptInvitation)
 	}
 
+	// Social login (OAuth2 / OpenID Connect)
+	social := router.Group("/api/auth/oauth")
+	{
+		social.GET("/providers", handlers.GetOAuthProviders)
+		social.GET("/:provider/login", handlers.StartOAuthLogin)
+		social.GET("/:provider/callback", handlers.OAuthCallback)
+		social.POST("/exchange", handlers.ExchangeOAuthCode)
+	}
+
+	// Signed-in account management
+	account := router.Group("/api/auth")
+	account.Use(middleware.AuthMiddleware())
+	{
+		account.POST("/change-password", auth.NotImpersonated(), handlers.ChangePassword)
+		account.GET("/sessions", handlers.GetSessions)
+		account.DELETE("/sessions", auth.NotImpersonated(), handlers.RevokeOtherSessions)
+		account.DELETE("/sessions/:id", auth.NotImpersonated(), handlers.RevokeSession)
+		account.POST("/api-keys", auth.NotImpersonated(), policy.Require(policy.APIKeyCreate), handlers.CreateAPIKey)
+		account.GET("/api-keys", handlers.ListAPIKeys)
+		account.DELETE("/api-keys/:id", auth.NotImpersonated(), handlers.RevokeAPIKey)
+		account.POST("/impersonation/end", handlers.StopImpersonating)
+		account.GET("/account/export", auth.NotImpersonated(), handlers.ExportAccountData)
+		account.POST("/account/deletion", auth.NotImpersonated(), handlers.RequestAccountDeletion)
+		account.DELETE("/account/deletion", auth.NotImpersonated(), handlers.CancelAccountDeletion)
+	}
+
+	// Two-factor authentication
+	mfa := router.Group("/api/auth/mfa")
+	{
+		mfa.POST("/verify", handlers.VerifyMFA) // Second login step
+		mfa.POST("/enroll", middleware.MFASetupAuth(), auth.NotImpersonated(), handlers.EnrollMFA)
+		mfa.POST("/enable", middleware.MFASetupAuth(), auth.NotImpersonated(), handlers.EnableMFA)
+		mfa.POST("/disable", middleware.AuthMiddleware(), auth.NotImpersonated(), handlers.DisableMFA)
+		mfa.POST("/recovery-codes", middleware.AuthMiddleware(), auth.NotImpersonated(), handlers.RegenerateRecoveryCodes)
+	}
+
+	// Admin account management
+	admin := router.Group("/api/auth/admin")
+	admin.Use(middleware.AuthMiddleware())
+	{
+		ban := policy.Require(policy.UserBan)
+		admin.POST("/users/:id/ban", ban, handlers.BanUser)
+		admin.DELETE("/users/:id/ban", ban, handlers.UnbanUser)
+		unlock := policy.Require(policy.UserUnlock)
+		admin.GET("/users/:id/lockout", unlock, handlers.GetUserLockout)
+		admin.DELETE("/users/:id/lockout", unlock, handlers.UnlockUser)
+		roles := policy.Require(policy.UserRoleUpdate)
+		admin.PUT("/users/:id/role", roles, handlers.ChangeUserRole)
+		admin.GET("/users/:id/role-changes", roles, handlers.GetRoleChanges)
+		invitations := policy.Require(policy.InvitationManage)
+		admin.POST("/invitations", invitations, handlers.CreateInvitation)
+		admin.GET("/invitations", invitations, handlers.ListInvitations)
+		admin.DELETE("/invitations/:id", invitations, handlers.RevokeInvitation)
+		mfaPolicies := policy.Require(policy.MFAPolicyManage)
+		admin.GET("/mfa/policies", mfaPolicies, handlers.GetMFAPolicies)
+		admin.PUT("/mfa/policies/:role", mfaPolicies, handlers.SetMFAPolicy)
+		impersonation := policy.Require(policy.UserImpersonate)
+		admin.POST("/users/:id/impersonate", impersonation, handlers.ImpersonateUser)
+		admin.GET("/impersonations", impersonation, handlers.ListImpersonations)
+		admin.DELETE("/impersonations/:id", impersonation, handlers.EndImpersonation)
+		rateLimits := policy.Require(policy.RateLimitManage)
+		admin.GET("/rate-limits", rateLimits, handlers.GetRateLimits)
+		admin.PUT("/rate-limits/:name", rateLimits, handlers.SetRateLimit)
+		admin.DELETE("/rate-limits/:name", rateLimits, handlers.ResetRateLimit)
+	}
+
+	// Internal API for other services, on its own port
+	internal := auth.NewInternalRouter(middleware.PrometheusMiddleware())
+	internal.POST("/internal/service-tokens", handlers.IssueServiceToken)
+	internal.POST("/internal/api-keys/introspect", // Lets other services accept API keys
+		auth.ServiceAuth("api-keys", "user-service", "job-service", "utility-service", "blog-service"),
+		handlers.IntrospectAPIKey)
+	auth.StartInternalServer(internal, "AUTH_SERVICE_INTERNAL_PORT", "8101")
+
 	// Start server
 	port := os.Getenv("AUTH_SERVICE_PORT")
 	if port == "" {
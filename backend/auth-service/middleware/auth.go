@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/auth-service/apikey"
+	"github.com/job-portal/auth-service/utils"
+	"github.com/job-portal/pkg/auth"
+)
+
+// AuthMiddleware validates JWT tokens from Authorization header. API keys are
+// accepted too, but only on routes that name the scopes a key needs.
+func AuthMiddleware(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.Abort()
+			return
+		}
+
+		// Extract token from "Bearer <token>"
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
+			c.Abort()
+			return
+		}
+
+		if apikey.IsAPIKey(parts[1]) {
+			authenticateAPIKey(c, parts[1], scopes)
+			return
+		}
+
+		claims, err := utils.ValidateJWT(parts[1])
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		if auth.IsRevoked(claims) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
+		// Set user info in context for handlers to use
+		c.Set("user_id", claims.UserID)
+		c.Set("user_email", claims.Email)
+		c.Set("user_role", claims.Role)
+		c.Set("email_verified", claims.EmailVerified)
+
+		// An admin is viewing the API as this user; record both on every request
+		if claims.Actor != nil {
+			c.Set("actor_id", claims.Actor.Subject)
+			log.Printf("Impersonated request: admin %s as user %s: %s %s",
+				claims.Actor.Subject, claims.UserID, c.Request.Method, c.Request.URL.Path)
+		}
+		c.Set("claims", claims)
+
+		c.Next()
+	}
+}
+
+// authenticateAPIKey sets the key owner's identity on the context, or aborts
+func authenticateAPIKey(c *gin.Context, key string, scopes []string) {
+	if len(scopes) == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot be used for this endpoint"})
+		c.Abort()
+		return
+	}
+
+	identity, err := apikey.Lookup(key)
+	if err == apikey.ErrInvalidKey {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired API key"})
+		c.Abort()
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check API key"})
+		c.Abort()
+		return
+	}
+
+	if missing := identity.MissingScope(scopes...); missing != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + missing + " scope"})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", identity.UserID)
+	c.Set("user_email", identity.Email)
+	c.Set("user_role", identity.Role)
+	c.Set("email_verified", identity.EmailVerified)
+	c.Set("api_key_id", identity.KeyID)
+
+	c.Next()
+}
+
+// MFASetupAuth authenticates 2FA enrollment. Besides a normal access token it
+// accepts the mfa_pending token given to users whose role requires 2FA but who
+// haven't set it up yet, so they can enroll before their first full login.
+func MFASetupAuth() gin.HandlerFunc {
+	auth := AuthMiddleware()
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if pending, err := utils.ValidateMFAPendingToken(parts[1]); err == nil {
+				if !pending.EnrollmentRequired {
+					c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+					c.Abort()
+					return
+				}
+				c.Set("user_id", pending.Subject)
+				c.Set("mfa_pending", pending)
+				c.Next()
+				return
+			}
+		}
+		auth(c)
+	}
+}
@@ -3,17 +3,21 @@ package models
 import "time"
 
 type User struct {
-	ID            string    `json:"id" db:"id"`
-	Name          string    `json:"name" db:"name"`
-	Email         string    `json:"email" db:"email"`
-	PasswordHash  string    `json:"-" db:"password_hash"` // Never send password hash to client
-	Phone         string    `json:"phone,omitempty" db:"phone"`
-	Role          string    `json:"role" db:"role"` // jobseeker or recruiter
-	Bio           string    `json:"bio,omitempty" db:"bio"`
-	ResumeURL     string    `json:"resume_url,omitempty" db:"resume_url"`
-	ProfilePicURL string    `json:"profile_pic_url,omitempty" db:"profile_pic_url"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	ID                   string     `json:"id" db:"id"`
+	Name                 string     `json:"name" db:"name"`
+	Email                string     `json:"email" db:"email"`
+	PasswordHash         string     `json:"-" db:"password_hash"` // Never send password hash to client
+	Phone                string     `json:"phone,omitempty" db:"phone"`
+	Role                 string     `json:"role" db:"role"` // jobseeker, recruiter or admin
+	Bio                  string     `json:"bio,omitempty" db:"bio"`
+	ResumeURL            string     `json:"resume_url,omitempty" db:"resume_url"`
+	ProfilePicURL        string     `json:"profile_pic_url,omitempty" db:"profile_pic_url"`
+	EmailVerified        bool       `json:"email_verified" db:"email_verified"`
+	MFAEnabled           bool       `json:"mfa_enabled" db:"mfa_enabled"`
+	BannedAt             *time.Time `json:"banned_at,omitempty" db:"banned_at"`
+	DeletionScheduledFor *time.Time `json:"deletion_scheduled_for,omitempty" db:"deletion_scheduled_for"` // Pending account deletion
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // User registration/login requests
@@ -22,7 +26,7 @@ type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
 	Phone    string `json:"phone"`
-	Role     string `json:"role" binding:"required,oneof=jobseeker recruiter admin"`
+	Role     string `json:"role" binding:"required,oneof=jobseeker recruiter"` // Admins are invited
 }
 
 type LoginRequest struct {
@@ -34,13 +38,218 @@ type ForgotPasswordRequest struct {
 	Email string `json:"email" binding:"required,email"`
 }
 
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type VerifyMagicLinkRequest struct {
+	Token string `json:"token" binding:"required"` // From the emailed link; the nonce comes from the magic_link_nonce cookie
+}
+
 type ResetPasswordRequest struct {
 	Token       string `json:"token" binding:"required"`
 	NewPassword string `json:"new_password" binding:"required,min=6"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type BanUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+// LockoutStatus describes an account's failed-login state (admin view)
+type LockoutStatus struct {
+	Locked         bool  `json:"locked"`
+	RetryAfter     int64 `json:"retry_after"` // Seconds until the next login attempt is allowed
+	FailedAttempts int64 `json:"failed_attempts"`
+}
+
+// Two-factor authentication
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"` // TOTP code or recovery code
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+	QRPayload  string `json:"qr_payload"` // Text to encode in the QR code scanned by authenticator apps
+}
+
+type UpdateMFAPolicyRequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
+
+type MFARolePolicy struct {
+	Role      string     `json:"role"`
+	Required  bool       `json:"required"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// Role invitations and role changes
+type CreateInvitationRequest struct {
+	Email          string `json:"email" binding:"required,email"`
+	Role           string `json:"role" binding:"required,oneof=recruiter admin"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"omitempty,min=1,max=720"` // Default 72
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+	Phone    string `json:"phone"`
+}
+
+type Invitation struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	InvitedBy  *string    `json:"invited_by,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type ChangeRoleRequest struct {
+	Role   string `json:"role" binding:"required,oneof=jobseeker recruiter admin"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+type RoleChange struct {
+	ID        string    `json:"id"`
+	OldRole   *string   `json:"old_role,omitempty"`
+	NewRole   string    `json:"new_role"`
+	ChangedBy *string   `json:"changed_by,omitempty"`
+	Source    string    `json:"source"`
+	Reason    *string   `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OAuthExchangeRequest struct {
+	Code string `json:"code" binding:"required"` // One-time code from the social login redirect
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// DeleteAccountRequest confirms an account deletion request with the user's password
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// Session is a signed-in device (a refresh token family)
+type Session struct {
+	ID         string     `json:"id"`
+	Device     string     `json:"device"`
+	IP         string     `json:"ip,omitempty"`
+	UserAgent  string     `json:"user_agent,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	Current    bool       `json:"current"`
+}
+
+// API keys
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=365"` // Default 90
+}
+
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse carries the only copy of the key ever returned
+type CreateAPIKeyResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type IntrospectAPIKeyRequest struct {
+	Key string `json:"key" binding:"required"`
+}
+
+// ServiceTokenRequest is a service asking for a token to call another service's internal API
+type ServiceTokenRequest struct {
+	Service  string `json:"service" binding:"required"`
+	Secret   string `json:"secret" binding:"required"`
+	Audience string `json:"audience" binding:"required"`
+}
+
+type ServiceTokenResponse struct {
+	Token     string `json:"token"`
+	ExpiresIn int64  `json:"expires_in"`
+}
+
+// ImpersonateRequest starts an admin "view as user" session
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required"` // e.g. the support ticket being investigated
+}
+
+type ImpersonationResponse struct {
+	ImpersonationID string `json:"impersonation_id"`
+	Token           string `json:"token"`
+	ExpiresIn       int64  `json:"expires_in"`
+	User            User   `json:"user"`
+}
+
+type Impersonation struct {
+	ID           string     `json:"id"`
+	AdminID      *string    `json:"admin_id,omitempty"`
+	TargetUserID string     `json:"target_user_id"`
+	Reason       string     `json:"reason"`
+	StartedAt    time.Time  `json:"started_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 // Auth responses
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // Access token lifetime in seconds
+	User         User   `json:"user"`
+}
+
+// MFAChallengeResponse is returned by login when a second factor is needed
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfa_required"`
+	MFAToken           string `json:"mfa_token"`
+	EnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"` // 2FA must be set up before signing in
+	ExpiresIn          int64  `json:"expires_in"`
+}
+
+// MFAEnableResponse carries the recovery codes, shown only once. When 2FA was
+// set up to finish a login, it also carries the session tokens.
+type MFAEnableResponse struct {
+	RecoveryCodes []string      `json:"recovery_codes"`
+	Auth          *AuthResponse `json:"auth,omitempty"`
 }
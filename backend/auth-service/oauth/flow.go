@@ -0,0 +1,221 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Identity is who the provider says the user is
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// PKCEChallenge derives the S256 code challenge for a code verifier (RFC 7636)
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// discover fills in missing endpoints from the issuer's discovery document.
+// A failure is retried on the next request.
+func (p *Provider) discover(ctx context.Context) error {
+	if p.Kind != KindOIDC {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovered {
+		return nil
+	}
+
+	var doc struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserinfoEndpoint      string `json:"userinfo_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+	wellKnown := strings.TrimSuffix(p.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, wellKnown, "", &doc); err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+	if doc.Issuer != p.Issuer {
+		return fmt.Errorf("discovery issuer %q does not match %q", doc.Issuer, p.Issuer)
+	}
+
+	if p.AuthURL == "" {
+		p.AuthURL = doc.AuthorizationEndpoint
+	}
+	if p.TokenURL == "" {
+		p.TokenURL = doc.TokenEndpoint
+	}
+	if p.UserInfoURL == "" {
+		p.UserInfoURL = doc.UserinfoEndpoint
+	}
+	if p.JWKSURL == "" {
+		p.JWKSURL = doc.JWKSURI
+	}
+	p.discovered = true
+	return nil
+}
+
+// AuthCodeURL builds the authorization request URL for the code flow with PKCE
+func (p *Provider) AuthCodeURL(ctx context.Context, redirectURI, state, nonce, codeVerifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.ClientID)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("scope", strings.Join(p.Scopes, " "))
+	params.Set("state", state)
+	params.Set("code_challenge", PKCEChallenge(codeVerifier))
+	params.Set("code_challenge_method", "S256")
+	if p.Kind == KindOIDC {
+		params.Set("nonce", nonce)
+	}
+
+	sep := "?"
+	if strings.Contains(p.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.AuthURL + sep + params.Encode(), nil
+}
+
+// Authenticate exchanges the authorization code and returns the user's identity.
+// For OIDC providers the ID token is validated, including its nonce.
+func (p *Provider) Authenticate(ctx context.Context, code, redirectURI, codeVerifier, nonce string) (*Identity, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	tokens, err := p.exchange(ctx, code, redirectURI, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Kind == KindGitHub {
+		return p.githubIdentity(ctx, tokens.AccessToken)
+	}
+
+	if tokens.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+	return p.validateIDToken(ctx, tokens.IDToken, nonce)
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	Error       string `json:"error"`
+	ErrorDesc   string `json:"error_description"`
+}
+
+// exchange redeems the authorization code at the token endpoint
+func (p *Provider) exchange(ctx context.Context, code, redirectURI, codeVerifier string) (*tokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("client_id", p.ClientID)
+	form.Set("client_secret", p.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tokens tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode token response (status %d): %w", resp.StatusCode, err)
+	}
+	if tokens.Error != "" {
+		return nil, fmt.Errorf("token endpoint error: %s %s", tokens.Error, tokens.ErrorDesc)
+	}
+	if resp.StatusCode != http.StatusOK || tokens.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+	return &tokens, nil
+}
+
+// githubIdentity reads the user and their primary verified email from the GitHub API
+func (p *Provider) githubIdentity(ctx context.Context, accessToken string) (*Identity, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := getJSON(ctx, p.UserInfoURL, accessToken, &user); err != nil {
+		return nil, err
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, strings.TrimSuffix(p.UserInfoURL, "/")+"/emails", accessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{Subject: fmt.Sprint(user.ID), Name: user.Name}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, e := range emails {
+		if e.Primary {
+			identity.Email = e.Email
+			identity.EmailVerified = e.Verified
+		}
+	}
+	return identity, nil
+}
+
+// getJSON GETs a JSON document, with a bearer token if given
+func getJSON(ctx context.Context, endpoint, bearer string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
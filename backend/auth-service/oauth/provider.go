@@ -0,0 +1,172 @@
+package oauth
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Providers are listed in OAUTH_PROVIDERS (e.g. "google,github,mock") and each
+// one is configured from OAUTH_<NAME>_* variables:
+//
+//	CLIENT_ID, CLIENT_SECRET   required
+//	ISSUER                     OIDC issuer; endpoints come from its discovery document
+//	AUTH_URL, TOKEN_URL,       override or replace discovery (required for non-OIDC
+//	USERINFO_URL, JWKS_URL     providers without an issuer)
+//	SCOPES                     space-separated, default "openid email profile"
+//
+// "google" and "github" come with their public endpoints filled in. GitHub
+// isn't an OIDC provider, so its identity is read from the REST API instead
+// of an ID token.
+
+// Kinds of provider
+const (
+	KindOIDC   = "oidc"
+	KindGitHub = "github"
+)
+
+// Provider is a configured identity provider
+type Provider struct {
+	Name         string
+	Kind         string
+	ClientID     string
+	ClientSecret string
+	Issuer       string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	JWKSURL      string
+	Scopes       []string
+
+	mu         sync.Mutex // Guards discovery
+	discovered bool
+	keys       *keySet
+}
+
+var (
+	providers   = map[string]*Provider{}
+	providersMu sync.RWMutex
+)
+
+// providerDefaults are the settings filled in for well-known providers
+type providerDefaults struct {
+	Kind        string
+	Issuer      string
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	Scopes      []string
+}
+
+var builtinProviders = map[string]providerDefaults{
+	"google": {
+		Kind:   KindOIDC,
+		Issuer: "https://accounts.google.com",
+	},
+	"github": {
+		Kind:        KindGitHub,
+		AuthURL:     "https://github.com/login/oauth/authorize",
+		TokenURL:    "https://github.com/login/oauth/access_token",
+		UserInfoURL: "https://api.github.com/user",
+		Scopes:      []string{"read:user", "user:email"},
+	},
+}
+
+// InitProviders loads the providers listed in OAUTH_PROVIDERS. Misconfigured
+// providers are skipped with a warning so social login can't take the service down.
+func InitProviders() {
+	loaded := map[string]*Provider{}
+	for _, name := range strings.Split(os.Getenv("OAUTH_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		p, err := loadProvider(name)
+		if err != nil {
+			log.Printf("⚠️  OAuth provider %s disabled: %v", name, err)
+			continue
+		}
+		loaded[name] = p
+	}
+
+	providersMu.Lock()
+	providers = loaded
+	providersMu.Unlock()
+
+	if len(loaded) > 0 {
+		log.Printf("✅ OAuth providers enabled: %s", strings.Join(Names(), ", "))
+	}
+}
+
+// loadProvider reads a provider's OAUTH_<NAME>_* configuration
+func loadProvider(name string) (*Provider, error) {
+	env := func(key string) string {
+		return os.Getenv(fmt.Sprintf("OAUTH_%s_%s", strings.ToUpper(name), key))
+	}
+
+	p := &Provider{Name: name, Kind: KindOIDC}
+	if builtin, ok := builtinProviders[name]; ok {
+		p.Kind = builtin.Kind
+		p.Issuer = builtin.Issuer
+		p.AuthURL = builtin.AuthURL
+		p.TokenURL = builtin.TokenURL
+		p.UserInfoURL = builtin.UserInfoURL
+		p.Scopes = builtin.Scopes
+	}
+
+	p.ClientID = env("CLIENT_ID")
+	p.ClientSecret = env("CLIENT_SECRET")
+	if p.ClientID == "" {
+		return nil, fmt.Errorf("OAUTH_%s_CLIENT_ID not set", strings.ToUpper(name))
+	}
+
+	for field, key := range map[*string]string{
+		&p.Issuer:      "ISSUER",
+		&p.AuthURL:     "AUTH_URL",
+		&p.TokenURL:    "TOKEN_URL",
+		&p.UserInfoURL: "USERINFO_URL",
+		&p.JWKSURL:     "JWKS_URL",
+	} {
+		if v := env(key); v != "" {
+			*field = v
+		}
+	}
+	if scopes := env("SCOPES"); scopes != "" {
+		p.Scopes = strings.Fields(scopes)
+	}
+	if len(p.Scopes) == 0 {
+		p.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	if p.Kind == KindOIDC && p.Issuer == "" {
+		return nil, fmt.Errorf("OAUTH_%s_ISSUER not set", strings.ToUpper(name))
+	}
+	if p.Kind == KindGitHub && (p.AuthURL == "" || p.TokenURL == "" || p.UserInfoURL == "") {
+		return nil, fmt.Errorf("missing endpoints")
+	}
+	p.keys = &keySet{}
+	return p, nil
+}
+
+// Get returns the named provider, if enabled
+func Get(name string) (*Provider, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	p, ok := providers[name]
+	return p, ok
+}
+
+// Names lists the enabled providers
+func Names() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,51 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/pkg/policy"
+)
+
+// Authorization is declared here rather than in handlers: each role is granted
+// a set of permissions, either on any resource or only on resources it owns.
+// Routes check that the caller's role holds a permission with Require.
+// Adding a role such as moderator means adding an entry to Default. The
+// evaluation itself is shared (pkg/policy).
+
+// Permission names an action on a kind of resource
+type Permission = policy.Permission
+
+// Permissions checked by auth-service
+const (
+	APIKeyCreate     Permission = "api_key.create"
+	UserBan          Permission = "user.ban"
+	UserUnlock       Permission = "user.unlock"
+	UserRoleUpdate   Permission = "user.role.update"
+	InvitationManage Permission = "invitation.manage"
+	MFAPolicyManage  Permission = "mfa.policy.manage"
+	UserImpersonate  Permission = "user.impersonate"
+	RateLimitManage  Permission = "rate_limit.manage"
+)
+
+// Default is the policy every handler is checked against
+var Default = &policy.Policy{
+	Grants: policy.Grants{
+		"recruiter": {
+			APIKeyCreate: policy.Own,
+		},
+		"admin": {
+			APIKeyCreate:     policy.Own,
+			UserBan:          policy.Any,
+			UserUnlock:       policy.Any,
+			UserRoleUpdate:   policy.Any,
+			InvitationManage: policy.Any,
+			MFAPolicyManage:  policy.Any,
+			UserImpersonate:  policy.Any,
+			RateLimitManage:  policy.Any,
+		},
+	},
+}
+
+// Require middleware ensures the caller's role holds perm
+func Require(perm Permission) gin.HandlerFunc {
+	return Default.Require(perm)
+}
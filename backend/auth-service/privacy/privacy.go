@@ -0,0 +1,249 @@
+package privacy
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/utils"
+	"github.com/job-portal/pkg/privacy"
+)
+
+// auth-service orchestrates data exports and account erasure. Its own tables
+// are one participant; other services are reached over their internal API
+// (see Remote and pkg/privacy, which declares the participant side).
+
+// Participant is a named share of a user's data
+type Participant interface {
+	Name() string
+	privacy.Participant
+}
+
+type (
+	Export = privacy.Export
+	File   = privacy.File
+)
+
+// QueryRecords runs query on db and returns its rows as a JSON array
+func QueryRecords(db *sql.DB, query string, args ...interface{}) (json.RawMessage, error) {
+	return privacy.QueryRecords(db, query, args...)
+}
+
+// Manifest describes an export archive
+type Manifest struct {
+	UserID       string    `json:"user_id"`
+	GeneratedAt  time.Time `json:"generated_at"`
+	Participants []string  `json:"participants"`
+	MissingFiles []string  `json:"missing_files,omitempty"`
+}
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// CheckCoverage fails unless every table with a foreign key to users (and users
+// itself) is claimed by a participant
+func CheckCoverage(participants []Participant) error {
+	claimed := map[string]bool{}
+	for _, p := range participants {
+		tables, err := p.Tables()
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.Name(), err)
+		}
+		for _, t := range tables {
+			claimed[t] = true
+		}
+	}
+
+	rows, err := config.DB.Query(`
+		SELECT DISTINCT tc.table_name
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.constraint_column_usage ccu
+		  ON ccu.constraint_name = tc.constraint_name AND ccu.table_schema = tc.table_schema
+		WHERE tc.constraint_type = 'FOREIGN KEY'
+		  AND ccu.table_name = 'users'
+		  AND tc.table_schema = current_schema()
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	tables := []string{"users"}
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return err
+		}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, t := range tables {
+		if !claimed[t] {
+			missing = append(missing, t)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("tables with user data not handled by any participant: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Archive is a user's data collected from every participant
+type Archive struct {
+	userID       string
+	participants []Participant
+	exports      []*Export
+}
+
+// Collect gathers every participant's export of the user's data
+func Collect(userID string, participants []Participant) (*Archive, error) {
+	archive := &Archive{userID: userID, participants: participants}
+	for _, p := range participants {
+		export, err := p.Export(userID)
+		if err != nil {
+			return nil, fmt.Errorf("%s export: %w", p.Name(), err)
+		}
+		archive.exports = append(archive.exports, export)
+	}
+	return archive, nil
+}
+
+// Write writes the archive to w as a ZIP file, fetching the user's uploaded files into it
+func (a *Archive) Write(w io.Writer) error {
+	manifest := Manifest{UserID: a.userID, GeneratedAt: time.Now().UTC()}
+	zw := zip.NewWriter(w)
+
+	for i, p := range a.participants {
+		manifest.Participants = append(manifest.Participants, p.Name())
+		for name, record := range a.exports[i].Records {
+			if err := writeJSON(zw, path.Join(p.Name(), name+".json"), record); err != nil {
+				return err
+			}
+		}
+		for _, file := range a.exports[i].Files {
+			// A missing upload shouldn't stop the rest of the export
+			if err := copyFile(zw, path.Join(p.Name(), "files", path.Base(file.Name)), file.URL); err != nil {
+				log.Printf("Export for user %s: failed to fetch %s: %v", a.userID, file.URL, err)
+				manifest.MissingFiles = append(manifest.MissingFiles, file.Name)
+			}
+		}
+	}
+
+	if err := writeJSON(zw, "manifest.json", manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// Erase has every participant erase the user's data, in order. Participants
+// must be safe to re-run, so a failed erasure is simply retried.
+func Erase(userID string, participants []Participant) error {
+	for _, p := range participants {
+		if err := p.Erase(userID); err != nil {
+			return fmt.Errorf("%s erase: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
+
+func writeJSON(archive *zip.Writer, name string, value interface{}) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func copyFile(archive *zip.Writer, name, url string) error {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
+
+// remote is a participant reached over HTTP
+type remote struct {
+	name    string
+	baseURL string
+}
+
+// Remote returns a participant for the service at baseURL
+func Remote(name, baseURL string) Participant {
+	return &remote{name: name, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (r *remote) Name() string { return r.name }
+
+func (r *remote) Tables() ([]string, error) {
+	var resp struct {
+		Tables []string `json:"tables"`
+	}
+	err := r.call(http.MethodGet, "/internal/privacy/tables", &resp)
+	return resp.Tables, err
+}
+
+func (r *remote) Export(userID string) (*Export, error) {
+	var export Export
+	if err := r.call(http.MethodGet, "/internal/privacy/users/"+userID, &export); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *remote) Erase(userID string) error {
+	return r.call(http.MethodDelete, "/internal/privacy/users/"+userID, nil)
+}
+
+func (r *remote) call(method, endpoint string, out interface{}) error {
+	token, err := utils.GenerateServiceToken(utils.ServiceName, privacy.Audience)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, r.baseURL+endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: status %d: %s", method, endpoint, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/job-portal/auth-service/config"
+	"github.com/job-portal/auth-service/utils"
+	"github.com/joho/godotenv"
+)
+
+// Creates the first admin account, or promotes an existing account to admin.
+// Further admins should be invited from the admin API instead.
+//
+//	go run ./scripts/create_admin -email admin@example.com -name "Admin"
+//
+// The password is read from ADMIN_PASSWORD or prompted for on stdin. Once an
+// admin exists the script refuses to run unless -force is given.
+func main() {
+	email := flag.String("email", "", "admin email address (required)")
+	name := flag.String("name", "Admin", "display name for a new account")
+	force := flag.Bool("force", false, "run even if an admin already exists")
+	flag.Parse()
+
+	if *email == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load("../../.env"); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	// Initialize database connection
+	config.InitDB()
+	defer config.CloseDB()
+
+	var admins int
+	if err := config.DB.QueryRow("SELECT COUNT(*) FROM users WHERE role = 'admin'").Scan(&admins); err != nil {
+		log.Fatalf("Failed to count admins: %v", err)
+	}
+	if admins > 0 && !*force {
+		log.Fatalf("❌ %d admin account(s) already exist; invite new admins from the admin API or pass -force", admins)
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		log.Fatalf("Failed to start transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var userID, oldRole string
+	err = tx.QueryRow("SELECT id, role FROM users WHERE LOWER(email) = LOWER($1) FOR UPDATE", *email).Scan(&userID, &oldRole)
+	switch {
+	case err == sql.ErrNoRows:
+		password := readPassword()
+		hashedPassword, err := utils.HashPassword(password)
+		if err != nil {
+			log.Fatalf("Failed to hash password: %v", err)
+		}
+		err = tx.QueryRow(`
+			INSERT INTO users (name, email, password_hash, role, email_verified, email_verified_at)
+			VALUES ($1, $2, $3, 'admin', TRUE, CURRENT_TIMESTAMP)
+			RETURNING id
+		`, *name, *email, hashedPassword).Scan(&userID)
+		if err != nil {
+			log.Fatalf("Failed to create admin: %v", err)
+		}
+	case err != nil:
+		log.Fatalf("Failed to look up user: %v", err)
+	case oldRole == "admin":
+		log.Printf("✅ %s is already an admin", *email)
+		return
+	default:
+		// Existing accounts keep their password
+		if _, err := tx.Exec("UPDATE users SET role = 'admin', updated_at = CURRENT_TIMESTAMP WHERE id = $1", userID); err != nil {
+			log.Fatalf("Failed to promote user: %v", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO role_change_audit (user_id, old_role, new_role, source, reason)
+		VALUES ($1, NULLIF($2, ''), 'admin', 'bootstrap', 'create_admin script')
+	`, userID, oldRole)
+	if err != nil {
+		log.Fatalf("Failed to record role change: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Fatalf("Failed to commit: %v", err)
+	}
+
+	if oldRole == "" {
+		log.Printf("✅ Created admin %s (%s)", *email, userID)
+	} else {
+		log.Printf("✅ Promoted %s (%s) from %s to admin", *email, userID, oldRole)
+	}
+}
+
+// readPassword takes the new admin's password from ADMIN_PASSWORD or stdin
+func readPassword() string {
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		fmt.Print("Password for the new admin: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			log.Fatalf("Failed to read password: %v", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if len(password) < 6 {
+		log.Fatal("❌ Password must be at least 6 characters")
+	}
+	return password
+}
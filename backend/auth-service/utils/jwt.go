@@ -5,56 +5,222 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/job-portal/pkg/auth"
 )
 
-type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+// Claims and Actor are shared with the services that verify access tokens
+type (
+	Claims = auth.Claims
+	Actor  = auth.Actor
+)
+
+// EmailVerificationClaims are carried by the link sent to confirm an email address
+type EmailVerificationClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// MFAPendingClaims identify a user who passed the password check but still
+// owes a second factor (or, if EnrollmentRequired, has to set one up first)
+type MFAPendingClaims struct {
+	EnrollmentRequired bool `json:"enrollment_required,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT generates a JWT token for a user
-func GenerateJWT(userID, email, role string) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "default-secret-change-this"
+// RoleInvitationClaims are carried by the link inviting someone to sign up
+// with a privileged role. The subject is the invitation ID.
+type RoleInvitationClaims struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Audiences keep these special-purpose tokens from being accepted as access tokens
+const (
+	emailVerificationAudience = "email-verification"
+	mfaPendingAudience        = "mfa_pending"
+	roleInvitationAudience    = "role-invitation"
+)
+
+// MFAPendingTTL is how long the user has to enter their second factor after the password
+const MFAPendingTTL = 5 * time.Minute
+
+// AccessTokenTTL returns the lifetime of access tokens (JWT_EXPIRY, default 15m)
+func AccessTokenTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("JWT_EXPIRY")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 15 * time.Minute
+}
+
+// GenerateJWT generates a JWT token for a user's session, signed with the active key
+func GenerateJWT(userID, email, role string, emailVerified bool, sessionID string) (string, error) {
+	// jti lets a single token be revoked before it expires
+	jti, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", err
 	}
 
-	expirationTime := time.Now().Add(24 * time.Hour)
+	expirationTime := time.Now().Add(AccessTokenTTL())
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:        userID,
+		Email:         email,
+		Role:          role,
+		EmailVerified: emailVerified,
+		SessionID:     sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return signToken(claims)
 }
 
-// ValidateJWT validates and parses a JWT token
-func ValidateJWT(tokenString string) (*Claims, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "default-secret-change-this"
+// GenerateImpersonationToken signs an access token for userID on behalf of actor.
+// Its jti is the impersonation ID, so ending the impersonation revokes it. It
+// carries no session and can't be refreshed.
+func GenerateImpersonationToken(impersonationID, userID, email, role string, emailVerified bool, actor Actor, expiresAt time.Time) (string, error) {
+	claims := &Claims{
+		UserID:        userID,
+		Email:         email,
+		Role:          role,
+		EmailVerified: emailVerified,
+		Actor:         &actor,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        impersonationID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
 	}
+	return signToken(claims)
+}
 
+// ValidateJWT validates and parses a JWT token
+func ValidateJWT(tokenString string) (*Claims, error) {
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, claims, VerificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}))
 
 	if err != nil {
 		return nil, err
 	}
 
-	if !token.Valid {
+	// Other token types (e.g. email verification) carry no user_id
+	if !token.Valid || claims.UserID == "" {
 		return nil, jwt.ErrSignatureInvalid
 	}
 
 	return claims, nil
 }
+
+// GenerateEmailVerificationToken signs a 24-hour token confirming that userID owns email
+func GenerateEmailVerificationToken(userID, email string) (string, error) {
+	claims := &EmailVerificationClaims{
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{emailVerificationAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return signToken(claims)
+}
+
+// ValidateEmailVerificationToken validates a verification token and returns its claims
+func ValidateEmailVerificationToken(tokenString string) (*EmailVerificationClaims, error) {
+	claims := &EmailVerificationClaims{}
+	if err := parseScopedToken(tokenString, claims, emailVerificationAudience); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// GenerateMFAPendingToken signs the short-lived token returned by the first login step
+func GenerateMFAPendingToken(userID string, enrollmentRequired bool) (string, error) {
+	jti, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	claims := &MFAPendingClaims{
+		EnrollmentRequired: enrollmentRequired,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{mfaPendingAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(MFAPendingTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return signToken(claims)
+}
+
+// ValidateMFAPendingToken validates an mfa_pending token and returns its claims
+func ValidateMFAPendingToken(tokenString string) (*MFAPendingClaims, error) {
+	claims := &MFAPendingClaims{}
+	if err := parseScopedToken(tokenString, claims, mfaPendingAudience); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// GenerateRoleInvitationToken signs the link for an invitation, valid until expiresAt
+func GenerateRoleInvitationToken(invitationID, email, role string, expiresAt time.Time) (string, error) {
+	claims := &RoleInvitationClaims{
+		Email: email,
+		Role:  role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   invitationID,
+			Audience:  jwt.ClaimStrings{roleInvitationAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return signToken(claims)
+}
+
+// ValidateRoleInvitationToken validates an invitation token and returns its claims
+func ValidateRoleInvitationToken(tokenString string) (*RoleInvitationClaims, error) {
+	claims := &RoleInvitationClaims{}
+	if err := parseScopedToken(tokenString, claims, roleInvitationAudience); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// ServiceName identifies auth-service as the subject of the service tokens it signs
+const ServiceName = "auth-service"
+
+// ServiceTokenTTL keeps service tokens good for a single call, or a short burst of them
+const ServiceTokenTTL = time.Minute
+
+// GenerateServiceToken signs a token authorizing service to call another
+// service's internal endpoints for audience (e.g. "privacy", "email")
+func GenerateServiceToken(service, audience string) (string, error) {
+	claims := &jwt.RegisteredClaims{
+		Subject:   service,
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ServiceTokenTTL)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	}
+	return signToken(claims)
+}
+
+// parseScopedToken validates a token issued for a single purpose (audience) to a subject
+func parseScopedToken(tokenString string, claims jwt.Claims, audience string) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, VerificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithAudience(audience))
+	if err != nil {
+		return err
+	}
+
+	if subject, err := claims.GetSubject(); !token.Valid || err != nil || subject == "" {
+		return jwt.ErrSignatureInvalid
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Signing keys live in JWT_KEYS_DIR as PEM private keys named <kid>.pem
+// (RSA -> RS256, Ed25519 -> EdDSA). Every key in the directory is published
+// in the JWKS; the active one signs new tokens. Rotation:
+//
+//  1. Drop the new key file in the directory. It is picked up on the next
+//     reload and becomes active (the newest kid by name, unless JWT_ACTIVE_KID
+//     pins one). Verifiers refetch the JWKS when they see an unknown kid.
+//  2. Leave the previous key file in place for at least one access token
+//     lifetime (the overlap window) so tokens it signed keep verifying.
+//  3. Delete the previous key file.
+
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer
+}
+
+type keyRing struct {
+	mu     sync.RWMutex
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+var signingKeys = &keyRing{keys: map[string]*signingKey{}}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// InitSigningKeys loads the signing keys and keeps reloading them so rotation
+// doesn't need a restart. Without JWT_KEYS_DIR an ephemeral key is generated,
+// which only works for a single auth-service instance.
+func InitSigningKeys() {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			log.Fatal("Failed to generate signing key:", err)
+		}
+		kid, _ := GenerateRandomToken(8)
+		sk := &signingKey{kid: "ephemeral-" + kid, method: jwt.SigningMethodRS256, private: key}
+		signingKeys.set(map[string]*signingKey{sk.kid: sk}, sk)
+		log.Println("⚠️  JWT_KEYS_DIR not set, using an ephemeral RS256 key (tokens won't survive a restart)")
+		return
+	}
+
+	if err := reloadSigningKeys(dir); err != nil {
+		log.Fatal("Failed to load signing keys:", err)
+	}
+
+	interval := 5 * time.Minute
+	if d, err := time.ParseDuration(os.Getenv("JWT_KEYS_RELOAD_INTERVAL")); err == nil && d > 0 {
+		interval = d
+	}
+	go func() {
+		for range time.Tick(interval) {
+			if err := reloadSigningKeys(dir); err != nil {
+				log.Printf("Failed to reload signing keys, keeping the current set: %v", err)
+			}
+		}
+	}()
+}
+
+// reloadSigningKeys reads every <kid>.pem in dir and picks the active key
+func reloadSigningKeys(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no *.pem keys found in %s", dir)
+	}
+	sort.Strings(files)
+
+	keys := make(map[string]*signingKey, len(files))
+	var newest *signingKey
+	for _, file := range files {
+		sk, err := loadSigningKey(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		keys[sk.kid] = sk
+		newest = sk
+	}
+
+	active := newest
+	if kid := os.Getenv("JWT_ACTIVE_KID"); kid != "" {
+		var ok bool
+		if active, ok = keys[kid]; !ok {
+			return fmt.Errorf("JWT_ACTIVE_KID %q not found in %s", kid, dir)
+		}
+	}
+
+	if previous := signingKeys.activeKid(); previous != active.kid {
+		log.Printf("🔑 Signing with key %s (%s), %d key(s) published", active.kid, active.method.Alg(), len(keys))
+	}
+	signingKeys.set(keys, active)
+	return nil
+}
+
+// loadSigningKey parses a PEM private key; the kid is the file name without extension
+func loadSigningKey(file string) (*signingKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data")
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	kid := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &signingKey{kid: kid, method: jwt.SigningMethodRS256, private: key}, nil
+	case ed25519.PrivateKey:
+		return &signingKey{kid: kid, method: jwt.SigningMethodEdDSA, private: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (use RSA or Ed25519)", parsed)
+	}
+}
+
+func (r *keyRing) set(keys map[string]*signingKey, active *signingKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = keys
+	r.active = active
+}
+
+func (r *keyRing) activeKid() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.active == nil {
+		return ""
+	}
+	return r.active.kid
+}
+
+// signToken signs claims with the active key and stamps its kid in the header
+func signToken(claims jwt.Claims) (string, error) {
+	signingKeys.mu.RLock()
+	active := signingKeys.active
+	signingKeys.mu.RUnlock()
+	if active == nil {
+		return "", fmt.Errorf("signing keys not initialized")
+	}
+
+	token := jwt.NewWithClaims(active.method, claims)
+	token.Header["kid"] = active.kid
+	return token.SignedString(active.private)
+}
+
+// VerificationKey is the jwt.Keyfunc for tokens signed by any published key
+func VerificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	signingKeys.mu.RLock()
+	sk, ok := signingKeys.keys[kid]
+	signingKeys.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != sk.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+	return sk.private.Public(), nil
+}
+
+// PublicJWKS returns the public half of every published signing key
+func PublicJWKS() JWKS {
+	signingKeys.mu.RLock()
+	defer signingKeys.mu.RUnlock()
+
+	jwks := JWKS{Keys: []JWK{}}
+	for kid, sk := range signingKeys.keys {
+		jwk := JWK{Kid: kid, Use: "sig", Alg: sk.method.Alg()}
+		switch pub := sk.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool { return jwks.Keys[i].Kid < jwks.Keys[j].Kid })
+	return jwks
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+// The RFC 6238 Appendix B key, ASCII "12345678901234567890", in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTPVectors(t *testing.T) {
+	// RFC 6238 Appendix B, SHA-1. The RFC lists 8-digit codes; 6-digit codes
+	// are their last six digits.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		step, ok := ValidateTOTP(rfcSecret, tt.code, time.Unix(tt.unix, 0))
+		if !ok || step != tt.unix/totpPeriod {
+			t.Errorf("ValidateTOTP(%s) at %d = %d, %v, want step %d", tt.code, tt.unix, step, ok, tt.unix/totpPeriod)
+		}
+	}
+
+	// Secrets are accepted in lower case, as some apps display them
+	if _, ok := ValidateTOTP("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "287082", time.Unix(59, 0)); !ok {
+		t.Error("lower-case secret rejected")
+	}
+	if _, ok := ValidateTOTP("not base32!", "287082", time.Unix(59, 0)); ok {
+		t.Error("invalid secret accepted")
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	// 1111111111 is in step 37037037; its code is accepted one step either side
+	const step = 1111111111 / totpPeriod
+	at := func(s int64) time.Time { return time.Unix(s*totpPeriod+10, 0) }
+
+	for _, s := range []int64{step - 1, step, step + 1} {
+		if matched, ok := ValidateTOTP(rfcSecret, "050471", at(s)); !ok || matched != step {
+			t.Errorf("at step %d: got %d, %v, want step %d", s, matched, ok, step)
+		}
+	}
+	for _, s := range []int64{step - 2, step + 2} {
+		if _, ok := ValidateTOTP(rfcSecret, "050471", at(s)); ok {
+			t.Errorf("code accepted %d steps away", s-step)
+		}
+	}
+}
+
+func TestValidateTOTPLength(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "94287082", " 287082", "287082 "} {
+		if _, ok := ValidateTOTP(rfcSecret, code, now); ok {
+			t.Errorf("ValidateTOTP accepted %q", code)
+		}
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	code, err := GenerateRecoveryCode()
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCode: %v", err)
+	}
+	if !regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`).MatchString(code) {
+		t.Errorf("recovery code %q, want xxxxx-xxxxx in lower-case base32", code)
+	}
+
+	for _, typed := range []string{"abcde-fghij", "ABCDE-FGHIJ", " abcde fghij ", "abcdefghij", "ab-cde-fg hij"} {
+		if got := NormalizeRecoveryCode(typed); got != "abcdefghij" {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want abcdefghij", typed, got)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/job-portal/blog-service/config"
 	"github.com/job-portal/blog-service/models"
+	"github.com/job-portal/blog-service/policy"
 	"github.com/job-portal/blog-service/utils"
 	"github.com/lib/pq"
 )
@@ -308,6 +309,9 @@ func CreateBlog(c *gin.Context) {
 	if status == "" {
 		status = "draft"
 	}
+	if status == "published" && !policy.Authorize(c, policy.BlogPublish, authorID) {
+		return
+	}
 
 	// Set published_at if publishing
 	var publishedAt *time.Time
@@ -358,8 +362,8 @@ func UpdateBlog(c *gin.Context) {
 	}
 
 	// Check blog exists
-	var existingStatus string
-	err := config.DB.QueryRow(`SELECT status FROM blogs WHERE id = $1`, blogID).Scan(&existingStatus)
+	var existingStatus, authorID string
+	err := config.DB.QueryRow(`SELECT status, author_id FROM blogs WHERE id = $1`, blogID).Scan(&existingStatus, &authorID)
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
 		return
@@ -369,6 +373,14 @@ func UpdateBlog(c *gin.Context) {
 		return
 	}
 
+	if !policy.Authorize(c, policy.BlogUpdate, authorID) {
+		return
+	}
+	// Changing the status is publishing, which may be granted separately
+	if req.Status != "" && req.Status != existingStatus && !policy.Authorize(c, policy.BlogPublish, authorID) {
+		return
+	}
+
 	// Build dynamic update
 	setParts := []string{}
 	args := []interface{}{}
@@ -483,6 +495,11 @@ func UpdateBlog(c *gin.Context) {
 func DeleteBlog(c *gin.Context) {
 	blogID := c.Param("id")
 
+	authorID, ok := blogAuthorID(c, blogID)
+	if !ok || !policy.Authorize(c, policy.BlogDelete, authorID) {
+		return
+	}
+
 	result, err := config.DB.Exec(`DELETE FROM blogs WHERE id = $1`, blogID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete blog"})
@@ -508,6 +525,11 @@ func PublishBlog(c *gin.Context) {
 		return
 	}
 
+	authorID, ok := blogAuthorID(c, blogID)
+	if !ok || !policy.Authorize(c, policy.BlogPublish, authorID) {
+		return
+	}
+
 	var publishedAt *time.Time
 	if req.Status == "published" {
 		now := time.Now()
@@ -531,3 +553,17 @@ func PublishBlog(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Blog status updated to " + req.Status})
 }
+
+// blogAuthorID looks up who wrote a blog, responding with 404 if it doesn't exist
+func blogAuthorID(c *gin.Context, blogID string) (string, bool) {
+	var authorID string
+	err := config.DB.QueryRow(`SELECT author_id FROM blogs WHERE id = $1`, blogID).Scan(&authorID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blog not found"})
+		return "", false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return "", false
+	}
+	return authorID, true
+}
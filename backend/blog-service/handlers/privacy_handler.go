@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/job-portal/blog-service/config"
+	"github.com/job-portal/pkg/privacy"
+)
+
+// PrivacyParticipant exports and erases the posts a user has written
+type PrivacyParticipant struct{}
+
+func (PrivacyParticipant) Tables() ([]string, error) {
+	return []string{"blogs"}, nil
+}
+
+func (PrivacyParticipant) Export(userID string) (*privacy.Export, error) {
+	records, err := privacy.QueryRecords(config.DB, `
+		SELECT b.id, b.title, b.slug, b.content, b.excerpt, b.cover_image_url, bc.name AS category,
+		       b.tags, b.status, b.published_at, b.created_at, b.updated_at
+		FROM blogs b
+		LEFT JOIN blog_categories bc ON b.category_id = bc.id
+		WHERE b.author_id = $1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &privacy.Export{Records: map[string]json.RawMessage{"posts": records}}, nil
+}
+
+func (PrivacyParticipant) Erase(userID string) error {
+	_, err := config.DB.Exec("DELETE FROM blogs WHERE author_id = $1", userID)
+	return err
+}
@@ -3,14 +3,24 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/job-portal/blog-service/config"
 	"github.com/job-portal/blog-service/handlers"
 	"github.com/job-portal/blog-service/middleware"
+	"github.com/job-portal/blog-service/policy"
+	"github.com/job-portal/pkg/auth"
+	"github.com/job-portal/pkg/privacy"
+	"github.com/job-portal/pkg/ratelimit"
 	"github.com/joho/godotenv"
 )
 
+// Rate limit policies; admins can override them at runtime (see pkg/ratelimit)
+var rateLimitPolicies = map[string]ratelimit.Policy{
+	"blog-service:default": {Limit: 600, Period: "1m", Burst: 100, By: "ip"},
+}
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load("../../.env"); err != nil {
@@ -21,15 +31,39 @@ func main() {
 	config.InitDB()
 	defer config.CloseDB()
 
+	// Initialize Redis connection (token revocation checks)
+	config.InitRedis()
+	defer config.CloseRedis()
+
+	// Load rate limit policies and admin overrides
+	ratelimit.Init(config.RedisClient, rateLimitPolicies)
+
+	// Verify tokens against auth-service's public signing keys
+	auth.Init(auth.Config{Service: "blog-service", Redis: config.RedisClient})
+
 	// Set up Gin router
 	router := gin.Default()
 
+	// Only trust X-Forwarded-For from known proxies (by default the frontend
+	// server on a local or private network), otherwise clients could spoof the
+	// IP their rate limits are counted against
+	proxies := os.Getenv("TRUSTED_PROXIES")
+	if proxies == "" {
+		proxies = "127.0.0.1,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"
+	}
+	if err := router.SetTrustedProxies(strings.Split(proxies, ",")); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
 	// CORS middleware
 	router.Use(middleware.CORSMiddleware())
 
 	// Prometheus metrics middleware
 	router.Use(middleware.PrometheusMiddleware())
 
+	// Default per-IP rate limit; expensive routes add their own
+	router.Use(ratelimit.Limit("blog-service:default"))
+
 	// Start metrics server on separate port
 	middleware.StartMetricsServer("9105")
 	log.Println("📊 Metrics server started on port 9105")
@@ -55,24 +89,24 @@ func main() {
 	}
 
 	// ========================================
-	// Admin routes (require auth + admin role)
+	// Admin routes (require auth + a permission granted in policy)
 	// ========================================
 	admin := router.Group("/api/admin")
-	admin.Use(middleware.AuthMiddleware())
-	admin.Use(middleware.AdminOnly())
+	admin.Use(auth.Middleware())
 	{
 		// Blog management
 		adminBlogs := admin.Group("/blogs")
 		{
-			adminBlogs.GET("", handlers.AdminListBlogs)            // List all blogs (incl. drafts)
-			adminBlogs.POST("", handlers.CreateBlog)               // Create blog
-			adminBlogs.PUT("/:id", handlers.UpdateBlog)            // Update blog
-			adminBlogs.DELETE("/:id", handlers.DeleteBlog)         // Delete blog
-			adminBlogs.PATCH("/:id/publish", handlers.PublishBlog) // Toggle publish status
+			adminBlogs.GET("", policy.Require(policy.BlogListAll), handlers.AdminListBlogs)            // List all blogs (incl. drafts)
+			adminBlogs.POST("", policy.Require(policy.BlogCreate), handlers.CreateBlog)                // Create blog
+			adminBlogs.PUT("/:id", policy.Require(policy.BlogUpdate), handlers.UpdateBlog)             // Update blog
+			adminBlogs.DELETE("/:id", policy.Require(policy.BlogDelete), handlers.DeleteBlog)          // Delete blog
+			adminBlogs.PATCH("/:id/publish", policy.Require(policy.BlogPublish), handlers.PublishBlog) // Toggle publish status
 		}
 
 		// Category management
 		adminCategories := admin.Group("/categories")
+		adminCategories.Use(policy.Require(policy.CategoryManage))
 		{
 			adminCategories.POST("", handlers.CreateCategory)       // Create category
 			adminCategories.PUT("/:id", handlers.UpdateCategory)    // Update category
@@ -80,6 +114,14 @@ func main() {
 		}
 	}
 
+	// Internal API for other services, on its own port
+	internal := auth.NewInternalRouter(middleware.PrometheusMiddleware())
+
+	// Data export and erasure for auth-service
+	privacy.Register(internal, handlers.PrivacyParticipant{})
+
+	auth.StartInternalServer(internal, "BLOG_SERVICE_INTERNAL_PORT", "8105")
+
 	// Start server
 	port := os.Getenv("BLOG_SERVICE_PORT")
 	if port == "" {
@@ -1,73 +0,0 @@
-package middleware
-
-import (
-	"net/http"
-	"os"
-	"strings"
-
-	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-)
-
-type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
-	jwt.RegisteredClaims
-}
-
-// AuthMiddleware validates JWT tokens from Authorization header
-func AuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
-			return
-		}
-
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
-			c.Abort()
-			return
-		}
-
-		tokenString := parts[1]
-		claims := &Claims{}
-
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "default-secret-change-this"
-		}
-
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
-			return
-		}
-
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
-
-		c.Next()
-	}
-}
-
-// AdminOnly middleware ensures only admins can access the endpoint
-func AdminOnly() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		role := c.GetString("user_role")
-		if role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can perform this action"})
-			c.Abort()
-			return
-		}
-		c.Next()
-	}
-}
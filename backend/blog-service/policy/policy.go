@@ -0,0 +1,66 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/pkg/policy"
+)
+
+// Authorization is declared here rather than in handlers: each role is granted
+// a set of permissions, either on any resource or only on resources it owns.
+// Routes check that the caller's role holds a permission at all with Require;
+// handlers check ownership once they have loaded the resource with Authorize.
+// Adding a role such as editor means adding an entry to Default. The
+// evaluation itself is shared (pkg/policy).
+
+// Permission names an action on a kind of resource
+type Permission = policy.Permission
+
+// Permissions checked by blog-service
+const (
+	BlogCreate     Permission = "blog.create"
+	BlogUpdate     Permission = "blog.update"
+	BlogDelete     Permission = "blog.delete"
+	BlogPublish    Permission = "blog.publish"
+	BlogListAll    Permission = "blog.list_all"
+	CategoryManage Permission = "category.manage"
+)
+
+// Default is the policy every handler is checked against
+var Default = &policy.Policy{
+	Grants: policy.Grants{
+		"admin": {
+			BlogCreate:     policy.Any,
+			BlogUpdate:     policy.Any,
+			BlogDelete:     policy.Any,
+			BlogPublish:    policy.Any,
+			BlogListAll:    policy.Any,
+			CategoryManage: policy.Any,
+		},
+	},
+	Explain: explain,
+}
+
+// notOwnerMessages explain ownership denials in terms the caller will recognise
+var notOwnerMessages = map[Permission]string{
+	BlogUpdate:  "You can only edit your own posts",
+	BlogDelete:  "You can only delete your own posts",
+	BlogPublish: "You can only publish your own posts",
+}
+
+func explain(perm Permission, reason policy.Reason) string {
+	if reason == policy.NotOwner {
+		return notOwnerMessages[perm]
+	}
+	return ""
+}
+
+// Require middleware ensures the caller's role holds perm
+func Require(perm Permission) gin.HandlerFunc {
+	return Default.Require(perm)
+}
+
+// Authorize checks perm against a resource owned by ownerID, e.g. the author of
+// a blog post, responding with 403 and returning false if the caller may not perform it
+func Authorize(c *gin.Context, perm Permission, ownerID string) bool {
+	return Default.Authorize(c, perm, policy.Owner(ownerID))
+}
@@ -0,0 +1,72 @@
+package policy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// callerContext is a request context as auth.Middleware leaves it
+func callerContext(values map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	for k, v := range values {
+		c.Set(k, v)
+	}
+	return c, w
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller map[string]string
+		perm   Permission
+		status int
+	}{
+		{"admin", map[string]string{"user_id": "a1", "user_role": "admin"}, BlogCreate, http.StatusOK},
+		{"recruiter", map[string]string{"user_id": "u1", "user_role": "recruiter"}, BlogCreate, http.StatusForbidden},
+		{"admin managing categories", map[string]string{"user_id": "a1", "user_role": "admin"}, CategoryManage, http.StatusOK},
+		{"jobseeker managing categories", map[string]string{"user_id": "u1", "user_role": "jobseeker"}, CategoryManage, http.StatusForbidden},
+		{"recruiter listing drafts", map[string]string{"user_id": "u1", "user_role": "recruiter"}, BlogListAll, http.StatusForbidden},
+		// An impersonation token carries the impersonated user's role, so an
+		// admin acting as a recruiter can't manage the blog
+		{"admin impersonating recruiter", map[string]string{"user_id": "u1", "user_role": "recruiter", "actor_id": "a1"}, BlogCreate, http.StatusForbidden},
+		// API keys act with their owner's role; a scope doesn't add permissions
+		{"API key of an admin", map[string]string{"user_id": "a1", "user_role": "admin", "api_key_id": "k1"}, BlogPublish, http.StatusOK},
+		{"API key of a jobseeker", map[string]string{"user_id": "u1", "user_role": "jobseeker", "api_key_id": "k1"}, BlogPublish, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		c, w := callerContext(tt.caller)
+		Require(tt.perm)(c)
+		status := http.StatusOK
+		if c.IsAborted() {
+			status = w.Code
+		}
+		if status != tt.status {
+			t.Errorf("%s: %s responded %d, want %d", tt.name, tt.perm, status, tt.status)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	c, _ := callerContext(map[string]string{"user_id": "a1", "user_role": "admin"})
+	if !Authorize(c, BlogUpdate, "u2") {
+		t.Error("admin editing another user's post: denied, want allowed")
+	}
+
+	c, w := callerContext(map[string]string{"user_id": "u1", "user_role": "jobseeker"})
+	if Authorize(c, BlogUpdate, "u1") {
+		t.Error("jobseeker editing a post: allowed, want denied")
+	}
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "You do not have permission") {
+		t.Errorf("jobseeker editing a post: %d %s, want 403", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,107 @@
+package embedding
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Chunk kinds: a job is embedded as its title, its required skills and
+// windows of its description
+const (
+	ChunkTitle       = "title"
+	ChunkSkills      = "skills"
+	ChunkDescription = "description"
+)
+
+// Description windows, in words. all-MiniLM-L6-v2 reads at most 256 word
+// pieces, so windows stay well under that; consecutive windows overlap so a
+// sentence cut at one boundary is whole in the next window.
+const (
+	ChunkWords           = 150
+	ChunkOverlap         = 30
+	MaxDescriptionChunks = 8 // Longer descriptions are truncated
+)
+
+// Chunk is a piece of a job to embed
+type Chunk struct {
+	Kind string
+	Text string
+}
+
+// JobChunks splits a job into the texts to embed. Skill and description chunks
+// are prefixed with the title so each stands on its own: "Backend Engineer.
+// Skills: Go, Kafka" and "Backend Engineer. Skills: PHP" are far apart.
+func JobChunks(title string, skills []string, description string) []Chunk {
+	chunks := []Chunk{{Kind: ChunkTitle, Text: title}}
+
+	var named []string
+	for _, skill := range skills {
+		if skill = strings.TrimSpace(skill); skill != "" {
+			named = append(named, skill)
+		}
+	}
+	if len(named) > 0 {
+		chunks = append(chunks, Chunk{Kind: ChunkSkills, Text: title + ". Skills: " + strings.Join(named, ", ")})
+	}
+
+	for _, window := range Windows(StripMarkdown(description), MaxDescriptionChunks) {
+		chunks = append(chunks, Chunk{Kind: ChunkDescription, Text: title + ". " + window})
+	}
+	return chunks
+}
+
+// Windows splits text into at most max overlapping windows of ChunkWords
+// words; the rest of a longer text is dropped
+func Windows(text string, max int) []string {
+	var windows []string
+	words := strings.Fields(text)
+	for start := 0; start < len(words) && len(windows) < max; start += ChunkWords - ChunkOverlap {
+		end := start + ChunkWords
+		if end > len(words) {
+			end = len(words)
+		}
+		windows = append(windows, strings.Join(words[start:end], " "))
+		if end == len(words) {
+			break
+		}
+	}
+	return windows
+}
+
+// emphasis is _emphasis_ or __strong__, but not the underscores of snake_case
+var emphasis = regexp.MustCompile(`(^|\W)_{1,2}([^_\n]+)_{1,2}(\W|$)`)
+
+var markdownRules = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile("(?m)^\\s*(```|~~~).*$"), ""},                     // Code fences (the code is kept)
+	{regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`), "$1"},                  // Images: alt text
+	{regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`), "$1"},                   // Links: link text
+	{regexp.MustCompile(`<[^>]+>`), " "},                                  // HTML tags
+	{regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s*`), ""},                     // Headings
+	{regexp.MustCompile(`(?m)^\s*>\s?`), ""},                              // Blockquotes
+	{regexp.MustCompile(`(?m)^\s*([-*+]|\d+[.)])\s+`), ""},                // List markers
+	{regexp.MustCompile(`(?m)^\s*([-*_]\s*){3,}$`), ""},                   // Horizontal rules
+	{regexp.MustCompile(`(?m)^\s*\|?(\s*:?-+:?\s*\|)+\s*:?-*:?\s*$`), ""}, // Table separators
+	{regexp.MustCompile(`\|`), " "},                                       // Table cells
+	{emphasis, "$1$2$3"},                                                  // _Emphasis_, until none is left (see StripMarkdown)
+	{regexp.MustCompile("(\\*|~~|`)"), ""},                                // *Emphasis*, ~~strike~~, `code`
+}
+
+// StripMarkdown reduces a Markdown description to its text
+func StripMarkdown(s string) string {
+	for _, rule := range markdownRules {
+		s = rule.pattern.ReplaceAllString(s, rule.replacement)
+		// Adjacent emphasis shares the character between, which only one match
+		// can take: "_a_ _b_" only matches "_a_ " the first time
+		for rule.pattern == emphasis {
+			next := emphasis.ReplaceAllString(s, rule.replacement)
+			if next == s {
+				break
+			}
+			s = next
+		}
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package embedding
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// words returns "w0 w1 ... w(n-1)"
+func words(n int) string {
+	w := make([]string, n)
+	for i := range w {
+		w[i] = fmt.Sprintf("w%d", i)
+	}
+	return strings.Join(w, " ")
+}
+
+func TestWindows(t *testing.T) {
+	step := ChunkWords - ChunkOverlap
+	tests := []struct {
+		name  string
+		words int
+		max   int
+		want  [][2]int // First and last word of each window
+	}{
+		{"empty", 0, 8, nil},
+		{"short", 10, 8, [][2]int{{0, 9}}},
+		{"exactly one window", ChunkWords, 8, [][2]int{{0, ChunkWords - 1}}},
+		{"one word over", ChunkWords + 1, 8, [][2]int{{0, ChunkWords - 1}, {step, ChunkWords}}},
+		{"three windows", 2*step + ChunkWords, 8, [][2]int{
+			{0, ChunkWords - 1}, {step, step + ChunkWords - 1}, {2 * step, 2*step + ChunkWords - 1}}},
+		{"truncated", 2000, 2, [][2]int{{0, ChunkWords - 1}, {step, step + ChunkWords - 1}}},
+	}
+	for _, tt := range tests {
+		windows := Windows(words(tt.words), tt.max)
+		if len(windows) != len(tt.want) {
+			t.Errorf("%s: got %d windows, want %d", tt.name, len(windows), len(tt.want))
+			continue
+		}
+		for i, window := range windows {
+			fields := strings.Fields(window)
+			first, last := fmt.Sprintf("w%d", tt.want[i][0]), fmt.Sprintf("w%d", tt.want[i][1])
+			if fields[0] != first || fields[len(fields)-1] != last {
+				t.Errorf("%s: window %d is %s..%s, want %s..%s", tt.name, i, fields[0], fields[len(fields)-1], first, last)
+			}
+		}
+	}
+
+	// Consecutive windows share ChunkOverlap words
+	windows := Windows(words(400), MaxDescriptionChunks)
+	for i := 1; i < len(windows); i++ {
+		prev, next := strings.Fields(windows[i-1]), strings.Fields(windows[i])
+		if strings.Join(prev[len(prev)-ChunkOverlap:], " ") != strings.Join(next[:ChunkOverlap], " ") {
+			t.Errorf("windows %d and %d don't overlap by %d words", i-1, i, ChunkOverlap)
+		}
+	}
+}
+
+func TestStripMarkdown(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"## About the role", "About the role"},
+		{"- Build APIs\n* Review code\n1. Ship", "Build APIs\nReview code\nShip"},
+		{"See [our blog](https://example.com) ![logo](logo.png)", "See our blog logo"},
+		{"**Bold**, *italic*, ~~old~~ and `code`", "Bold, italic, old and code"},
+		{"_Remote_ or __hybrid__", "Remote or hybrid"},
+		{"_a_ _b_ _c_", "a b c"},
+		{"__a__ _b_ _c_ _d_ *e* __f__", "a b c d e f"},
+		{"_a_,_b_,_c_,_d_", "a,b,c,d"},
+		{"Tune max_retry_count and job_service", "Tune max_retry_count and job_service"},
+		{"Use `snake_case_names`", "Use snake_case_names"},
+		{"> Quoted", "Quoted"},
+		{"```go\nfmt.Println()\n```", "\nfmt.Println()\n"},
+		{"<p>Hello<br>world</p>", " Hello world "},
+		{"| Stack | Go |\n|---|---|\n| DB | Postgres |", "  Stack   Go  \n\n  DB   Postgres  "},
+		{"Before\n***\nAfter", "Before\n\nAfter"},
+	}
+	for _, tt := range tests {
+		if got := StripMarkdown(tt.in); got != tt.want {
+			t.Errorf("StripMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestJobChunks(t *testing.T) {
+	chunks := JobChunks("Backend Engineer", []string{" Go ", "", "Kafka"}, "## Role\nBuild **APIs**")
+	want := []Chunk{
+		{ChunkTitle, "Backend Engineer"},
+		{ChunkSkills, "Backend Engineer. Skills: Go, Kafka"},
+		{ChunkDescription, "Backend Engineer. Role Build APIs"},
+	}
+	if fmt.Sprint(chunks) != fmt.Sprint(want) {
+		t.Errorf("JobChunks:\n got %q\nwant %q", chunks, want)
+	}
+
+	// Without skills or a description only the title is embedded
+	if chunks := JobChunks("Designer", []string{" "}, ""); len(chunks) != 1 || chunks[0].Kind != ChunkTitle {
+		t.Errorf("JobChunks without skills or description: got %q, want the title only", chunks)
+	}
+
+	// Long descriptions are cut at MaxDescriptionChunks windows
+	chunks = JobChunks("SRE", nil, words(5000))
+	if len(chunks) != 1+MaxDescriptionChunks {
+		t.Errorf("long description: got %d chunks, want %d", len(chunks), 1+MaxDescriptionChunks)
+	}
+	for _, chunk := range chunks[1:] {
+		if chunk.Kind != ChunkDescription || !strings.HasPrefix(chunk.Text, "SRE. w") {
+			t.Errorf("description chunk %q, want it prefixed with the title", chunk.Text)
+			break
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package embedding
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// EmbedJob embeds the chunks of a job (see JobChunks) and replaces its stored
+// embeddings: one job_embeddings row per chunk, jobs.embedding as their
+// normalized mean and jobs.title_embedding as the title's embedding
+func (s *EmbeddingService) EmbedJob(db *sql.DB, jobID string) error {
+	chunks, err := jobChunks(db, jobID, "")
+	if err != nil {
+		return err
+	}
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+	vectors, err := s.GetBatchEmbeddings(texts)
+	if err != nil {
+		return fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+	if len(vectors) != len(chunks) {
+		return fmt.Errorf("embedding server returned %d embeddings for %d chunks", len(vectors), len(chunks))
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Regenerations of the same job run one at a time. If the job changed while
+	// this one was embedding, the regeneration for that change stores its own
+	// embeddings, so these stale ones are dropped.
+	current, err := jobChunks(tx, jobID, "FOR UPDATE")
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(current, chunks) {
+		return nil
+	}
+
+	if _, err := tx.Exec("DELETE FROM job_embeddings WHERE job_id = $1", jobID); err != nil {
+		return fmt.Errorf("failed to clear embeddings: %w", err)
+	}
+	for i, chunk := range chunks {
+		_, err := tx.Exec(`
+			INSERT INTO job_embeddings (job_id, chunk_index, kind, content, embedding)
+			VALUES ($1, $2, $3, $4, $5::vector)
+		`, jobID, i, chunk.Kind, chunk.Text, VectorLiteral(vectors[i]))
+		if err != nil {
+			return fmt.Errorf("failed to save chunk %d: %w", i, err)
+		}
+	}
+	_, err = tx.Exec("UPDATE jobs SET embedding = $1::vector, title_embedding = $2::vector WHERE id = $3",
+		VectorLiteral(Mean(vectors)), VectorLiteral(vectors[0]), jobID)
+	if err != nil {
+		return fmt.Errorf("failed to save job embedding: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// jobChunks loads a job and splits it into chunks, locking its row as lock says
+func jobChunks(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, jobID, lock string) ([]Chunk, error) {
+	var title, description string
+	var skills []string
+	err := q.QueryRow("SELECT title, COALESCE(description, ''), COALESCE(required_skills, '{}') FROM jobs WHERE id = $1 "+lock, jobID).
+		Scan(&title, &description, pq.Array(&skills))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job: %w", err)
+	}
+	return JobChunks(title, skills, description), nil
+}
+
+// Mean is the normalized mean of vectors, a single vector close (by cosine
+// similarity) to all of them
+func Mean(vectors [][]float32) []float32 {
+	if len(vectors) == 0 {
+		return nil
+	}
+	mean := make([]float32, len(vectors[0]))
+	for _, v := range vectors {
+		for i := range mean {
+			mean[i] += v[i]
+		}
+	}
+
+	var norm float64
+	for _, x := range mean {
+		norm += float64(x) * float64(x)
+	}
+	if norm = math.Sqrt(norm); norm > 0 {
+		for i := range mean {
+			mean[i] = float32(float64(mean[i]) / norm)
+		}
+	}
+	return mean
+}
+
+// ParseVector reads a pgvector value such as "[0.1,-0.2,0.3]"
+func ParseVector(s string) ([]float32, error) {
+	fields := strings.Split(strings.Trim(s, "[]"), ",")
+	vector := make([]float32, len(fields))
+	for i, field := range fields {
+		x, err := strconv.ParseFloat(strings.TrimSpace(field), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector: %w", err)
+		}
+		vector[i] = float32(x)
+	}
+	return vector, nil
+}
+
+// VectorLiteral formats an embedding as "[v1,v2,...]" for pgvector
+func VectorLiteral(embedding []float32) string {
+	return "[" + strings.Trim(strings.Join(strings.Fields(fmt.Sprint(embedding)), ","), "[]") + "]"
+}
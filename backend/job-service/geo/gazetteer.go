@@ -0,0 +1,158 @@
+package geo
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Gazetteer geocodes offline from a GeoNames-style file: tab-separated rows of
+// geonameid, name, asciiname, alternatenames (comma-separated), latitude,
+// longitude, feature class, feature code, country code, cc2, admin1 code, ...,
+// population, ... (see https://download.geonames.org/export/dump/readme.txt).
+// A small file of major cities is bundled; a full dump such as cities15000.txt
+// can be used instead (GAZETTEER_PATH).
+type Gazetteer struct {
+	// Places by normalized name, most populous first
+	names map[string][]gazetteerPlace
+}
+
+type gazetteerPlace struct {
+	Place
+	admin1     string
+	population int64
+}
+
+//go:embed cities.tsv
+var bundledCities []byte
+
+// GeoNames columns read by the gazetteer
+const (
+	colID             = 0
+	colName           = 1
+	colASCIIName      = 2
+	colAlternateNames = 3
+	colLatitude       = 4
+	colLongitude      = 5
+	colCountry        = 8
+	colAdmin1         = 10
+	colPopulation     = 14
+	minColumns        = 15
+)
+
+// BundledGazetteer loads the gazetteer shipped with the service
+func BundledGazetteer() (*Gazetteer, error) {
+	return LoadGazetteer(bytes.NewReader(bundledCities))
+}
+
+// OpenGazetteer loads a gazetteer file
+func OpenGazetteer(path string) (*Gazetteer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadGazetteer(f)
+}
+
+// LoadGazetteer reads GeoNames-style rows
+func LoadGazetteer(r io.Reader) (*Gazetteer, error) {
+	g := &Gazetteer{names: map[string][]gazetteerPlace{}}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // alternatenames can be long
+	line := 0
+	for scanner.Scan() {
+		line++
+		if scanner.Text() == "" || strings.HasPrefix(scanner.Text(), "#") {
+			continue
+		}
+		cols := strings.Split(scanner.Text(), "\t")
+		if len(cols) < minColumns {
+			return nil, fmt.Errorf("line %d: expected at least %d columns, got %d", line, minColumns, len(cols))
+		}
+		lat, err := strconv.ParseFloat(cols[colLatitude], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid latitude: %w", line, err)
+		}
+		lng, err := strconv.ParseFloat(cols[colLongitude], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid longitude: %w", line, err)
+		}
+		population, _ := strconv.ParseInt(cols[colPopulation], 10, 64)
+
+		place := gazetteerPlace{
+			Place: Place{
+				ID:        "geonames:" + cols[colID],
+				Name:      cols[colName],
+				Country:   cols[colCountry],
+				Latitude:  lat,
+				Longitude: lng,
+			},
+			admin1:     cols[colAdmin1],
+			population: population,
+		}
+
+		seen := map[string]bool{}
+		names := append([]string{cols[colName], cols[colASCIIName]}, strings.Split(cols[colAlternateNames], ",")...)
+		for _, name := range names {
+			key := Normalize(name)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			g.add(key, place)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// add indexes a place under a name, keeping the most populous place first
+func (g *Gazetteer) add(key string, place gazetteerPlace) {
+	places := append(g.names[key], place)
+	for i := len(places) - 1; i > 0 && places[i].population > places[i-1].population; i-- {
+		places[i], places[i-1] = places[i-1], places[i]
+	}
+	g.names[key] = places
+}
+
+// Geocode resolves a location such as "Ha Noi", "San Francisco, CA" or
+// "District 1, Ho Chi Minh City". Each comma-separated part is tried as a
+// place name in turn; the others pick between places of the same name by
+// country or state code ("San Jose, CR"), else the most populous one wins.
+func (g *Gazetteer) Geocode(location string) (Place, error) {
+	if places := g.names[Normalize(location)]; len(places) > 0 {
+		return places[0].Place, nil
+	}
+
+	parts := strings.FieldsFunc(location, func(r rune) bool {
+		return strings.ContainsRune(",;/|()", r)
+	})
+	for i, part := range parts {
+		places := g.names[Normalize(part)]
+		if len(places) == 0 {
+			continue
+		}
+		for j, qualifier := range parts {
+			if j == i {
+				continue
+			}
+			code := strings.ToUpper(strings.TrimSpace(qualifier))
+			for _, place := range places {
+				if place.Country == code || place.admin1 == code {
+					return place.Place, nil
+				}
+			}
+		}
+		return places[0].Place, nil
+	}
+	return Place{}, ErrNotFound
+}
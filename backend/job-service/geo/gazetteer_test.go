@@ -0,0 +1,76 @@
+package geo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeocode(t *testing.T) {
+	g, err := BundledGazetteer()
+	if err != nil {
+		t.Fatalf("BundledGazetteer: %v", err)
+	}
+
+	tests := []struct {
+		location string
+		id       string
+		country  string
+	}{
+		{"San Jose", "geonames:5392171", "US"}, // The most populous San Jose
+		{"San Jose, CA", "geonames:5392171", "US"},
+		{"San Jose, CR", "geonames:3621849", "CR"},
+		{"San José (CR)", "geonames:3621849", "CR"},
+		{"Hà Nội", "geonames:1581130", "VN"},
+		{"ha noi", "geonames:1581130", "VN"},
+		{"District 1, Ho Chi Minh City", "geonames:1566083", "VN"},
+		{"TP. HCM", "geonames:1566083", "VN"},
+	}
+	for _, tt := range tests {
+		place, err := g.Geocode(tt.location)
+		if err != nil {
+			t.Errorf("Geocode(%q): %v", tt.location, err)
+			continue
+		}
+		if place.ID != tt.id || place.Country != tt.country {
+			t.Errorf("Geocode(%q) = %s (%s), want %s (%s)", tt.location, place.ID, place.Country, tt.id, tt.country)
+		}
+	}
+
+	if _, err := g.Geocode("Atlantis"); err != ErrNotFound {
+		t.Errorf("Geocode(Atlantis): got %v, want ErrNotFound", err)
+	}
+}
+
+func TestLoadGazetteer(t *testing.T) {
+	row := func(id, name, alternates, lat, country, admin1, population string) string {
+		cols := make([]string, minColumns)
+		cols[colID], cols[colName], cols[colASCIIName], cols[colAlternateNames] = id, name, name, alternates
+		cols[colLatitude], cols[colLongitude] = lat, "0"
+		cols[colCountry], cols[colAdmin1], cols[colPopulation] = country, admin1, population
+		return strings.Join(cols, "\t")
+	}
+	data := strings.Join([]string{
+		"# comment",
+		row("1", "Springfield", "", "39.8", "US", "IL", "114000"),
+		row("2", "Springfield", "Springfield MO", "37.2", "US", "MO", "169000"),
+		"",
+	}, "\n")
+
+	g, err := LoadGazetteer(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("LoadGazetteer: %v", err)
+	}
+	for location, want := range map[string]string{
+		"Springfield":     "geonames:2",
+		"Springfield, IL": "geonames:1",
+		"Springfield MO":  "geonames:2",
+	} {
+		if place, err := g.Geocode(location); err != nil || place.ID != want {
+			t.Errorf("Geocode(%q) = %s, %v, want %s", location, place.ID, err, want)
+		}
+	}
+
+	if _, err := LoadGazetteer(strings.NewReader("1\tToo short\n")); err == nil {
+		t.Error("LoadGazetteer accepted a row with too few columns")
+	}
+}
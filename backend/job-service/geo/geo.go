@@ -0,0 +1,88 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Jobs and companies store the place their location resolves to, so "Hanoi",
+// "Ha Noi" and "Hà Nội" are the same place and searches can ask for jobs within
+// some distance of it. Places come from a Geocoder; the default one is an
+// offline gazetteer (see gazetteer.go).
+
+// ErrNotFound is returned for locations a geocoder has no place for
+var ErrNotFound = errors.New("place not found")
+
+// Place is a named point, identified by its gazetteer ID (e.g. "geonames:1581130")
+type Place struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Country   string  `json:"country"` // ISO 3166-1 alpha-2
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Geocoder resolves free-form locations ("Ha Noi, Vietnam", "San Francisco, CA")
+// to places
+type Geocoder interface {
+	Geocode(location string) (Place, error)
+}
+
+// EarthRadiusKm is the mean radius used for distances
+const EarthRadiusKm = 6371.0
+
+// DistanceKm is the great-circle distance between two points
+func DistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := radians(lat2 - lat1)
+	dLng := radians(lng2 - lng1)
+	a := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+// BoundingBox returns the latitudes and longitudes within which every point
+// radiusKm from (lat, lng) lies, for index-friendly prefiltering
+func BoundingBox(lat, lng, radiusKm float64) (minLat, maxLat, minLng, maxLng float64) {
+	dLat := radiusKm / (EarthRadiusKm * math.Pi / 180)
+	minLat, maxLat = math.Max(lat-dLat, -90), math.Min(lat+dLat, 90)
+	// Near the poles (or across the antimeridian) any longitude may be in range
+	if minLat == -90 || maxLat == 90 {
+		return minLat, maxLat, -180, 180
+	}
+	// The circle is widest north (or south) of its center, so this is more than dLat / cos(lat)
+	dLng := math.Asin(math.Sin(radiusKm/EarthRadiusKm)/math.Cos(radians(lat))) * 180 / math.Pi
+	if lng-dLng < -180 || lng+dLng > 180 {
+		return minLat, maxLat, -180, 180
+	}
+	return minLat, maxLat, lng - dLng, lng + dLng
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// Normalize folds a place name for lookup: lowercase, without diacritics,
+// spaces or punctuation, so "Hà Nội", "Ha Noi" and "HANOI" all read "hanoi"
+func Normalize(name string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn))), name)
+	if err != nil {
+		folded = name
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(folded) {
+		switch {
+		case r == 'đ':
+			b.WriteRune('d')
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
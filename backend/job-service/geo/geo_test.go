@@ -0,0 +1,94 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+	}{
+		{"same point", 21.0245, 105.8412, 21.0245, 105.8412, 0},
+		{"London to Paris", 51.5074, -0.1278, 48.8566, 2.3522, 343.5},
+		{"Hanoi to Ho Chi Minh City", 21.0245, 105.8412, 10.8230, 106.6296, 1137.1},
+		{"across the antimeridian", 0, 179.5, 0, -179.5, 111.2},
+		{"pole to pole", 90, 0, -90, 0, math.Pi * EarthRadiusKm},
+	}
+	for _, tt := range tests {
+		if got := DistanceKm(tt.lat1, tt.lng1, tt.lat2, tt.lng2); math.Abs(got-tt.want) > 0.5 {
+			t.Errorf("%s: got %.1f km, want %.1f", tt.name, got, tt.want)
+		}
+	}
+}
+
+// destination is the point distanceKm from (lat, lng) on the initial bearing
+func destination(lat, lng, distanceKm, bearing float64) (float64, float64) {
+	d := distanceKm / EarthRadiusKm
+	lat1, lng1, b := radians(lat), radians(lng), radians(bearing)
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(b))
+	lng2 := lng1 + math.Atan2(math.Sin(b)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+	return lat2 * 180 / math.Pi, math.Remainder(lng2*180/math.Pi, 360)
+}
+
+func TestBoundingBoxContainsCircle(t *testing.T) {
+	centers := []struct{ lat, lng, radiusKm float64 }{
+		{21.0245, 105.8412, 50},
+		{0, 0, 1000},
+		{-33.8688, 151.2093, 200},
+		{64.1466, -21.9426, 300},
+		{80, 20, 500}, // Far north the circle is much wider than its latitude span suggests
+		{-80, -60, 500},
+	}
+	for _, c := range centers {
+		minLat, maxLat, minLng, maxLng := BoundingBox(c.lat, c.lng, c.radiusKm)
+		for bearing := 0.0; bearing < 360; bearing += 5 {
+			lat, lng := destination(c.lat, c.lng, c.radiusKm*0.999, bearing)
+			if lat < minLat || lat > maxLat || lng < minLng || lng > maxLng {
+				t.Errorf("%v km around (%v, %v): (%.3f, %.3f) at bearing %v is outside [%.3f, %.3f] x [%.3f, %.3f]",
+					c.radiusKm, c.lat, c.lng, lat, lng, bearing, minLat, maxLat, minLng, maxLng)
+				break
+			}
+		}
+	}
+}
+
+func TestBoundingBoxEdges(t *testing.T) {
+	tests := []struct {
+		name                           string
+		lat, lng, radiusKm             float64
+		minLat, maxLat, minLng, maxLng float64
+	}{
+		// The circle covers the pole, so every longitude is in range
+		{"north pole", 89.5, 10, 100, 88.6, 90, -180, 180},
+		{"south pole", -89.5, 10, 100, -90, -88.6, -180, 180},
+		// The box would wrap around, so it spans every longitude instead
+		{"antimeridian east", 10, 179.5, 100, 9.1, 10.9, -180, 180},
+		{"antimeridian west", 10, -179.5, 100, 9.1, 10.9, -180, 180},
+		{"equator", 0, 0, 111.19, -1, 1, -1, 1},
+	}
+	for _, tt := range tests {
+		minLat, maxLat, minLng, maxLng := BoundingBox(tt.lat, tt.lng, tt.radiusKm)
+		got := []float64{minLat, maxLat, minLng, maxLng}
+		want := []float64{tt.minLat, tt.maxLat, tt.minLng, tt.maxLng}
+		for i := range got {
+			if math.Abs(got[i]-want[i]) > 0.01 {
+				t.Errorf("%s: got %.2f, want %.2f", tt.name, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for _, name := range []string{"Hà Nội", "Ha Noi", "HANOI", "ha-noi"} {
+		if got := Normalize(name); got != "hanoi" {
+			t.Errorf("Normalize(%q) = %q, want hanoi", name, got)
+		}
+	}
+	if got := Normalize("Đà Nẵng"); got != "danang" {
+		t.Errorf("Normalize(Đà Nẵng) = %q, want danang", got)
+	}
+}
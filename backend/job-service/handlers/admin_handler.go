@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/job-portal/job-service/config"
+	"github.com/job-portal/job-service/policy"
 )
 
 // AdminRecruiter represents a recruiter user for admin views
@@ -180,8 +181,15 @@ func AssignCompanyToRecruiter(c *gin.Context) {
 		return
 	}
 
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
 	// Update the company's recruiter_id
-	_, err = config.DB.Exec(
+	_, err = tx.Exec(
 		"UPDATE companies SET recruiter_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2",
 		req.RecruiterID, companyID,
 	)
@@ -191,6 +199,35 @@ func AssignCompanyToRecruiter(c *gin.Context) {
 		return
 	}
 
+	// The assigned recruiter owns the company's team; other members keep their roles
+	_, err = tx.Exec(`
+		INSERT INTO company_members (company_id, user_id, role, added_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (company_id, user_id) DO UPDATE SET role = EXCLUDED.role, updated_at = CURRENT_TIMESTAMP
+	`, companyID, req.RecruiterID, policy.RoleOwner, c.GetString("user_id"))
+	if err != nil {
+		log.Printf("AssignCompanyToRecruiter: membership error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign company"})
+		return
+	}
+
+	// Jobs left behind by a deleted recruiter go to the new one
+	_, err = tx.Exec(
+		"UPDATE jobs SET recruiter_id = $1, updated_at = CURRENT_TIMESTAMP WHERE company_id = $2 AND recruiter_id IS NULL",
+		req.RecruiterID, companyID,
+	)
+	if err != nil {
+		log.Printf("AssignCompanyToRecruiter: job update error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign company"})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("AssignCompanyToRecruiter: commit error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign company"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message":        "Company assigned successfully",
 		"company_name":   companyName,
@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/job-portal/job-service/config"
 	"github.com/job-portal/job-service/models"
+	"github.com/job-portal/job-service/policy"
 )
 
 // ApplyToJob creates a new job application
@@ -110,7 +111,6 @@ func GetMyApplications(c *gin.Context) {
 // UpdateApplicationStatus updates application status (recruiters only)
 func UpdateApplicationStatus(c *gin.Context) {
 	applicationID := c.Param("id")
-	recruiterID := c.GetString("user_id")
 
 	var req models.UpdateApplicationStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -118,15 +118,16 @@ func UpdateApplicationStatus(c *gin.Context) {
 		return
 	}
 
-	// Verify recruiter owns the job
-	var jobRecruiterID string
+	// Verify recruiter is a member of the job's company
+	var role string
 	query := `
-		SELECT j.recruiter_id
+		SELECT COALESCE(m.role, '')
 		FROM applications a
 		JOIN jobs j ON a.job_id = j.id
+		LEFT JOIN company_members m ON m.company_id = j.company_id AND m.user_id = $2
 		WHERE a.id = $1
 	`
-	err := config.DB.QueryRow(query, applicationID).Scan(&jobRecruiterID)
+	err := config.DB.QueryRow(query, applicationID, c.GetString("user_id")).Scan(&role)
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
 		return
@@ -135,8 +136,7 @@ func UpdateApplicationStatus(c *gin.Context) {
 		return
 	}
 
-	if jobRecruiterID != recruiterID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update applications for your own jobs"})
+	if !policy.Authorize(c, policy.ApplicationStatusUpdate, role) {
 		return
 	}
 
@@ -146,7 +146,7 @@ func UpdateApplicationStatus(c *gin.Context) {
 		UPDATE applications
 		SET status = $1, updated_at = CURRENT_TIMESTAMP
 		WHERE id = $2
-		RETURNING id, job_id, applicant_id, email, resume_url, cover_letter, status, subscribed, applied_at, updated_at
+		RETURNING id, job_id, COALESCE(applicant_id::text, ''), email, resume_url, cover_letter, status, subscribed, applied_at, updated_at
 	`
 	err = config.DB.QueryRow(updateQuery, req.Status, applicationID).
 		Scan(&application.ID, &application.JobID, &application.ApplicantID, &application.Email,
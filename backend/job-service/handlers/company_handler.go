@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/job-portal/job-service/config"
 	"github.com/job-portal/job-service/models"
+	"github.com/job-portal/job-service/policy"
 )
 
 // CreateCompany creates a new company (recruiters only)
@@ -20,17 +21,36 @@ func CreateCompany(c *gin.Context) {
 		return
 	}
 
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	placeID, latitude, longitude := placeColumns(req.Headquarters)
+
 	var company models.Company
 	query := `
-		INSERT INTO companies (name, description, website, logo_url, recruiter_id, industry, company_size, founded_year, headquarters)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, name, description, website, logo_url, recruiter_id, industry, company_size, founded_year, headquarters, rating, created_at, updated_at
+		INSERT INTO companies (name, description, website, logo_url, recruiter_id, industry, company_size, founded_year, headquarters,
+		                       place_id, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, name, description, website, logo_url, recruiter_id, industry, company_size, founded_year, headquarters,
+		          COALESCE(place_id, ''), latitude, longitude, rating, created_at, updated_at
 	`
-	err := config.DB.QueryRow(query, req.Name, req.Description, req.Website, req.LogoURL, recruiterID,
-		req.Industry, req.CompanySize, req.FoundedYear, req.Headquarters).
+	err = tx.QueryRow(query, req.Name, req.Description, req.Website, req.LogoURL, recruiterID,
+		req.Industry, req.CompanySize, req.FoundedYear, req.Headquarters, placeID, latitude, longitude).
 		Scan(&company.ID, &company.Name, &company.Description, &company.Website, &company.LogoURL,
 			&company.RecruiterID, &company.Industry, &company.CompanySize, &company.FoundedYear, &company.Headquarters,
-			&company.Rating, &company.CreatedAt, &company.UpdatedAt)
+			&company.PlaceID, &company.Latitude, &company.Longitude, &company.Rating, &company.CreatedAt, &company.UpdatedAt)
+	if err == nil {
+		// The creator owns the company's team
+		_, err = tx.Exec("INSERT INTO company_members (company_id, user_id, role) VALUES ($1, $2, $3)",
+			company.ID, recruiterID, policy.RoleOwner)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
 
 	if err != nil {
 		log.Printf("Failed to create company: %v", err)
@@ -38,17 +58,16 @@ func CreateCompany(c *gin.Context) {
 		return
 	}
 
+	company.MemberRole = policy.RoleOwner
 	c.JSON(http.StatusCreated, company)
 }
 
 // UpdateCompany updates company information
 func UpdateCompany(c *gin.Context) {
 	companyID := c.Param("id")
-	recruiterID := c.GetString("user_id")
 
-	// Check ownership
-	var ownerID string
-	err := config.DB.QueryRow("SELECT recruiter_id FROM companies WHERE id = $1", companyID).Scan(&ownerID)
+	// Check membership
+	role, err := companyRole(companyID, c.GetString("user_id"))
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
 		return
@@ -57,8 +76,7 @@ func UpdateCompany(c *gin.Context) {
 		return
 	}
 
-	if ownerID != recruiterID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own companies"})
+	if !policy.Authorize(c, policy.CompanyUpdate, role) {
 		return
 	}
 
@@ -78,18 +96,24 @@ func UpdateCompany(c *gin.Context) {
 		    company_size = COALESCE(NULLIF($6, ''), company_size),
 		    founded_year = COALESCE(NULLIF($7, 0), founded_year),
 		    headquarters = COALESCE(NULLIF($8, ''), headquarters),
+		    place_id = CASE WHEN $8 = '' THEN place_id ELSE $10 END,
+		    latitude = CASE WHEN $8 = '' THEN latitude ELSE $11::float8 END,
+		    longitude = CASE WHEN $8 = '' THEN longitude ELSE $12::float8 END,
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE id = $9
-		RETURNING id, name, description, website, logo_url, recruiter_id, industry, company_size, founded_year, headquarters, rating, created_at, updated_at
+		RETURNING id, name, description, website, logo_url, COALESCE(recruiter_id::text, ''), industry, company_size, founded_year, headquarters,
+		          COALESCE(place_id, ''), latitude, longitude, rating, created_at, updated_at
 	`
 
+	placeID, latitude, longitude := placeColumns(req.Headquarters)
+
 	var company models.Company
 	err = config.DB.QueryRow(query, req.Name, req.Description, req.Website, req.LogoURL,
 		req.Industry, req.CompanySize, req.FoundedYear, req.Headquarters,
-		companyID).
+		companyID, placeID, latitude, longitude).
 		Scan(&company.ID, &company.Name, &company.Description, &company.Website, &company.LogoURL,
 			&company.RecruiterID, &company.Industry, &company.CompanySize, &company.FoundedYear, &company.Headquarters,
-			&company.Rating, &company.CreatedAt, &company.UpdatedAt)
+			&company.PlaceID, &company.Latitude, &company.Longitude, &company.Rating, &company.CreatedAt, &company.UpdatedAt)
 
 	if err != nil {
 		log.Printf("Failed to update company: %v", err)
@@ -103,11 +127,9 @@ func UpdateCompany(c *gin.Context) {
 // DeleteCompany deletes a company
 func DeleteCompany(c *gin.Context) {
 	companyID := c.Param("id")
-	recruiterID := c.GetString("user_id")
 
-	// Check ownership
-	var ownerID string
-	err := config.DB.QueryRow("SELECT recruiter_id FROM companies WHERE id = $1", companyID).Scan(&ownerID)
+	// Check membership
+	role, err := companyRole(companyID, c.GetString("user_id"))
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
 		return
@@ -116,8 +138,7 @@ func DeleteCompany(c *gin.Context) {
 		return
 	}
 
-	if ownerID != recruiterID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own companies"})
+	if !policy.Authorize(c, policy.CompanyDelete, role) {
 		return
 	}
 
@@ -143,14 +164,14 @@ func GetCompany(c *gin.Context) {
 	var company models.Company
 	query := `
 		SELECT id, name, COALESCE(description, ''), COALESCE(website, ''), COALESCE(logo_url, ''), 
-		       recruiter_id, COALESCE(industry, ''), COALESCE(company_size, ''), COALESCE(founded_year, 0), 
-		       COALESCE(headquarters, ''), COALESCE(rating, 0), created_at, updated_at
+		       COALESCE(recruiter_id::text, ''), COALESCE(industry, ''), COALESCE(company_size, ''), COALESCE(founded_year, 0), 
+		       COALESCE(headquarters, ''), COALESCE(place_id, ''), latitude, longitude, COALESCE(rating, 0), created_at, updated_at
 		FROM companies WHERE id = $1
 	`
 	err := config.DB.QueryRow(query, companyID).
 		Scan(&company.ID, &company.Name, &company.Description, &company.Website, &company.LogoURL,
 			&company.RecruiterID, &company.Industry, &company.CompanySize, &company.FoundedYear, &company.Headquarters,
-			&company.Rating, &company.CreatedAt, &company.UpdatedAt)
+			&company.PlaceID, &company.Latitude, &company.Longitude, &company.Rating, &company.CreatedAt, &company.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
@@ -163,7 +184,7 @@ func GetCompany(c *gin.Context) {
 	c.JSON(http.StatusOK, company)
 }
 
-// GetCompanies lists all companies for the authenticated recruiter
+// GetCompanies lists the companies the authenticated recruiter is a member of, with their role in each
 func GetCompanies(c *gin.Context) {
 	recruiterID := c.GetString("user_id")
 
@@ -178,12 +199,13 @@ func GetCompanies(c *gin.Context) {
 	}
 
 	query := `
-		SELECT id, name, COALESCE(description, ''), COALESCE(website, ''), COALESCE(logo_url, ''), 
-		       recruiter_id, COALESCE(industry, ''), COALESCE(company_size, ''), COALESCE(founded_year, 0), 
-		       COALESCE(headquarters, ''), COALESCE(rating, 0), created_at, updated_at
-		FROM companies
-		WHERE recruiter_id = $1
-		ORDER BY created_at DESC
+		SELECT c.id, c.name, COALESCE(c.description, ''), COALESCE(c.website, ''), COALESCE(c.logo_url, ''),
+		       COALESCE(c.recruiter_id::text, ''), COALESCE(c.industry, ''), COALESCE(c.company_size, ''), COALESCE(c.founded_year, 0),
+		       COALESCE(c.headquarters, ''), COALESCE(c.rating, 0), c.created_at, c.updated_at, m.role
+		FROM companies c
+		JOIN company_members m ON m.company_id = c.id
+		WHERE m.user_id = $1
+		ORDER BY c.created_at DESC
 	`
 	rows, err := config.DB.Query(query, recruiterID)
 	if err != nil {
@@ -198,7 +220,7 @@ func GetCompanies(c *gin.Context) {
 		var company models.Company
 		if err := rows.Scan(&company.ID, &company.Name, &company.Description, &company.Website, &company.LogoURL,
 			&company.RecruiterID, &company.Industry, &company.CompanySize, &company.FoundedYear, &company.Headquarters,
-			&company.Rating, &company.CreatedAt, &company.UpdatedAt); err != nil {
+			&company.Rating, &company.CreatedAt, &company.UpdatedAt, &company.MemberRole); err != nil {
 			log.Printf("GetCompanies: scan error: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to scan company"})
 			return
@@ -214,7 +236,7 @@ func GetCompanies(c *gin.Context) {
 func GetAllCompanies(c *gin.Context) {
 	query := `
 		SELECT id, name, COALESCE(description, ''), COALESCE(website, ''), COALESCE(logo_url, ''), 
-		       recruiter_id, COALESCE(industry, ''), COALESCE(company_size, ''), COALESCE(founded_year, 0), 
+		       COALESCE(recruiter_id::text, ''), COALESCE(industry, ''), COALESCE(company_size, ''), COALESCE(founded_year, 0), 
 		       COALESCE(headquarters, ''), COALESCE(rating, 0), created_at, updated_at
 		FROM companies
 		ORDER BY rating DESC NULLS LAST, created_at DESC
@@ -0,0 +1,524 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/job-service/config"
+	"github.com/job-portal/job-service/models"
+	"github.com/job-portal/job-service/policy"
+)
+
+// A company is run by a team. Members are added by invitation: an owner or
+// admin invites an email address, and the recruiter signed in with that address
+// accepts it from their pending invitations.
+
+const companyInvitationTTL = 7 * 24 * time.Hour
+
+// pendingInvitation matches invitations that can still be accepted
+const pendingInvitation = `accepted_at IS NULL AND declined_at IS NULL AND revoked_at IS NULL
+	AND expires_at > CURRENT_TIMESTAMP`
+
+// companyRole returns userID's role in the company, or "" if they aren't a
+// member. sql.ErrNoRows means the company doesn't exist.
+func companyRole(companyID, userID string) (string, error) {
+	var role string
+	err := config.DB.QueryRow(`
+		SELECT COALESCE(m.role, '')
+		FROM companies c
+		LEFT JOIN company_members m ON m.company_id = c.id AND m.user_id = $2
+		WHERE c.id = $1
+	`, companyID, userID).Scan(&role)
+	return role, err
+}
+
+// jobCompanyRole returns userID's role in the company that posted the job, or
+// "" if they aren't a member. sql.ErrNoRows means the job doesn't exist.
+func jobCompanyRole(jobID, userID string) (string, error) {
+	var role string
+	err := config.DB.QueryRow(`
+		SELECT COALESCE(m.role, '')
+		FROM jobs j
+		LEFT JOIN company_members m ON m.company_id = j.company_id AND m.user_id = $2
+		WHERE j.id = $1
+	`, jobID, userID).Scan(&role)
+	return role, err
+}
+
+// authorizeCompany loads the caller's role in the company from the :id param
+// and checks perm against it, responding and returning false on failure
+func authorizeCompany(c *gin.Context, perm policy.Permission) (string, bool) {
+	role, err := companyRole(c.Param("id"), c.GetString("user_id"))
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
+		return "", false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return "", false
+	}
+	return role, policy.Authorize(c, perm, role)
+}
+
+// lockedOwnerCount locks the company's team for the rest of the transaction and
+// counts its owners, so concurrent changes can't remove the last one
+func lockedOwnerCount(tx *sql.Tx, companyID string) (int, error) {
+	if _, err := tx.Exec("SELECT id FROM companies WHERE id = $1 FOR UPDATE", companyID); err != nil {
+		return 0, err
+	}
+	var owners int
+	err := tx.QueryRow("SELECT COUNT(*) FROM company_members WHERE company_id = $1 AND role = $2",
+		companyID, policy.RoleOwner).Scan(&owners)
+	return owners, err
+}
+
+// lockedMemberRole reads userID's role in the company, or "" if they aren't a
+// member, locking it for the rest of the transaction. Call it after
+// lockedOwnerCount, so that the role is current.
+func lockedMemberRole(tx *sql.Tx, companyID, userID string) (string, error) {
+	var role string
+	err := tx.QueryRow("SELECT role FROM company_members WHERE company_id = $1 AND user_id = $2 FOR UPDATE",
+		companyID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+// GetCompanyMembers lists a company's team
+func GetCompanyMembers(c *gin.Context) {
+	if _, ok := authorizeCompany(c, policy.CompanyMembersRead); !ok {
+		return
+	}
+
+	rows, err := config.DB.Query(`
+		SELECT m.user_id, u.name, u.email, m.role, COALESCE(m.added_by::text, ''), m.created_at, m.updated_at
+		FROM company_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.company_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 WHEN 'recruiter' THEN 2 ELSE 3 END, u.name
+	`, c.Param("id"))
+	if err != nil {
+		log.Printf("GetCompanyMembers: database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	members := []models.CompanyMember{}
+	for rows.Next() {
+		var m models.CompanyMember
+		if err := rows.Scan(&m.UserID, &m.Name, &m.Email, &m.Role, &m.AddedBy, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			log.Printf("GetCompanyMembers: scan error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		members = append(members, m)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// UpdateCompanyMember changes a member's role
+func UpdateCompanyMember(c *gin.Context) {
+	var req models.UpdateCompanyMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorRole, ok := authorizeCompany(c, policy.CompanyMembersManage)
+	if !ok {
+		return
+	}
+	companyID, userID := c.Param("id"), c.Param("userId")
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	owners, err := lockedOwnerCount(tx, companyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// The caller may have been demoted or removed since authorizeCompany
+	actorRole, err = lockedMemberRole(tx, companyID, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !policy.Authorize(c, policy.CompanyMembersManage, actorRole) {
+		return
+	}
+
+	currentRole, err := lockedMemberRole(tx, companyID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if currentRole == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	if !policy.CanManageMember(actorRole, currentRole) || !policy.CanManageMember(actorRole, req.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role in this company does not allow this action"})
+		return
+	}
+	if currentRole == policy.RoleOwner && req.Role != policy.RoleOwner && owners == 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A company must keep at least one owner"})
+		return
+	}
+
+	_, err = tx.Exec(`
+		UPDATE company_members SET role = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE company_id = $2 AND user_id = $3
+	`, req.Role, companyID, userID)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("UpdateCompanyMember: update error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+		return
+	}
+
+	log.Printf("Company %s: member %s changed from %s to %s by %s", companyID, userID, currentRole, req.Role, c.GetString("user_id"))
+	c.JSON(http.StatusOK, gin.H{"message": "Member updated successfully", "role": req.Role})
+}
+
+// RemoveCompanyMember removes a member from the team. Any member may remove
+// themselves; removing someone else takes a role that manages theirs.
+func RemoveCompanyMember(c *gin.Context) {
+	companyID, userID := c.Param("id"), c.Param("userId")
+	callerID := c.GetString("user_id")
+
+	actorRole, err := companyRole(companyID, callerID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	leaving := userID == callerID && actorRole != ""
+	if !leaving && !policy.Authorize(c, policy.CompanyMembersManage, actorRole) {
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	owners, err := lockedOwnerCount(tx, companyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// The caller may have been demoted or removed since the check above
+	actorRole, err = lockedMemberRole(tx, companyID, callerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	leaving = userID == callerID && actorRole != ""
+	if !leaving && !policy.Authorize(c, policy.CompanyMembersManage, actorRole) {
+		return
+	}
+
+	role, err := lockedMemberRole(tx, companyID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if role == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Member not found"})
+		return
+	}
+
+	if !leaving && !policy.CanManageMember(actorRole, role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role in this company does not allow this action"})
+		return
+	}
+	if role == policy.RoleOwner && owners == 1 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A company must keep at least one owner"})
+		return
+	}
+
+	_, err = tx.Exec("DELETE FROM company_members WHERE company_id = $1 AND user_id = $2", companyID, userID)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("RemoveCompanyMember: delete error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+		return
+	}
+
+	log.Printf("Company %s: member %s removed by %s", companyID, userID, callerID)
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+// GetCompanyInvitations lists a company's pending invitations
+func GetCompanyInvitations(c *gin.Context) {
+	if _, ok := authorizeCompany(c, policy.CompanyMembersManage); !ok {
+		return
+	}
+
+	rows, err := config.DB.Query(`
+		SELECT i.id, i.company_id, i.email, i.role, COALESCE(i.invited_by::text, ''), COALESCE(u.name, ''),
+		       i.expires_at, i.created_at
+		FROM company_invitations i
+		LEFT JOIN users u ON u.id = i.invited_by
+		WHERE i.company_id = $1 AND `+pendingInvitation+`
+		ORDER BY i.created_at DESC
+	`, c.Param("id"))
+	if err != nil {
+		log.Printf("GetCompanyInvitations: database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	invitations := []models.CompanyInvitation{}
+	for rows.Next() {
+		var i models.CompanyInvitation
+		if err := rows.Scan(&i.ID, &i.CompanyID, &i.Email, &i.Role, &i.InvitedBy, &i.InvitedByName,
+			&i.ExpiresAt, &i.CreatedAt); err != nil {
+			log.Printf("GetCompanyInvitations: scan error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		invitations = append(invitations, i)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// CreateCompanyInvitation invites an email address to the team, replacing any
+// pending invitation for the same address
+func CreateCompanyInvitation(c *gin.Context) {
+	var req models.InviteCompanyMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actorRole, ok := authorizeCompany(c, policy.CompanyMembersManage)
+	if !ok {
+		return
+	}
+	if !policy.CanManageMember(actorRole, req.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role in this company does not allow this action"})
+		return
+	}
+	companyID := c.Param("id")
+
+	var isMember bool
+	err := config.DB.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM company_members m JOIN users u ON u.id = m.user_id
+			WHERE m.company_id = $1 AND LOWER(u.email) = LOWER($2)
+		)
+	`, companyID, req.Email).Scan(&isMember)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if isMember {
+		c.JSON(http.StatusConflict, gin.H{"error": "This person is already a member of the company"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE company_invitations SET revoked_at = CURRENT_TIMESTAMP
+		WHERE company_id = $1 AND LOWER(email) = LOWER($2) AND `+pendingInvitation,
+		companyID, req.Email)
+	if err != nil {
+		log.Printf("CreateCompanyInvitation: revoke error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	var invitation models.CompanyInvitation
+	err = tx.QueryRow(`
+		INSERT INTO company_invitations (company_id, email, role, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, company_id, email, role, invited_by, expires_at, created_at
+	`, companyID, req.Email, req.Role, c.GetString("user_id"), time.Now().Add(companyInvitationTTL)).
+		Scan(&invitation.ID, &invitation.CompanyID, &invitation.Email, &invitation.Role, &invitation.InvitedBy,
+			&invitation.ExpiresAt, &invitation.CreatedAt)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("CreateCompanyInvitation: insert error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+
+	log.Printf("Company %s: %s invited as %s by %s", companyID, req.Email, req.Role, c.GetString("user_id"))
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// RevokeCompanyInvitation withdraws a pending invitation
+func RevokeCompanyInvitation(c *gin.Context) {
+	actorRole, ok := authorizeCompany(c, policy.CompanyMembersManage)
+	if !ok {
+		return
+	}
+	companyID, invitationID := c.Param("id"), c.Param("invitationId")
+
+	var role string
+	err := config.DB.QueryRow(`
+		SELECT role FROM company_invitations WHERE id = $1 AND company_id = $2 AND `+pendingInvitation,
+		invitationID, companyID).Scan(&role)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !policy.CanManageMember(actorRole, role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role in this company does not allow this action"})
+		return
+	}
+
+	_, err = config.DB.Exec("UPDATE company_invitations SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1", invitationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked"})
+}
+
+// GetMyCompanyInvitations lists pending invitations sent to the caller's email
+func GetMyCompanyInvitations(c *gin.Context) {
+	rows, err := config.DB.Query(`
+		SELECT i.id, i.company_id, i.email, i.role, COALESCE(i.invited_by::text, ''), COALESCE(u.name, ''),
+		       i.expires_at, i.created_at, co.name
+		FROM company_invitations i
+		JOIN companies co ON co.id = i.company_id
+		LEFT JOIN users u ON u.id = i.invited_by
+		WHERE LOWER(i.email) = LOWER($1) AND `+pendingInvitation+`
+		ORDER BY i.created_at DESC
+	`, c.GetString("user_email"))
+	if err != nil {
+		log.Printf("GetMyCompanyInvitations: database error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	invitations := []models.CompanyInvitation{}
+	for rows.Next() {
+		var i models.CompanyInvitation
+		if err := rows.Scan(&i.ID, &i.CompanyID, &i.Email, &i.Role, &i.InvitedBy, &i.InvitedByName,
+			&i.ExpiresAt, &i.CreatedAt, &i.CompanyName); err != nil {
+			log.Printf("GetMyCompanyInvitations: scan error: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		invitations = append(invitations, i)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// AcceptCompanyInvitation joins the caller to the inviting company's team
+func AcceptCompanyInvitation(c *gin.Context) {
+	invitationID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Only the invited address can accept, and only once
+	var companyID, role string
+	var invitedBy sql.NullString
+	err = tx.QueryRow(`
+		SELECT company_id, role, invited_by FROM company_invitations
+		WHERE id = $1 AND LOWER(email) = LOWER($2) AND `+pendingInvitation+`
+		FOR UPDATE
+	`, invitationID, c.GetString("user_email")).Scan(&companyID, &role, &invitedBy)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found or expired"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO company_members (company_id, user_id, role, added_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (company_id, user_id) DO NOTHING
+	`, companyID, userID, role, invitedBy)
+	if err != nil {
+		log.Printf("AcceptCompanyInvitation: insert error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You are already a member of this company"})
+		return
+	}
+
+	_, err = tx.Exec(`
+		UPDATE company_invitations SET accepted_at = CURRENT_TIMESTAMP, accepted_user_id = $1 WHERE id = $2
+	`, userID, invitationID)
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		log.Printf("AcceptCompanyInvitation: update error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+		return
+	}
+
+	log.Printf("Company %s: user %s joined as %s", companyID, userID, role)
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation accepted", "company_id": companyID, "role": role})
+}
+
+// DeclineCompanyInvitation turns down an invitation sent to the caller
+func DeclineCompanyInvitation(c *gin.Context) {
+	result, err := config.DB.Exec(`
+		UPDATE company_invitations SET declined_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND LOWER(email) = LOWER($2) AND `+pendingInvitation,
+		c.Param("id"), c.GetString("user_email"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline invitation"})
+		return
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found or expired"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation declined"})
+}
@@ -2,16 +2,51 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/job-portal/job-service/config"
 	"github.com/job-portal/job-service/models"
+	"github.com/job-portal/job-service/policy"
+	"github.com/job-portal/job-service/salary"
 	"github.com/lib/pq"
 )
 
+// salaryRange resolves the structured salary of a create or update request: the
+// range given explicitly, else one parsed from the salary text. set is false if
+// the request says nothing about pay; a text without an amount ("Negotiable")
+// clears the range.
+func salaryRange(text string, min, max *float64, currency, period string) (r salary.Range, set bool, err error) {
+	if min == nil && max == nil {
+		if text == "" {
+			return salary.Range{}, false, nil
+		}
+		r, _ = salary.Parse(text)
+		return r, true, nil
+	}
+
+	if currency == "" || period == "" {
+		return salary.Range{}, false, errors.New("currency and salary_period are required with salary_min or salary_max")
+	}
+	if min != nil && max != nil && *min > *max {
+		return salary.Range{}, false, errors.New("salary_min must not be greater than salary_max")
+	}
+	return salary.Range{Min: min, Max: max, Currency: strings.ToUpper(currency), Period: period}, true, nil
+}
+
+// hideSalary leaves pay out of a job shown publicly if its recruiter chose to
+func hideSalary(job *models.Job) {
+	if job.SalaryHidden {
+		job.Salary = ""
+		job.SalaryMin, job.SalaryMax = nil, nil
+		job.Currency, job.SalaryPeriod = "", ""
+	}
+}
+
 // CreateJob creates a new job posting with markdown description
 func CreateJob(c *gin.Context) {
 	recruiterID := c.GetString("user_id")
@@ -22,9 +57,8 @@ func CreateJob(c *gin.Context) {
 		return
 	}
 
-	// Verify company ownership
-	var ownerID string
-	err := config.DB.QueryRow("SELECT recruiter_id FROM companies WHERE id = $1", req.CompanyID).Scan(&ownerID)
+	// Verify company membership
+	role, err := companyRole(req.CompanyID, recruiterID)
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Company not found"})
 		return
@@ -33,20 +67,32 @@ func CreateJob(c *gin.Context) {
 		return
 	}
 
-	if ownerID != recruiterID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only post jobs for your own companies"})
+	if !policy.Authorize(c, policy.JobCreate, role) {
+		return
+	}
+
+	pay, _, err := salaryRange(req.Salary, req.SalaryMin, req.SalaryMax, req.Currency, req.SalaryPeriod)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	placeID, latitude, longitude := placeColumns(req.Location)
+
 	var job models.Job
 	query := `
-		INSERT INTO jobs (title, description, salary, location, job_type, work_location, openings, required_skills, company_id, recruiter_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		RETURNING id, title, description, salary, location, job_type, work_location, openings, required_skills, company_id, recruiter_id, status, created_at, updated_at
+		INSERT INTO jobs (title, description, salary, location, job_type, work_location, openings, required_skills, company_id, recruiter_id,
+		                  salary_min, salary_max, currency, salary_period, salary_hidden, place_id, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING id, title, description, salary, salary_min, salary_max, COALESCE(currency, ''), COALESCE(salary_period, ''), salary_hidden,
+		          location, COALESCE(place_id, ''), latitude, longitude, job_type, work_location, openings, required_skills, company_id, COALESCE(recruiter_id::text, ''), status, created_at, updated_at
 	`
 	err = config.DB.QueryRow(query, req.Title, req.Description, req.Salary, req.Location, req.JobType,
-		req.WorkLocation, req.Openings, pq.Array(req.RequiredSkills), req.CompanyID, recruiterID).
-		Scan(&job.ID, &job.Title, &job.Description, &job.Salary, &job.Location, &job.JobType,
+		req.WorkLocation, req.Openings, pq.Array(req.RequiredSkills), req.CompanyID, recruiterID,
+		pay.Min, pay.Max, sql.NullString{String: pay.Currency, Valid: pay.Currency != ""},
+		sql.NullString{String: pay.Period, Valid: pay.Period != ""}, req.SalaryHidden, placeID, latitude, longitude).
+		Scan(&job.ID, &job.Title, &job.Description, &job.Salary, &job.SalaryMin, &job.SalaryMax, &job.Currency,
+			&job.SalaryPeriod, &job.SalaryHidden, &job.Location, &job.PlaceID, &job.Latitude, &job.Longitude, &job.JobType,
 			&job.WorkLocation, &job.Openings, pq.Array(&job.RequiredSkills), &job.CompanyID,
 			&job.RecruiterID, &job.Status, &job.CreatedAt, &job.UpdatedAt)
 
@@ -55,12 +101,12 @@ func CreateJob(c *gin.Context) {
 		return
 	}
 
-	// Generate and save embedding asynchronously
+	// Generate and save embeddings asynchronously
 	go func() {
-		if err := generateJobEmbedding(job.ID, job.Title); err != nil {
-			log.Printf("Failed to generate embedding for job %s: %v", job.ID, err)
+		if err := generateJobEmbeddings(job.ID); err != nil {
+			log.Printf("Failed to generate embeddings for job %s: %v", job.ID, err)
 		} else {
-			log.Printf("✅ Generated embedding for job: %s", job.Title)
+			log.Printf("✅ Generated embeddings for job: %s", job.Title)
 		}
 	}()
 
@@ -70,11 +116,9 @@ func CreateJob(c *gin.Context) {
 // UpdateJob updates job posting
 func UpdateJob(c *gin.Context) {
 	jobID := c.Param("id")
-	recruiterID := c.GetString("user_id")
 
-	// Check ownership
-	var ownerID string
-	err := config.DB.QueryRow("SELECT recruiter_id FROM jobs WHERE id = $1", jobID).Scan(&ownerID)
+	// Check membership of the job's company
+	role, err := jobCompanyRole(jobID, c.GetString("user_id"))
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 		return
@@ -83,8 +127,7 @@ func UpdateJob(c *gin.Context) {
 		return
 	}
 
-	if ownerID != recruiterID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own jobs"})
+	if !policy.Authorize(c, policy.JobUpdate, role) {
 		return
 	}
 
@@ -94,6 +137,12 @@ func UpdateJob(c *gin.Context) {
 		return
 	}
 
+	pay, paySet, err := salaryRange(req.Salary, req.SalaryMin, req.SalaryMax, req.Currency, req.SalaryPeriod)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Build dynamic update query
 	// Note: job_type and work_location are ENUM types, so we use CASE WHEN instead of COALESCE
 	query := `
@@ -107,18 +156,31 @@ func UpdateJob(c *gin.Context) {
 		    openings = CASE WHEN $7 = 0 THEN openings ELSE $7 END,
 		    status = CASE WHEN $8 = '' THEN status ELSE $8::job_status END,
 		    required_skills = $9,
+		    salary_min = CASE WHEN $11::boolean THEN $12::numeric ELSE salary_min END,
+		    salary_max = CASE WHEN $11::boolean THEN $13::numeric ELSE salary_max END,
+		    currency = CASE WHEN $11::boolean THEN $14 ELSE currency END,
+		    salary_period = CASE WHEN $11::boolean THEN $15 ELSE salary_period END,
+		    salary_hidden = COALESCE($16::boolean, salary_hidden),
+		    place_id = CASE WHEN $4 = '' THEN place_id ELSE $17 END,
+		    latitude = CASE WHEN $4 = '' THEN latitude ELSE $18::float8 END,
+		    longitude = CASE WHEN $4 = '' THEN longitude ELSE $19::float8 END,
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE id = $10
-		RETURNING id, title, description, salary, location, job_type, work_location, openings, required_skills, company_id, recruiter_id, status, created_at, updated_at
+		RETURNING id, title, description, salary, salary_min, salary_max, COALESCE(currency, ''), COALESCE(salary_period, ''), salary_hidden,
+		          location, COALESCE(place_id, ''), latitude, longitude, job_type, work_location, openings, required_skills, company_id, COALESCE(recruiter_id::text, ''), status, created_at, updated_at
 	`
 
 	var job models.Job
 	// Always use pq.Array for skills - pass empty array if no skills provided
 	skills := pq.Array(req.RequiredSkills)
+	placeID, latitude, longitude := placeColumns(req.Location)
 
 	err = config.DB.QueryRow(query, req.Title, req.Description, req.Salary, req.Location, req.JobType,
-		req.WorkLocation, req.Openings, req.Status, skills, jobID).
-		Scan(&job.ID, &job.Title, &job.Description, &job.Salary, &job.Location, &job.JobType,
+		req.WorkLocation, req.Openings, req.Status, skills, jobID,
+		paySet, pay.Min, pay.Max, sql.NullString{String: pay.Currency, Valid: pay.Currency != ""},
+		sql.NullString{String: pay.Period, Valid: pay.Period != ""}, req.SalaryHidden, placeID, latitude, longitude).
+		Scan(&job.ID, &job.Title, &job.Description, &job.Salary, &job.SalaryMin, &job.SalaryMax, &job.Currency,
+			&job.SalaryPeriod, &job.SalaryHidden, &job.Location, &job.PlaceID, &job.Latitude, &job.Longitude, &job.JobType,
 			&job.WorkLocation, &job.Openings, pq.Array(&job.RequiredSkills), &job.CompanyID,
 			&job.RecruiterID, &job.Status, &job.CreatedAt, &job.UpdatedAt)
 
@@ -128,13 +190,13 @@ func UpdateJob(c *gin.Context) {
 		return
 	}
 
-	// Re-generate embedding if title changed
-	if req.Title != "" {
+	// Re-generate embeddings if any embedded text changed
+	if req.Title != "" || req.Description != "" || req.RequiredSkills != nil {
 		go func() {
-			if err := generateJobEmbedding(job.ID, job.Title); err != nil {
-				log.Printf("Failed to regenerate embedding for job %s: %v", job.ID, err)
+			if err := generateJobEmbeddings(job.ID); err != nil {
+				log.Printf("Failed to regenerate embeddings for job %s: %v", job.ID, err)
 			} else {
-				log.Printf("✅ Regenerated embedding for job: %s", job.Title)
+				log.Printf("✅ Regenerated embeddings for job: %s", job.Title)
 			}
 		}()
 	}
@@ -145,11 +207,9 @@ func UpdateJob(c *gin.Context) {
 // DeleteJob deletes a job posting
 func DeleteJob(c *gin.Context) {
 	jobID := c.Param("id")
-	recruiterID := c.GetString("user_id")
 
-	// Check ownership
-	var ownerID string
-	err := config.DB.QueryRow("SELECT recruiter_id FROM jobs WHERE id = $1", jobID).Scan(&ownerID)
+	// Check membership of the job's company
+	role, err := jobCompanyRole(jobID, c.GetString("user_id"))
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 		return
@@ -158,8 +218,7 @@ func DeleteJob(c *gin.Context) {
 		return
 	}
 
-	if ownerID != recruiterID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own jobs"})
+	if !policy.Authorize(c, policy.JobDelete, role) {
 		return
 	}
 
@@ -185,15 +244,17 @@ func GetJobByID(c *gin.Context) {
 	var job models.Job
 	// Explicitly defining columns to avoid * and ensure order matches Scan
 	query := `
-		SELECT j.id, j.title, j.description, j.salary, j.location, j.job_type, j.work_location,
-		       j.openings, j.required_skills, j.company_id, j.recruiter_id, j.status, j.created_at, j.updated_at,
+		SELECT j.id, j.title, j.description, j.salary, j.salary_min, j.salary_max, COALESCE(j.currency, ''), COALESCE(j.salary_period, ''), j.salary_hidden,
+		       j.location, COALESCE(j.place_id, ''), j.latitude, j.longitude, j.job_type, j.work_location,
+		       j.openings, j.required_skills, j.company_id, COALESCE(j.recruiter_id::text, ''), j.status, j.created_at, j.updated_at,
 		       c.name as company_name
 		FROM jobs j
 		JOIN companies c ON j.company_id = c.id
 		WHERE j.id = $1`
 
 	err := config.DB.QueryRow(query, jobID).
-		Scan(&job.ID, &job.Title, &job.Description, &job.Salary, &job.Location, &job.JobType,
+		Scan(&job.ID, &job.Title, &job.Description, &job.Salary, &job.SalaryMin, &job.SalaryMax, &job.Currency,
+			&job.SalaryPeriod, &job.SalaryHidden, &job.Location, &job.PlaceID, &job.Latitude, &job.Longitude, &job.JobType,
 			&job.WorkLocation, &job.Openings, pq.Array(&job.RequiredSkills), &job.CompanyID,
 			&job.RecruiterID, &job.Status, &job.CreatedAt, &job.UpdatedAt, &job.CompanyName)
 
@@ -206,16 +267,38 @@ func GetJobByID(c *gin.Context) {
 		return
 	}
 
+	hideSalary(&job)
 	c.JSON(http.StatusOK, job)
 }
 
-// SearchJobs searches jobs with filters (public endpoint)
+// keywordRank scores a job's match for the websearch query in $1. Full-text rank
+// is boosted by up to 50% for new jobs; the boost halves every 14 days.
+const keywordRank = `ts_rank_cd(j.search_vector, websearch_to_tsquery('english', $1), 32) *
+	(1 + 0.5 * power(0.5, EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - j.created_at)) / 86400 / 14))`
+
+// snippetOptions mark matches in ts_headline excerpts of the description
+const snippetOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=25, MinWords=10, FragmentDelimiter=\" … \""
+
+// snippetSource is the description HTML-escaped, so the only markup in a
+// snippet is the <mark> tags ts_headline adds. Recruiters write descriptions
+// in Markdown, which may contain raw HTML. The parser reads each escape as a
+// single entity token, so excerpts never cut one in half.
+const snippetSource = `replace(replace(replace(r.description, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+
+// SearchJobs searches jobs with filters (public endpoint). A keyword is parsed
+// as a web search (quoted phrases, OR, -exclude) and results are ranked by
+// relevance, each with a highlighted snippet; otherwise nearest jobs come first
+// in a radius search, else newest. With mode=hybrid, jobs semantically similar
+// to the keyword are found too and both rankings are fused (see hybridQuery).
+// The response carries the total number of matches and, unless facets=false,
+// facet counts under the same filters.
 func SearchJobs(c *gin.Context) {
-	// Query parameters
 	keyword := c.Query("keyword")
-	location := c.Query("location")
-	jobType := c.Query("job_type")
-	workLocation := c.Query("work_location")
+	mode := c.DefaultQuery("mode", searchModeKeyword)
+	if mode != searchModeKeyword && mode != searchModeHybrid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be keyword or hybrid"})
+		return
+	}
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
@@ -227,56 +310,61 @@ func SearchJobs(c *gin.Context) {
 	}
 	offset := (page - 1) * limit
 
+	// Hybrid search needs the keyword's embedding; without the embedding
+	// server it degrades to keyword search
+	var embedding []float32
+	if mode == searchModeHybrid {
+		var err error
+		if keyword == "" {
+			mode = searchModeKeyword
+		} else if embedding, err = embeddingService.GetEmbedding(keyword); err != nil {
+			log.Printf("SearchJobs falling back to keyword search: %v", err)
+			mode = searchModeKeyword
+		}
+	}
+
+	filter, err := parseJobFilter(c, mode == searchModeKeyword)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Build query
-	query := `
-		SELECT j.id, j.title, j.description, j.salary, j.location, j.job_type, j.work_location,
-		       j.openings, j.required_skills, j.company_id, j.recruiter_id, j.status, j.created_at, j.updated_at,
-		       c.name as company_name
+	var query string
+	if mode == searchModeHybrid {
+		vector, err := findVectorMatches(embedding)
+		if err != nil {
+			log.Printf("SearchJobs vector matches failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		query = hybridQuery(&filter, vector)
+	} else {
+		rank := "0::float8"
+		if keyword != "" {
+			rank = keywordRank
+		}
+		query = `
+		SELECT ` + searchColumns + `, ` + rank + ` AS rank, ` + filter.distanceColumn() + ` AS distance_km
 		FROM jobs j
 		JOIN companies c ON j.company_id = c.id
-		WHERE j.status = 'active'
-	`
-	args := []interface{}{}
-	argIndex := 1
+		WHERE ` + filter.where + `
+		ORDER BY rank DESC, distance_km, j.created_at DESC`
+	}
+	query += `
+		LIMIT $` + strconv.Itoa(len(filter.args)+1) + ` OFFSET $` + strconv.Itoa(len(filter.args)+2)
+	args := append(append([]interface{}{}, filter.args...), limit, offset)
 
+	// Snippets are only built for the page being returned
+	snippet := "''"
 	if keyword != "" {
-		query += ` AND (j.title ILIKE $` + strconv.Itoa(argIndex) + ` OR j.description ILIKE $` + strconv.Itoa(argIndex) + `)`
-		args = append(args, "%"+keyword+"%")
-		argIndex++
-	}
-	if location != "" {
-		query += ` AND j.location ILIKE $` + strconv.Itoa(argIndex)
-		args = append(args, "%"+location+"%")
-		argIndex++
-	}
-	if jobType != "" {
-		query += ` AND j.job_type = $` + strconv.Itoa(argIndex)
-		args = append(args, jobType)
-		argIndex++
-	}
-	if workLocation != "" {
-		query += ` AND j.work_location = $` + strconv.Itoa(argIndex)
-		args = append(args, workLocation)
-		argIndex++
-	}
-	companyID := c.Query("company_id")
-	if companyID != "" {
-		query += ` AND j.company_id = $` + strconv.Itoa(argIndex)
-		args = append(args, companyID)
-		argIndex++
-	}
-	recruiterID := c.Query("recruiter_id")
-	if recruiterID != "" {
-		query += ` AND j.recruiter_id = $` + strconv.Itoa(argIndex)
-		args = append(args, recruiterID)
-		argIndex++
+		snippet = `ts_headline('english', ` + snippetSource + `, websearch_to_tsquery('english', $1), '` + snippetOptions + `')`
 	}
-
-	query += ` ORDER BY j.created_at DESC LIMIT $` + strconv.Itoa(argIndex) + ` OFFSET $` + strconv.Itoa(argIndex+1)
-	args = append(args, limit, offset)
+	query = `SELECT r.*, ` + snippet + ` FROM (` + query + `) r ORDER BY r.rank DESC, r.distance_km, r.created_at DESC`
 
 	rows, err := config.DB.Query(query, args...)
 	if err != nil {
+		log.Printf("SearchJobs query failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
@@ -285,21 +373,54 @@ func SearchJobs(c *gin.Context) {
 	jobs := []models.Job{}
 	for rows.Next() {
 		var job models.Job
-		err := rows.Scan(&job.ID, &job.Title, &job.Description, &job.Salary, &job.Location, &job.JobType,
+		var keywordPosition, vectorPosition sql.NullInt64
+		var keywordScore, vectorScore sql.NullFloat64
+		dest := []interface{}{&job.ID, &job.Title, &job.Description, &job.Salary, &job.SalaryMin, &job.SalaryMax, &job.Currency,
+			&job.SalaryPeriod, &job.SalaryHidden, &job.Location, &job.PlaceID, &job.Latitude, &job.Longitude, &job.JobType,
 			&job.WorkLocation, &job.Openings, pq.Array(&job.RequiredSkills), &job.CompanyID,
-			&job.RecruiterID, &job.Status, &job.CreatedAt, &job.UpdatedAt, &job.CompanyName)
-		if err != nil {
+			&job.RecruiterID, &job.Status, &job.CreatedAt, &job.UpdatedAt, &job.CompanyName,
+			&job.Rank, &job.DistanceKm}
+		if mode == searchModeHybrid {
+			dest = append(dest, &keywordPosition, &keywordScore, &vectorPosition, &vectorScore)
+		}
+		if err := rows.Scan(append(dest, &job.Snippet)...); err != nil {
 			continue
 		}
+		if mode == searchModeHybrid {
+			job.Explanation = explainHybrid(keywordPosition, keywordScore, vectorPosition, vectorScore)
+			job.Similarity = float32(vectorScore.Float64)
+		}
+		hideSalary(&job)
 		jobs = append(jobs, job)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"jobs":  jobs,
-		"page":  page,
-		"limit": limit,
-		"count": len(jobs),
-	})
+	var total int
+	var facets map[string][]models.Facet
+	if c.Query("facets") == "false" {
+		err = config.DB.QueryRow(`SELECT COUNT(*) FROM jobs j JOIN companies c ON j.company_id = c.id WHERE `+filter.where,
+			filter.args...).Scan(&total)
+	} else {
+		total, facets, err = searchFacets(filter)
+	}
+	if err != nil {
+		log.Printf("SearchJobs count failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	response := gin.H{
+		"mode":        mode,
+		"jobs":        jobs,
+		"page":        page,
+		"limit":       limit,
+		"count":       len(jobs),
+		"total":       total,
+		"total_pages": (total + limit - 1) / limit,
+	}
+	if facets != nil {
+		response["facets"] = facets
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // GetJobsByCompany retrieves all jobs for a company (public endpoint)
@@ -307,8 +428,9 @@ func GetJobsByCompany(c *gin.Context) {
 	companyID := c.Param("companyId")
 
 	query := `
-		SELECT j.id, j.title, j.description, j.salary, j.location, j.job_type, j.work_location,
-		       j.openings, j.required_skills, j.company_id, j.recruiter_id, j.status, j.created_at, j.updated_at,
+		SELECT j.id, j.title, j.description, j.salary, j.salary_min, j.salary_max, COALESCE(j.currency, ''), COALESCE(j.salary_period, ''), j.salary_hidden,
+		       j.location, COALESCE(j.place_id, ''), j.latitude, j.longitude, j.job_type, j.work_location,
+		       j.openings, j.required_skills, j.company_id, COALESCE(j.recruiter_id::text, ''), j.status, j.created_at, j.updated_at,
 		       c.name as company_name
 		FROM jobs j
 		JOIN companies c ON j.company_id = c.id
@@ -326,12 +448,14 @@ func GetJobsByCompany(c *gin.Context) {
 	jobs := []models.Job{}
 	for rows.Next() {
 		var job models.Job
-		err := rows.Scan(&job.ID, &job.Title, &job.Description, &job.Salary, &job.Location, &job.JobType,
+		err := rows.Scan(&job.ID, &job.Title, &job.Description, &job.Salary, &job.SalaryMin, &job.SalaryMax, &job.Currency,
+			&job.SalaryPeriod, &job.SalaryHidden, &job.Location, &job.PlaceID, &job.Latitude, &job.Longitude, &job.JobType,
 			&job.WorkLocation, &job.Openings, pq.Array(&job.RequiredSkills), &job.CompanyID,
 			&job.RecruiterID, &job.Status, &job.CreatedAt, &job.UpdatedAt, &job.CompanyName)
 		if err != nil {
 			continue
 		}
+		hideSalary(&job)
 		jobs = append(jobs, job)
 	}
 
@@ -341,11 +465,9 @@ func GetJobsByCompany(c *gin.Context) {
 // GetJobApplications retrieves all applications for a job (recruiters only)
 func GetJobApplications(c *gin.Context) {
 	jobID := c.Param("id")
-	recruiterID := c.GetString("user_id")
 
-	// Check ownership
-	var ownerID string
-	err := config.DB.QueryRow("SELECT recruiter_id FROM jobs WHERE id = $1", jobID).Scan(&ownerID)
+	// Check membership of the job's company
+	role, err := jobCompanyRole(jobID, c.GetString("user_id"))
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 		return
@@ -354,14 +476,13 @@ func GetJobApplications(c *gin.Context) {
 		return
 	}
 
-	if ownerID != recruiterID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only view applications for your own jobs"})
+	if !policy.Authorize(c, policy.JobApplicationsRead, role) {
 		return
 	}
 
 	query := `
-		SELECT a.id, a.job_id, a.applicant_id, a.email, a.resume_url, a.cover_letter, a.status, a.subscribed, a.applied_at, a.updated_at,
-		       u.name as applicant_name
+		SELECT a.id, a.job_id, COALESCE(a.applicant_id::text, ''), a.email, a.resume_url, a.cover_letter, a.status, a.subscribed, a.applied_at, a.updated_at,
+		       COALESCE(u.name, 'Deleted user') as applicant_name
 		FROM applications a
 		LEFT JOIN users u ON a.applicant_id = u.id
 		WHERE a.job_id = $1
@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/job-service/config"
+	"github.com/job-portal/job-service/embedding"
+	"github.com/job-portal/job-service/geo"
+	"github.com/job-portal/job-service/models"
+	"github.com/job-portal/job-service/salary"
+	"github.com/lib/pq"
+)
+
+// A job search's results, total and facets are all computed under the same
+// filters, built once from the query string by parseJobFilter.
+
+// jobFilter is a WHERE clause over jobs j JOIN companies c, with its arguments
+type jobFilter struct {
+	where string
+	args  []interface{}
+
+	// distance is a job's distance in km from the point of a radius search,
+	// "" without one
+	distance string
+}
+
+// add appends a condition whose placeholders are written as ?, one per argument
+func (f *jobFilter) add(condition string, args ...interface{}) {
+	for _, arg := range args {
+		condition = strings.Replace(condition, "?", f.arg(arg), 1)
+	}
+	f.where += " AND " + condition
+}
+
+// arg adds an argument, returning its placeholder
+func (f *jobFilter) arg(arg interface{}) string {
+	f.args = append(f.args, arg)
+	return "$" + strconv.Itoa(len(f.args))
+}
+
+// Radius searches default to and are capped at these distances
+const (
+	defaultRadiusKm = 25
+	maxRadiusKm     = 500
+)
+
+// distanceKm is the great-circle distance of a job from the point whose
+// latitude and longitude are the placeholders %[1]s and %[2]s
+const distanceKm = `(2 * 6371 * asin(LEAST(1, sqrt(
+	power(sin(radians(j.latitude - %[1]s) / 2), 2) +
+	cos(radians(%[1]s)) * cos(radians(j.latitude)) * power(sin(radians(j.longitude - %[2]s) / 2), 2)))))`
+
+// postedWithin maps the posted_within parameter and facet buckets to intervals
+var postedWithin = []struct {
+	value, label, interval string
+}{
+	{"24h", "Last 24 hours", "1 day"},
+	{"7d", "Last 7 days", "7 days"},
+	{"30d", "Last 30 days", "30 days"},
+}
+
+// parseJobFilter reads the search filters shared by every job search. A keyword,
+// if any, is always $1 so rankings can refer to it; matchKeyword requires jobs
+// to match it (hybrid search also finds jobs that only match semantically).
+func parseJobFilter(c *gin.Context, matchKeyword bool) (jobFilter, error) {
+	f := jobFilter{where: "j.status = 'active'"}
+
+	if keyword := c.Query("keyword"); keyword != "" {
+		if matchKeyword {
+			f.add("j.search_vector @@ websearch_to_tsquery('english', ?)", keyword)
+		} else {
+			f.arg(keyword)
+		}
+	}
+	// A location the gazetteer knows also matches jobs in the same place
+	// spelled differently ("Hanoi" and "Ha Noi")
+	if location := c.Query("location"); location != "" {
+		if place, ok := resolvePlace(location); ok {
+			f.add("(j.place_id = ? OR j.location ILIKE ?)", place.ID, "%"+location+"%")
+		} else {
+			f.add("j.location ILIKE ?", "%"+location+"%")
+		}
+	}
+	if jobType := c.Query("job_type"); jobType != "" {
+		f.add("j.job_type = ?", jobType)
+	}
+	if workLocation := c.Query("work_location"); workLocation != "" {
+		f.add("j.work_location = ?", workLocation)
+	}
+	if companyID := c.Query("company_id"); companyID != "" {
+		f.add("j.company_id = ?", companyID)
+	}
+	if recruiterID := c.Query("recruiter_id"); recruiterID != "" {
+		f.add("j.recruiter_id = ?", recruiterID)
+	}
+	// Jobs of every company the user is a team member of
+	if memberID := c.Query("member_id"); memberID != "" {
+		f.add("j.company_id IN (SELECT company_id FROM company_members WHERE user_id = ?)", memberID)
+	}
+	// Jobs requiring every listed skill (comma-separated, case-insensitive)
+	if skills := c.Query("skills"); skills != "" {
+		for _, skill := range strings.Split(skills, ",") {
+			if skill = strings.TrimSpace(skill); skill != "" {
+				f.add("EXISTS (SELECT 1 FROM unnest(j.required_skills) s WHERE LOWER(s) = LOWER(?))", skill)
+			}
+		}
+	}
+	if within := c.Query("posted_within"); within != "" {
+		for _, bucket := range postedWithin {
+			if bucket.value == within {
+				f.where += " AND j.created_at >= CURRENT_TIMESTAMP - INTERVAL '" + bucket.interval + "'"
+			}
+		}
+	}
+
+	if err := f.addRadius(c); err != nil {
+		return f, err
+	}
+
+	// Pay filters compare annualized amounts in one currency, so a job paying
+	// 5,000/month matches salary_min=50000 (per year). salary_period says what
+	// period the filter amounts are in. Jobs hiding their salary never match.
+	minPay, maxPay := c.Query("salary_min"), c.Query("salary_max")
+	if currency := c.Query("currency"); currency != "" {
+		f.add("j.currency = ?", strings.ToUpper(currency))
+	} else if minPay != "" || maxPay != "" {
+		return f, errors.New("currency is required with salary_min or salary_max")
+	}
+	if minPay != "" || maxPay != "" {
+		period := c.DefaultQuery("salary_period", salary.Year)
+		if period != salary.Hour && period != salary.Month && period != salary.Year {
+			return f, errors.New("salary_period must be hour, month or year")
+		}
+		f.where += " AND NOT j.salary_hidden"
+		if minPay != "" {
+			amount, err := strconv.ParseFloat(minPay, 64)
+			if err != nil {
+				return f, errors.New("salary_min must be a number")
+			}
+			f.add("COALESCE(j.salary_max_annual, j.salary_min_annual) >= ?", salary.PerYear(amount, period))
+		}
+		if maxPay != "" {
+			amount, err := strconv.ParseFloat(maxPay, 64)
+			if err != nil {
+				return f, errors.New("salary_max must be a number")
+			}
+			f.add("COALESCE(j.salary_min_annual, j.salary_max_annual) <= ?", salary.PerYear(amount, period))
+		}
+	}
+
+	return f, nil
+}
+
+// addRadius limits jobs to radius_km (default 25) around a point given as
+// lat/lng or as near=<place>, and sets f.distance
+func (f *jobFilter) addRadius(c *gin.Context) error {
+	var lat, lng float64
+	switch near, latParam, lngParam := c.Query("near"), c.Query("lat"), c.Query("lng"); {
+	case near != "":
+		place, ok := resolvePlace(near)
+		if !ok {
+			return fmt.Errorf("unknown place %q", near)
+		}
+		lat, lng = place.Latitude, place.Longitude
+	case latParam != "" || lngParam != "":
+		var errLat, errLng error
+		lat, errLat = strconv.ParseFloat(latParam, 64)
+		lng, errLng = strconv.ParseFloat(lngParam, 64)
+		if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+			return errors.New("lat and lng must be a valid latitude and longitude")
+		}
+	default:
+		if c.Query("radius_km") != "" {
+			return errors.New("radius_km requires lat and lng or near")
+		}
+		return nil
+	}
+
+	radius := float64(defaultRadiusKm)
+	if r := c.Query("radius_km"); r != "" {
+		var err error
+		radius, err = strconv.ParseFloat(r, 64)
+		if err != nil || radius <= 0 || radius > maxRadiusKm {
+			return fmt.Errorf("radius_km must be a number between 0 and %d", maxRadiusKm)
+		}
+	}
+
+	// Bounding box first, so the coordinates index narrows the rows to measure
+	minLat, maxLat, minLng, maxLng := geo.BoundingBox(lat, lng, radius)
+	f.add("j.latitude BETWEEN ? AND ?", minLat, maxLat)
+	f.add("j.longitude BETWEEN ? AND ?", minLng, maxLng)
+	f.distance = fmt.Sprintf(distanceKm, f.arg(lat)+"::float8", f.arg(lng)+"::float8")
+	f.add(f.distance+" <= ?", radius)
+	return nil
+}
+
+// distanceColumn is a job's distance from the point of a radius search, NULL
+// without one
+func (f jobFilter) distanceColumn() string {
+	if f.distance == "" {
+		return "NULL::float8"
+	}
+	return "ROUND(" + f.distance + "::numeric, 1)::float8"
+}
+
+// searchColumns are the job columns of a search result, in models.Job order
+const searchColumns = `j.id, j.title, j.description, j.salary, j.salary_min, j.salary_max, COALESCE(j.currency, ''), COALESCE(j.salary_period, ''), j.salary_hidden,
+		       j.location, COALESCE(j.place_id, ''), j.latitude, j.longitude, j.job_type, j.work_location,
+		       j.openings, j.required_skills, j.company_id, COALESCE(j.recruiter_id::text, ''), j.status, j.created_at, j.updated_at,
+		       c.name as company_name`
+
+// Search modes: keyword (full-text) alone, or fused with semantic search
+const (
+	searchModeKeyword = "keyword"
+	searchModeHybrid  = "hybrid"
+)
+
+// rrfK damps the weight of top positions in reciprocal-rank fusion; 60 is
+// the constant from the original RRF paper
+const rrfK = 60
+
+// semanticCandidates is how many of the chunks (and titles of jobs embedded
+// before chunking) nearest to the query hybrid search considers, so the
+// vector indexes find them rather than a scan of every job
+const semanticCandidates = 1000
+
+// vectorMatches are the jobs semantically similar to a query and their
+// similarity (max-sim, as semanticSimilarity), regardless of filters
+type vectorMatches struct {
+	ids    []string
+	scores []float64
+}
+
+// findVectorMatches looks up the jobs similar to the query embedding once, so
+// a hybrid search's results, total and facets all read the same matches
+func findVectorMatches(queryEmbedding []float32) (vectorMatches, error) {
+	var m vectorMatches
+	rows, err := config.DB.Query(`
+		SELECT id, MAX(similarity) FROM (
+			(SELECT job_id AS id, 1 - (embedding <=> $1::vector) AS similarity
+			 FROM job_embeddings
+			 ORDER BY embedding <=> $1::vector
+			 LIMIT $3)
+			UNION ALL
+			(SELECT j.id, 1 - (j.title_embedding <=> $1::vector)
+			 FROM jobs j
+			 WHERE j.title_embedding IS NOT NULL
+			   AND NOT EXISTS (SELECT 1 FROM job_embeddings e WHERE e.job_id = j.id)
+			 ORDER BY j.title_embedding <=> $1::vector
+			 LIMIT $3)
+		) nearest
+		WHERE similarity > $2
+		GROUP BY id`,
+		embedding.VectorLiteral(queryEmbedding), semanticThreshold, semanticCandidates)
+	if err != nil {
+		return m, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var score float64
+		if err := rows.Scan(&id, &score); err != nil {
+			return m, err
+		}
+		m.ids = append(m.ids, id)
+		m.scores = append(m.scores, score)
+	}
+	return m, rows.Err()
+}
+
+// hybridQuery ranks jobs matching the keyword in $1, among the vector matches,
+// or both. Each retriever orders its own matches and a job's rank is the sum
+// over retrievers of 1 / (rrfK + position), so jobs that rank well in both
+// come first. It restricts f to the jobs either retriever matches, so totals
+// and facets count the same jobs.
+func hybridQuery(f *jobFilter, vector vectorMatches) string {
+	ids := f.arg(pq.Array(vector.ids)) + "::uuid[]"
+	scores := f.arg(pq.Array(vector.scores)) + "::float8[]"
+	matchesKeyword := "j.search_vector @@ websearch_to_tsquery('english', $1)"
+	f.where += " AND (" + matchesKeyword + " OR j.id = ANY(" + ids + "))"
+
+	k := strconv.Itoa(rrfK)
+	return `
+		WITH matches AS (
+			SELECT j.id, j.created_at,
+			       CASE WHEN ` + matchesKeyword + ` THEN ` + keywordRank + ` END AS keyword_score,
+			       v.score AS vector_score
+			FROM jobs j
+			JOIN companies c ON j.company_id = c.id
+			LEFT JOIN unnest(` + ids + `, ` + scores + `) AS v(id, score) ON v.id = j.id
+			WHERE ` + f.where + `
+		), positions AS (
+			SELECT id, keyword_score, vector_score,
+			       CASE WHEN keyword_score IS NOT NULL THEN ROW_NUMBER() OVER (ORDER BY keyword_score DESC NULLS LAST, created_at DESC) END AS keyword_position,
+			       CASE WHEN vector_score IS NOT NULL THEN ROW_NUMBER() OVER (ORDER BY vector_score DESC NULLS LAST, created_at DESC) END AS vector_position
+			FROM matches
+		)
+		SELECT ` + searchColumns + `,
+		       (COALESCE(1.0 / (` + k + ` + p.keyword_position), 0) + COALESCE(1.0 / (` + k + ` + p.vector_position), 0))::float8 AS rank,
+		       ` + f.distanceColumn() + ` AS distance_km,
+		       p.keyword_position, p.keyword_score, p.vector_position, p.vector_score
+		FROM positions p
+		JOIN jobs j ON j.id = p.id
+		JOIN companies c ON j.company_id = c.id
+		ORDER BY rank DESC, distance_km, j.created_at DESC`
+}
+
+// explainHybrid builds a hybrid result's explanation from its retriever
+// positions and scores
+func explainHybrid(keywordPosition sql.NullInt64, keywordScore sql.NullFloat64,
+	vectorPosition sql.NullInt64, vectorScore sql.NullFloat64) *models.SearchExplanation {
+	match := func(position sql.NullInt64, score sql.NullFloat64) *models.RetrieverMatch {
+		if !position.Valid {
+			return nil
+		}
+		return &models.RetrieverMatch{
+			Position:     int(position.Int64),
+			Score:        score.Float64,
+			Contribution: 1 / float64(rrfK+position.Int64),
+		}
+	}
+	return &models.SearchExplanation{
+		Keyword: match(keywordPosition, keywordScore),
+		Vector:  match(vectorPosition, vectorScore),
+	}
+}
+
+// facetLimit caps the values returned for open-ended facets (location, skills, company)
+const facetLimit = 20
+
+// normalizedLocation groups spellings of a location that differ only in case and spacing
+const normalizedLocation = `LOWER(REGEXP_REPLACE(TRIM(j.location), '\s+', ' ', 'g'))`
+
+// searchFacets counts the jobs matching f by type, work location, location,
+// skill, company and posting date, along with the total number of matches
+func searchFacets(f jobFilter) (int, map[string][]models.Facet, error) {
+	from := " FROM jobs j JOIN companies c ON j.company_id = c.id WHERE " + f.where
+	top := " ORDER BY 4 DESC, 3 LIMIT " + strconv.Itoa(facetLimit) + ")"
+
+	branches := []string{
+		`SELECT 'total', '', '', COUNT(*)` + from,
+		`(SELECT 'job_type', j.job_type::text, j.job_type::text, COUNT(*)` + from + ` GROUP BY j.job_type ORDER BY 4 DESC)`,
+		`(SELECT 'work_location', j.work_location::text, j.work_location::text, COUNT(*)` + from + ` GROUP BY j.work_location ORDER BY 4 DESC)`,
+		`(SELECT 'location', ` + normalizedLocation + `, MIN(TRIM(j.location)), COUNT(*)` + from + ` GROUP BY 2` + top,
+		`(SELECT 'skills', LOWER(s), MIN(s), COUNT(DISTINCT j.id)
+		  FROM jobs j JOIN companies c ON j.company_id = c.id CROSS JOIN LATERAL unnest(j.required_skills) s
+		  WHERE ` + f.where + ` AND s <> '' GROUP BY 2` + top,
+		`(SELECT 'company', j.company_id::text, MIN(c.name), COUNT(*)` + from + ` GROUP BY j.company_id` + top,
+	}
+	for _, bucket := range postedWithin {
+		branches = append(branches, `SELECT 'posted_within', '`+bucket.value+`', '`+bucket.label+`', COUNT(*)`+from+
+			` AND j.created_at >= CURRENT_TIMESTAMP - INTERVAL '`+bucket.interval+`'`)
+	}
+
+	rows, err := config.DB.Query(strings.Join(branches, " UNION ALL "), f.args...)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	total := 0
+	facets := map[string][]models.Facet{
+		"job_type":      {},
+		"work_location": {},
+		"location":      {},
+		"skills":        {},
+		"company":       {},
+		"posted_within": {},
+	}
+	for rows.Next() {
+		var name string
+		var facet models.Facet
+		if err := rows.Scan(&name, &facet.Value, &facet.Label, &facet.Count); err != nil {
+			return 0, nil, err
+		}
+		if name == "total" {
+			total = facet.Count
+			continue
+		}
+		facets[name] = append(facets[name], facet)
+	}
+	return total, facets, rows.Err()
+}
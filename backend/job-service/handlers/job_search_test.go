@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"database/sql"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/job-portal/job-service/models"
+	"github.com/lib/pq"
+)
+
+func position(n int64) sql.NullInt64  { return sql.NullInt64{Int64: n, Valid: true} }
+func score(f float64) sql.NullFloat64 { return sql.NullFloat64{Float64: f, Valid: true} }
+
+// fusedRank is a hybrid result's rank as hybridQuery computes it: the sum of
+// its retrievers' contributions
+func fusedRank(e *models.SearchExplanation) float64 {
+	rank := 0.0
+	for _, match := range []*models.RetrieverMatch{e.Keyword, e.Vector} {
+		if match != nil {
+			rank += match.Contribution
+		}
+	}
+	return rank
+}
+
+func TestExplainHybrid(t *testing.T) {
+	e := explainHybrid(position(1), score(0.8), position(3), score(0.62))
+	if e.Keyword == nil || e.Keyword.Position != 1 || e.Keyword.Score != 0.8 || e.Keyword.Contribution != 1.0/61 {
+		t.Errorf("keyword match: got %+v, want position 1, score 0.8, contribution 1/61", e.Keyword)
+	}
+	if e.Vector == nil || e.Vector.Position != 3 || e.Vector.Score != 0.62 || e.Vector.Contribution != 1.0/63 {
+		t.Errorf("vector match: got %+v, want position 3, score 0.62, contribution 1/63", e.Vector)
+	}
+
+	e = explainHybrid(sql.NullInt64{}, sql.NullFloat64{}, position(2), score(0.5))
+	if e.Keyword != nil {
+		t.Errorf("job without a keyword match: got keyword %+v, want none", e.Keyword)
+	}
+	if got := fusedRank(e); math.Abs(got-1.0/62) > 1e-12 {
+		t.Errorf("vector-only rank: got %v, want 1/62", got)
+	}
+}
+
+func TestHybridFusionOrder(t *testing.T) {
+	// Jobs ranking fairly well in both retrievers beat a job topping only one
+	results := []struct {
+		job             string
+		keyword, vector sql.NullInt64
+	}{
+		{"keyword only, first", position(1), sql.NullInt64{}},
+		{"vector only, first", sql.NullInt64{}, position(1)},
+		{"both, second and third", position(2), position(3)},
+		{"both, fifth", position(5), position(5)},
+		{"keyword only, third", position(3), sql.NullInt64{}},
+	}
+	ranks := map[string]float64{}
+	for _, r := range results {
+		ranks[r.job] = fusedRank(explainHybrid(r.keyword, sql.NullFloat64{}, r.vector, sql.NullFloat64{}))
+	}
+	order := make([]string, 0, len(results))
+	for _, r := range results {
+		order = append(order, r.job)
+	}
+	sort.SliceStable(order, func(a, b int) bool { return ranks[order[a]] > ranks[order[b]] })
+
+	want := []string{"both, second and third", "both, fifth", "keyword only, first", "vector only, first", "keyword only, third"}
+	if strings.Join(order, "; ") != strings.Join(want, "; ") {
+		t.Errorf("fused order:\n got %q\nwant %q", order, want)
+	}
+	if ranks["keyword only, first"] != ranks["vector only, first"] {
+		t.Errorf("top of either retriever: got %v and %v, want equal", ranks["keyword only, first"], ranks["vector only, first"])
+	}
+}
+
+func TestHybridQuery(t *testing.T) {
+	f := jobFilter{where: "j.status = 'active'"}
+	f.arg("golang developer")
+	f.add("j.job_type = ?", "full-time")
+	vector := vectorMatches{ids: []string{"job-1", "job-2"}, scores: []float64{0.8, 0.5}}
+
+	query := hybridQuery(&f, vector)
+
+	if len(f.args) != 4 || f.args[0] != "golang developer" ||
+		!reflect.DeepEqual(f.args[2], pq.Array(vector.ids)) || !reflect.DeepEqual(f.args[3], pq.Array(vector.scores)) {
+		t.Fatalf("args: got %v, want keyword, job type, matched job IDs and their similarities", f.args)
+	}
+	// Totals and facets count the jobs either retriever matches
+	wantWhere := "j.status = 'active' AND j.job_type = $2 AND " +
+		"(j.search_vector @@ websearch_to_tsquery('english', $1) OR j.id = ANY($3::uuid[]))"
+	if f.where != wantWhere {
+		t.Errorf("where:\n got %s\nwant %s", f.where, wantWhere)
+	}
+
+	for _, want := range []string{
+		"WHERE " + wantWhere,
+		"LEFT JOIN unnest($3::uuid[], $4::float8[]) AS v(id, score) ON v.id = j.id",
+		"COALESCE(1.0 / (60 + p.keyword_position), 0) + COALESCE(1.0 / (60 + p.vector_position), 0)",
+		"ORDER BY keyword_score DESC NULLS LAST, created_at DESC",
+		"ORDER BY vector_score DESC NULLS LAST, created_at DESC",
+		"NULL::float8 AS distance_km",
+		"ORDER BY rank DESC, distance_km, j.created_at DESC",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query does not contain %q:\n%s", want, query)
+		}
+	}
+	// Similarities come from the vector matches, not from the embeddings again
+	if strings.Contains(query, "job_embeddings") || strings.Contains(query, "<=>") {
+		t.Errorf("query recomputes similarity:\n%s", query)
+	}
+}
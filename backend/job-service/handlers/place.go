@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+
+	"github.com/job-portal/job-service/geo"
+)
+
+// Global geocoder for job locations and company headquarters
+var geocoder geo.Geocoder
+
+// InitGeocoder loads the gazetteer at GAZETTEER_PATH (a GeoNames-style file
+// such as cities15000.txt), or the bundled one of major cities
+func InitGeocoder() {
+	var gazetteer *geo.Gazetteer
+	var err error
+	if path := os.Getenv("GAZETTEER_PATH"); path != "" {
+		gazetteer, err = geo.OpenGazetteer(path)
+	} else {
+		gazetteer, err = geo.BundledGazetteer()
+	}
+	if err != nil {
+		log.Printf("⚠️  Failed to load gazetteer: %v (locations will not be geocoded)", err)
+		return
+	}
+	geocoder = gazetteer
+	log.Println("✅ Gazetteer loaded")
+}
+
+// resolvePlace geocodes a location, reporting false if it has no known place
+func resolvePlace(location string) (geo.Place, bool) {
+	if geocoder == nil || location == "" {
+		return geo.Place{}, false
+	}
+	place, err := geocoder.Geocode(location)
+	if err != nil {
+		if !errors.Is(err, geo.ErrNotFound) {
+			log.Printf("Failed to geocode %q: %v", location, err)
+		}
+		return geo.Place{}, false
+	}
+	return place, true
+}
+
+// placeColumns are the place_id, latitude and longitude to store for a
+// location, NULL if it has no known place
+func placeColumns(location string) (sql.NullString, sql.NullFloat64, sql.NullFloat64) {
+	place, ok := resolvePlace(location)
+	return sql.NullString{String: place.ID, Valid: ok},
+		sql.NullFloat64{Float64: place.Latitude, Valid: ok},
+		sql.NullFloat64{Float64: place.Longitude, Valid: ok}
+}
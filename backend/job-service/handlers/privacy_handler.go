@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/job-portal/job-service/config"
+	"github.com/job-portal/pkg/privacy"
+)
+
+// PrivacyParticipant exports and erases a user's companies, team memberships,
+// jobs and applications. Erasure keeps recruiters' hiring history: applications
+// are anonymized rather than deleted, and a deleted recruiter's jobs are closed
+// and left unowned.
+type PrivacyParticipant struct{}
+
+func (PrivacyParticipant) Tables() ([]string, error) {
+	return []string{"companies", "company_members", "company_invitations", "jobs", "applications"}, nil
+}
+
+func (PrivacyParticipant) Export(userID string) (*privacy.Export, error) {
+	queries := map[string]string{
+		"companies": `SELECT id, name, description, website, logo_url, industry, company_size,
+			founded_year, headquarters, created_at, updated_at
+			FROM companies WHERE recruiter_id = $1`,
+		"company_memberships": `SELECT m.company_id, c.name AS company_name, m.role, m.created_at, m.updated_at
+			FROM company_members m JOIN companies c ON m.company_id = c.id
+			WHERE m.user_id = $1`,
+		"company_invitations": `SELECT i.company_id, c.name AS company_name, i.email, i.role, i.expires_at,
+			i.accepted_at, i.declined_at, i.revoked_at, i.created_at
+			FROM company_invitations i JOIN companies c ON i.company_id = c.id
+			WHERE i.accepted_user_id = $1 OR LOWER(i.email) = (SELECT LOWER(email) FROM users WHERE id = $1)`,
+		"jobs": `SELECT j.id, j.title, j.description, j.salary, j.location, j.job_type, j.work_location,
+			j.openings, j.required_skills, j.status, c.name AS company_name, j.created_at, j.updated_at
+			FROM jobs j LEFT JOIN companies c ON j.company_id = c.id
+			WHERE j.recruiter_id = $1`,
+		"applications": `SELECT a.id, a.job_id, j.title AS job_title, c.name AS company_name, a.email,
+			a.resume_url, a.cover_letter, a.status, a.applied_at, a.updated_at
+			FROM applications a
+			LEFT JOIN jobs j ON a.job_id = j.id
+			LEFT JOIN companies c ON j.company_id = c.id
+			WHERE a.applicant_id = $1`,
+	}
+
+	export := &privacy.Export{Records: map[string]json.RawMessage{}}
+	for name, query := range queries {
+		records, err := privacy.QueryRecords(config.DB, query, userID)
+		if err != nil {
+			return nil, err
+		}
+		export.Records[name] = records
+	}
+	return export, nil
+}
+
+func (PrivacyParticipant) Erase(userID string) error {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The recruiter still sees that someone applied and how far they got
+	_, err = tx.Exec(`
+		UPDATE applications
+		SET applicant_id = NULL, email = '', resume_url = '', cover_letter = '',
+		    subscribed = FALSE, anonymized_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE applicant_id = $1
+	`, userID)
+	if err != nil {
+		return err
+	}
+
+	// Invitations are addressed to the user's email rather than their ID
+	_, err = tx.Exec(`
+		DELETE FROM company_invitations
+		WHERE accepted_user_id = $1 OR LOWER(email) = (SELECT LOWER(email) FROM users WHERE id = $1)
+	`, userID)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM company_members WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	// Companies outlive their recruiter; an admin can assign them to someone else
+	if _, err := tx.Exec("UPDATE companies SET recruiter_id = NULL WHERE recruiter_id = $1", userID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		UPDATE jobs SET recruiter_id = NULL, status = 'closed', updated_at = CURRENT_TIMESTAMP
+		WHERE recruiter_id = $1
+	`, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/job-service/config"
+	"github.com/job-portal/job-service/embedding"
+	"github.com/job-portal/job-service/models"
+	"github.com/lib/pq"
+)
+
+// Recommendations match active jobs against a job seeker's profile: their
+// skills, bio and resume text (written in user-service) and the jobs they have
+// applied to. Each part is embedded and the profile vector is their normalized
+// mean; a job scores by its similarity to that vector and by how many of its
+// required skills the user has.
+
+// Parts of a profile, by what the job seeker's recommendations are based on
+const (
+	profileSkills       = "skills"
+	profileBio          = "bio"
+	profileResume       = "resume"
+	profileApplications = "applications"
+)
+
+// profileReasons explain a job's similarity to each part of a profile
+var profileReasons = map[string]string{
+	profileSkills:       "Matches your skills",
+	profileBio:          "Matches your bio",
+	profileResume:       "Matches your resume",
+	profileApplications: "Similar to jobs you applied to",
+}
+
+// Weights of similarity and skill coverage in a recommendation's score
+const (
+	recommendSimilarityWeight = 0.7
+	recommendSkillWeight      = 0.3
+)
+
+// Limits on the text and history embedded per request
+const (
+	maxResumeChunks = 4  // Resume windows of embedding.ChunkWords words
+	maxAppliedJobs  = 20 // Most recent applications
+)
+
+// profilePart is the embedding of one part of a job seeker's profile
+type profilePart struct {
+	source string
+	vector []float32
+}
+
+// jobSeekerProfile is what a user's recommendations are based on
+type jobSeekerProfile struct {
+	skills []string // Lowercase skill names
+	parts  []profilePart
+}
+
+// loadProfile reads and embeds a user's profile. Without the embedding server
+// only the skills (and applied jobs, which are embedded already) are used.
+func loadProfile(userID string) (jobSeekerProfile, error) {
+	var profile jobSeekerProfile
+
+	var bio, resumeText sql.NullString
+	err := config.DB.QueryRow("SELECT bio, resume_text FROM users WHERE id = $1", userID).Scan(&bio, &resumeText)
+	if err != nil && err != sql.ErrNoRows {
+		return profile, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	rows, err := config.DB.Query(`
+		SELECT s.name
+		FROM user_skills us
+		JOIN skills s ON us.skill_id = s.id
+		WHERE us.user_id = $1
+		ORDER BY s.name
+	`, userID)
+	if err != nil {
+		return profile, fmt.Errorf("failed to load skills: %w", err)
+	}
+	var skills []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return profile, err
+		}
+		skills = append(skills, name)
+		profile.skills = append(profile.skills, strings.ToLower(strings.TrimSpace(name)))
+	}
+	rows.Close()
+
+	// Texts to embed; the resume is split into windows, like job descriptions
+	var sources, texts []string
+	if len(skills) > 0 {
+		sources, texts = append(sources, profileSkills), append(texts, "Skills: "+strings.Join(skills, ", "))
+	}
+	if text := strings.TrimSpace(bio.String); text != "" {
+		sources, texts = append(sources, profileBio), append(texts, text)
+	}
+	for _, window := range embedding.Windows(resumeText.String, maxResumeChunks) {
+		sources, texts = append(sources, profileResume), append(texts, window)
+	}
+
+	if len(texts) > 0 && embeddingService != nil {
+		vectors, err := embeddingService.GetBatchEmbeddings(texts)
+		if err == nil && len(vectors) != len(texts) {
+			err = fmt.Errorf("embedding server returned %d embeddings for %d texts", len(vectors), len(texts))
+		}
+		if err != nil {
+			log.Printf("Recommending jobs for user %s by skills only: %v", userID, err)
+		} else {
+			var resume [][]float32
+			for i, source := range sources {
+				if source == profileResume {
+					resume = append(resume, vectors[i])
+					continue
+				}
+				profile.parts = append(profile.parts, profilePart{source: source, vector: vectors[i]})
+			}
+			if len(resume) > 0 {
+				profile.parts = append(profile.parts, profilePart{source: profileResume, vector: embedding.Mean(resume)})
+			}
+		}
+	}
+
+	var applied sql.NullString
+	err = config.DB.QueryRow(`
+		SELECT AVG(v)::text FROM (
+			SELECT COALESCE(j.embedding, j.title_embedding) AS v
+			FROM applications a
+			JOIN jobs j ON a.job_id = j.id
+			WHERE a.applicant_id = $1 AND COALESCE(j.embedding, j.title_embedding) IS NOT NULL
+			ORDER BY a.applied_at DESC
+			LIMIT $2
+		) recent
+	`, userID, maxAppliedJobs).Scan(&applied)
+	if err != nil {
+		return profile, fmt.Errorf("failed to load applied jobs: %w", err)
+	}
+	if applied.Valid {
+		vector, err := embedding.ParseVector(applied.String)
+		if err != nil {
+			return profile, err
+		}
+		profile.parts = append(profile.parts, profilePart{source: profileApplications, vector: embedding.Mean([][]float32{vector})})
+	}
+
+	return profile, nil
+}
+
+// GetRecommendedJobs recommends active jobs the authenticated user hasn't
+// applied to, best match first, each with why it was recommended. It accepts
+// the filters of SearchJobs. A job is a candidate if it is semantically similar
+// to the user's profile or requires any of their skills.
+func GetRecommendedJobs(c *gin.Context) {
+	userID := c.GetString("user_id")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	filter, err := parseJobFilter(c, true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := loadProfile(userID)
+	if err != nil {
+		log.Printf("GetRecommendedJobs failed to load profile: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load profile"})
+		return
+	}
+	basedOn := []string{}
+	vectors := make([][]float32, len(profile.parts))
+	for i, part := range profile.parts {
+		basedOn = append(basedOn, part.source)
+		vectors[i] = part.vector
+	}
+	// Skills are matched even when they couldn't be embedded
+	if len(profile.skills) > 0 && (len(profile.parts) == 0 || profile.parts[0].source != profileSkills) {
+		basedOn = append([]string{profileSkills}, basedOn...)
+	}
+	if len(basedOn) == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"jobs":        []models.Job{},
+			"based_on":    basedOn,
+			"page":        page,
+			"limit":       limit,
+			"count":       0,
+			"total":       0,
+			"total_pages": 0,
+			"message":     "Add skills, a bio or a resume to your profile to get recommendations",
+		})
+		return
+	}
+
+	filter.add("NOT EXISTS (SELECT 1 FROM applications a WHERE a.job_id = j.id AND a.applicant_id = ?)", userID)
+	similarity := "0::float8"
+	if len(vectors) > 0 {
+		similarity = semanticSimilarity(filter.arg(embedding.VectorLiteral(embedding.Mean(vectors))))
+	}
+	matchedSkills := "ARRAY(SELECT s FROM unnest(j.required_skills) s WHERE LOWER(TRIM(s)) = ANY(" + filter.arg(pq.Array(profile.skills)) + "::text[]))"
+	filter.where += " AND (" + similarity + " > " + filter.arg(semanticThreshold) + " OR cardinality(" + matchedSkills + ") > 0)"
+
+	var total int
+	err = config.DB.QueryRow(`SELECT COUNT(*) FROM jobs j JOIN companies c ON j.company_id = c.id WHERE `+filter.where,
+		filter.args...).Scan(&total)
+	if err != nil {
+		log.Printf("GetRecommendedJobs count failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Only the page being returned is compared with each part of the profile
+	args := append(append([]interface{}{}, filter.args...), limit, offset)
+	partSimilarities := "'{}'::float8[]"
+	if len(profile.parts) > 0 {
+		columns := make([]string, len(profile.parts))
+		for i, part := range profile.parts {
+			args = append(args, embedding.VectorLiteral(part.vector))
+			columns[i] = semanticSimilarity("$" + strconv.Itoa(len(args)))
+		}
+		partSimilarities = "ARRAY[" + strings.Join(columns, ", ") + "]::float8[]"
+	}
+
+	query := `
+		WITH matches AS (
+			SELECT j.id, j.created_at, (` + similarity + `)::float8 AS similarity,
+			       ` + matchedSkills + ` AS matched_skills,
+			       cardinality(j.required_skills) AS required_skills
+			FROM jobs j
+			JOIN companies c ON j.company_id = c.id
+			WHERE ` + filter.where + `
+		), covered AS (
+			SELECT id, created_at, similarity, matched_skills,
+			       COALESCE(cardinality(matched_skills)::float8 / NULLIF(required_skills, 0), 0) AS skill_coverage
+			FROM matches
+		), ranked AS (
+			SELECT *, ` + fmt.Sprintf("(%g * similarity + %g * skill_coverage)", recommendSimilarityWeight, recommendSkillWeight) + `::float8 AS score
+			FROM covered
+			ORDER BY score DESC, created_at DESC
+			LIMIT $` + strconv.Itoa(len(filter.args)+1) + ` OFFSET $` + strconv.Itoa(len(filter.args)+2) + `
+		)
+		SELECT ` + searchColumns + `, ` + filter.distanceColumn() + ` AS distance_km,
+		       r.similarity, r.matched_skills, r.skill_coverage, r.score, ` + partSimilarities + `
+		FROM ranked r
+		JOIN jobs j ON j.id = r.id
+		JOIN companies c ON j.company_id = c.id
+		ORDER BY r.score DESC, j.created_at DESC`
+
+	rows, err := config.DB.Query(query, args...)
+	if err != nil {
+		log.Printf("GetRecommendedJobs query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		var job models.Job
+		recommendation := models.Recommendation{MatchedSkills: []string{}}
+		var similarities []float64
+		err := rows.Scan(&job.ID, &job.Title, &job.Description, &job.Salary, &job.SalaryMin, &job.SalaryMax, &job.Currency,
+			&job.SalaryPeriod, &job.SalaryHidden, &job.Location, &job.PlaceID, &job.Latitude, &job.Longitude, &job.JobType,
+			&job.WorkLocation, &job.Openings, pq.Array(&job.RequiredSkills), &job.CompanyID,
+			&job.RecruiterID, &job.Status, &job.CreatedAt, &job.UpdatedAt, &job.CompanyName, &job.DistanceKm,
+			&recommendation.Similarity, pq.Array(&recommendation.MatchedSkills), &recommendation.SkillCoverage,
+			&recommendation.Score, pq.Array(&similarities))
+		if err != nil {
+			log.Printf("Row scan error: %v", err)
+			continue
+		}
+		recommendation.Reasons = recommendationReasons(job, recommendation, profile, similarities)
+		job.Recommendation = &recommendation
+		hideSalary(&job)
+		jobs = append(jobs, job)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":        jobs,
+		"based_on":    basedOn,
+		"page":        page,
+		"limit":       limit,
+		"count":       len(jobs),
+		"total":       total,
+		"total_pages": (total + limit - 1) / limit,
+	})
+}
+
+// recommendationReasons explains a recommendation: the user's skills the job
+// requires, then each part of their profile the job is similar to, most
+// similar first
+func recommendationReasons(job models.Job, r models.Recommendation, profile jobSeekerProfile, similarities []float64) []string {
+	reasons := []string{}
+	if n := len(r.MatchedSkills); n > 0 {
+		if n == len(job.RequiredSkills) {
+			reasons = append(reasons, fmt.Sprintf("You have all %d of its required skills: %s", n, strings.Join(r.MatchedSkills, ", ")))
+		} else {
+			reasons = append(reasons, fmt.Sprintf("You have %d of its %d required skills: %s", n, len(job.RequiredSkills), strings.Join(r.MatchedSkills, ", ")))
+		}
+	}
+
+	var similar []int
+	for i := range similarities {
+		if i < len(profile.parts) && similarities[i] > semanticThreshold {
+			similar = append(similar, i)
+		}
+	}
+	sort.SliceStable(similar, func(a, b int) bool { return similarities[similar[a]] > similarities[similar[b]] })
+	for _, i := range similar {
+		reasons = append(reasons, fmt.Sprintf("%s (%d%%)", profileReasons[profile.parts[i].source], percent(similarities[i])))
+	}
+
+	// Similar to the profile as a whole though to no one part of it
+	if len(reasons) == 0 {
+		reasons = append(reasons, fmt.Sprintf("Similar to your profile (%d%%)", percent(r.Similarity)))
+	}
+	return reasons
+}
+
+// percent formats a similarity between 0 and 1 as a whole percentage
+func percent(similarity float64) int {
+	return int(math.Round(similarity * 100))
+}
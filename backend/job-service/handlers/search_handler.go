@@ -17,6 +17,19 @@ import (
 // Global embedding service instance
 var embeddingService *embedding.EmbeddingService
 
+// semanticThreshold is the minimum similarity for a job to match semantically
+// (35%) - allows broader semantic matches
+const semanticThreshold = 0.35
+
+// semanticSimilarity is the cosine similarity of a job to the query vector in
+// the given placeholder: that of its most similar chunk (max-sim), or of its
+// title for jobs embedded before chunking
+func semanticSimilarity(vector string) string {
+	return `COALESCE(
+		(SELECT MAX(1 - (e.embedding <=> ` + vector + `::vector)) FROM job_embeddings e WHERE e.job_id = j.id),
+		1 - (j.title_embedding <=> ` + vector + `::vector))`
+}
+
 // InitEmbeddingService initializes the embedding service
 func InitEmbeddingService() {
 	embeddingURL := os.Getenv("EMBEDDING_SERVICE_URL")
@@ -33,7 +46,7 @@ func InitEmbeddingService() {
 	}
 }
 
-// SemanticSearchJobs performs semantic search on job titles using vector similarity
+// SemanticSearchJobs performs semantic search on jobs using vector similarity
 func SemanticSearchJobs(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
@@ -41,7 +54,7 @@ func SemanticSearchJobs(c *gin.Context) {
 		return
 	}
 
-	threshold := float32(0.35) // Minimum similarity score (35%) - allows broader semantic matches
+	threshold := float32(semanticThreshold)
 
 	// Generate embedding for search query
 	queryEmbedding, err := embeddingService.GetEmbedding(query)
@@ -54,25 +67,23 @@ func SemanticSearchJobs(c *gin.Context) {
 	// Semantic search using pgvector
 	sqlQuery := `
 		SELECT 
-			j.id, j.title, j.description, j.salary, j.location, 
+			j.id, j.title, j.description, j.salary, j.salary_min, j.salary_max,
+			COALESCE(j.currency, ''), COALESCE(j.salary_period, ''), j.salary_hidden, j.location,
+			COALESCE(j.place_id, ''), j.latitude, j.longitude,
 			j.job_type, j.work_location, j.openings, j.required_skills,
 			j.company_id, j.status, j.created_at, j.updated_at,
 			c.name as company_name,
-			1 - (j.title_embedding <=> $1::vector) AS similarity
+			` + semanticSimilarity("$1") + ` AS similarity
 		FROM jobs j
 		LEFT JOIN companies c ON j.company_id = c.id
 		WHERE 
-			j.title_embedding IS NOT NULL
-			AND j.status = 'active'
-			AND 1 - (j.title_embedding <=> $1::vector) > $2
+			j.status = 'active'
+			AND ` + semanticSimilarity("$1") + ` > $2
 		ORDER BY similarity DESC
 		LIMIT 20
 	`
 
-	// Format embedding as string "[v1,v2,...]" for pgvector
-	vectorStr := "[" + strings.Trim(strings.Join(strings.Fields(fmt.Sprint(queryEmbedding)), ","), "[]") + "]"
-
-	rows, err := config.DB.Query(sqlQuery, vectorStr, threshold)
+	rows, err := config.DB.Query(sqlQuery, embedding.VectorLiteral(queryEmbedding), threshold)
 	if err != nil {
 		log.Printf("Semantic search query failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database query failed"})
@@ -86,7 +97,9 @@ func SemanticSearchJobs(c *gin.Context) {
 		var requiredSkills sql.NullString
 
 		err := rows.Scan(
-			&job.ID, &job.Title, &job.Description, &job.Salary, &job.Location,
+			&job.ID, &job.Title, &job.Description, &job.Salary, &job.SalaryMin, &job.SalaryMax,
+			&job.Currency, &job.SalaryPeriod, &job.SalaryHidden, &job.Location,
+			&job.PlaceID, &job.Latitude, &job.Longitude,
 			&job.JobType, &job.WorkLocation, &job.Openings, &requiredSkills,
 			&job.CompanyID, &job.Status, &job.CreatedAt, &job.UpdatedAt,
 			&job.CompanyName, &job.Similarity,
@@ -104,6 +117,7 @@ func SemanticSearchJobs(c *gin.Context) {
 			}
 		}
 
+		hideSalary(&job)
 		jobs = append(jobs, job)
 	}
 
@@ -114,27 +128,11 @@ func SemanticSearchJobs(c *gin.Context) {
 	})
 }
 
-// Helper function to generate and save embedding for a job
-func generateJobEmbedding(jobID, title string) error {
+// Helper function to generate and save the embeddings of a job's title,
+// skills and description
+func generateJobEmbeddings(jobID string) error {
 	if embeddingService == nil {
 		return fmt.Errorf("embedding service not initialized")
 	}
-
-	// Generate embedding
-	embedding, err := embeddingService.GetEmbedding(title)
-	if err != nil {
-		return fmt.Errorf("failed to generate embedding: %w", err)
-	}
-
-	// Format embedding as string "[v1,v2,...]" for pgvector
-	vectorStr := "[" + strings.Trim(strings.Join(strings.Fields(fmt.Sprint(embedding)), ","), "[]") + "]"
-
-	// Update job with embedding using explicit cast
-	query := fmt.Sprintf("UPDATE jobs SET title_embedding = '%s'::vector WHERE id = $1", vectorStr)
-	_, err = config.DB.Exec(query, jobID)
-	if err != nil {
-		return fmt.Errorf("failed to save embedding: %w", err)
-	}
-
-	return nil
+	return embeddingService.EmbedJob(config.DB, jobID)
 }
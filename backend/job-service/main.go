@@ -3,14 +3,27 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/job-portal/job-service/config"
 	"github.com/job-portal/job-service/handlers"
 	"github.com/job-portal/job-service/middleware"
+	"github.com/job-portal/job-service/policy"
+	"github.com/job-portal/pkg/auth"
+	"github.com/job-portal/pkg/privacy"
+	"github.com/job-portal/pkg/ratelimit"
 	"github.com/joho/godotenv"
 )
 
+// Rate limit policies; admins can override them at runtime (see pkg/ratelimit)
+var rateLimitPolicies = map[string]ratelimit.Policy{
+	"job-service:default":         {Limit: 600, Period: "1m", Burst: 100, By: "ip"},
+	"job-service:semantic-search": {Limit: 30, Period: "1m", Burst: 10, By: "ip"}, // Calls the embedding server
+	"job-service:jobs-write":      {Limit: 300, Period: "1h", Burst: 30, By: "api_key"},
+	"job-service:recommendations": {Limit: 30, Period: "1m", Burst: 10, By: "user"}, // Calls the embedding server
+}
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load("../../.env"); err != nil {
@@ -21,18 +34,45 @@ func main() {
 	config.InitDB()
 	defer config.CloseDB()
 
+	// Initialize Redis connection (token revocation checks)
+	config.InitRedis()
+	defer config.CloseRedis()
+
+	// Load rate limit policies and admin overrides
+	ratelimit.Init(config.RedisClient, rateLimitPolicies)
+
 	// Initialize embedding service
 	handlers.InitEmbeddingService()
 
+	// Load the gazetteer used to geocode job and company locations
+	handlers.InitGeocoder()
+
+	// Verify tokens against auth-service's public signing keys
+	auth.Init(auth.Config{Service: "job-service", Redis: config.RedisClient})
+
 	// Set up Gin router
 	router := gin.Default()
 
+	// Only trust X-Forwarded-For from known proxies (by default the frontend
+	// server on a local or private network), otherwise clients could spoof the
+	// IP their rate limits are counted against
+	proxies := os.Getenv("TRUSTED_PROXIES")
+	if proxies == "" {
+		proxies = "127.0.0.1,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"
+	}
+	if err := router.SetTrustedProxies(strings.Split(proxies, ",")); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
 	// CORS middleware
 	router.Use(middleware.CORSMiddleware())
 
 	// Prometheus metrics middleware
 	router.Use(middleware.PrometheusMiddleware())
 
+	// Default per-IP rate limit; expensive routes add their own
+	router.Use(ratelimit.Limit("job-service:default"))
+
 	// Start metrics server on separate port
 	middleware.StartMetricsServer("9103")
 	log.Println("📊 Metrics server started on port 9103")
@@ -45,9 +85,10 @@ func main() {
 	// Public job routes (no auth required)
 	publicJobs := router.Group("/api/jobs")
 	{
-		publicJobs.GET("", handlers.SearchJobs)                  // Keyword search
-		publicJobs.GET("/semantic", handlers.SemanticSearchJobs) // Semantic search
-		publicJobs.GET("/:id", handlers.GetJobByID)              // Get job details
+		hybrid := func(c *gin.Context) bool { return c.Query("mode") == "hybrid" }
+		publicJobs.GET("", ratelimit.LimitWhen("job-service:semantic-search", hybrid), handlers.SearchJobs)      // Keyword or hybrid search
+		publicJobs.GET("/semantic", ratelimit.Limit("job-service:semantic-search"), handlers.SemanticSearchJobs) // Semantic search
+		publicJobs.GET("/:id", handlers.GetJobByID)                                                              // Get job details
 		publicJobs.GET("/company/:companyId", handlers.GetJobsByCompany)
 	}
 
@@ -58,47 +99,80 @@ func main() {
 		publicCompanies.GET("/all", handlers.GetAllCompanies)
 	}
 
+	// Job management (recruiters only). These also accept API keys with the
+	// matching scope, so they carry their own auth.Middleware.
+	jobs := router.Group("/api/jobs")
+	{
+		jobsWrite := auth.Middleware("jobs:write")
+		writeLimit := ratelimit.Limit("job-service:jobs-write")
+		jobs.POST("", jobsWrite, writeLimit, policy.Require(policy.JobCreate), handlers.CreateJob)
+		jobs.PUT("/:id", jobsWrite, writeLimit, policy.Require(policy.JobUpdate), handlers.UpdateJob)
+		jobs.DELETE("/:id", jobsWrite, writeLimit, auth.NotImpersonated(), policy.Require(policy.JobDelete), handlers.DeleteJob)
+		jobs.GET("/:id/applications", auth.Middleware("applications:read"), policy.Require(policy.JobApplicationsRead), handlers.GetJobApplications)
+	}
+
 	// Protected routes (require authentication)
-	auth := router.Group("/api")
-	auth.Use(middleware.AuthMiddleware())
+	protected := router.Group("/api")
+	protected.Use(auth.Middleware())
 	{
 		// Company management (recruiters only)
-		companies := auth.Group("/companies")
+		companies := protected.Group("/companies")
 		{
-			companies.GET("", handlers.GetCompanies) // List recruiter's companies
-			companies.POST("", middleware.RecruiterOnly(), handlers.CreateCompany)
-			companies.PUT("/:id", middleware.RecruiterOnly(), handlers.UpdateCompany)
-			companies.DELETE("/:id", middleware.RecruiterOnly(), handlers.DeleteCompany)
+			companies.GET("", handlers.GetCompanies) // List companies the recruiter is a member of
+			companies.POST("", policy.Require(policy.CompanyCreate), auth.VerifiedEmailOnly(), handlers.CreateCompany)
+			companies.PUT("/:id", policy.Require(policy.CompanyUpdate), handlers.UpdateCompany)
+			companies.DELETE("/:id", auth.NotImpersonated(), policy.Require(policy.CompanyDelete), handlers.DeleteCompany)
+
+			// Company team
+			membersRead := policy.Require(policy.CompanyMembersRead)
+			membersManage := policy.Require(policy.CompanyMembersManage)
+			companies.GET("/:id/members", membersRead, handlers.GetCompanyMembers)
+			companies.PUT("/:id/members/:userId", membersManage, handlers.UpdateCompanyMember)
+			companies.DELETE("/:id/members/:userId", auth.NotImpersonated(), membersRead, handlers.RemoveCompanyMember) // Manage, or leave
+			companies.GET("/:id/invitations", membersManage, handlers.GetCompanyInvitations)
+			companies.POST("/:id/invitations", membersManage, handlers.CreateCompanyInvitation)
+			companies.DELETE("/:id/invitations/:invitationId", membersManage, handlers.RevokeCompanyInvitation)
 		}
 
-		// Job management (recruiters only for create/update/delete)
-		jobs := auth.Group("/jobs")
+		// Invitations to join a company team, addressed to the caller's email
+		invitations := protected.Group("/company-invitations")
 		{
-			jobs.POST("", middleware.RecruiterOnly(), handlers.CreateJob)
-			jobs.PUT("/:id", middleware.RecruiterOnly(), handlers.UpdateJob)
-			jobs.DELETE("/:id", middleware.RecruiterOnly(), handlers.DeleteJob)
-			jobs.GET("/:id/applications", middleware.RecruiterOnly(), handlers.GetJobApplications)
+			join := policy.Require(policy.CompanyJoin)
+			invitations.GET("", join, handlers.GetMyCompanyInvitations)
+			invitations.POST("/:id/accept", join, auth.VerifiedEmailOnly(), handlers.AcceptCompanyInvitation)
+			invitations.DELETE("/:id", join, handlers.DeclineCompanyInvitation)
 		}
 
+		// Jobs recommended for the job seeker's profile
+		protected.GET("/jobs/recommended", ratelimit.Limit("job-service:recommendations"), policy.Require(policy.JobsRecommendedRead), handlers.GetRecommendedJobs)
+
 		// Application management
-		applications := auth.Group("/applications")
+		applications := protected.Group("/applications")
 		{
-			applications.POST("", handlers.ApplyToJob)          // Job seekers apply
-			applications.GET("/my", handlers.GetMyApplications) // Get user's applications
-			applications.PUT("/:id/status", middleware.RecruiterOnly(), handlers.UpdateApplicationStatus)
+			applications.POST("", policy.Require(policy.ApplicationCreate), auth.VerifiedEmailOnly(), handlers.ApplyToJob) // Job seekers apply
+			applications.GET("/my", handlers.GetMyApplications)                                                            // Get user's applications
+			applications.PUT("/:id/status", policy.Require(policy.ApplicationStatusUpdate), handlers.UpdateApplicationStatus)
 		}
 
 		// Admin routes (admin only)
-		admin := auth.Group("/admin")
-		admin.Use(middleware.AdminOnly())
+		admin := protected.Group("/admin")
 		{
-			admin.GET("/stats", handlers.GetAdminStats)
-			admin.GET("/recruiters", handlers.GetAdminRecruiters)
-			admin.GET("/companies", handlers.GetAdminCompanies)
-			admin.PUT("/companies/:id/assign", handlers.AssignCompanyToRecruiter)
+			dashboard := policy.Require(policy.AdminDashboardRead)
+			admin.GET("/stats", dashboard, handlers.GetAdminStats)
+			admin.GET("/recruiters", dashboard, handlers.GetAdminRecruiters)
+			admin.GET("/companies", dashboard, handlers.GetAdminCompanies)
+			admin.PUT("/companies/:id/assign", policy.Require(policy.CompanyAssign), handlers.AssignCompanyToRecruiter)
 		}
 	}
 
+	// Internal API for other services, on its own port
+	internal := auth.NewInternalRouter(middleware.PrometheusMiddleware())
+
+	// Data export and erasure for auth-service
+	privacy.Register(internal, handlers.PrivacyParticipant{})
+
+	auth.StartInternalServer(internal, "JOB_SERVICE_INTERNAL_PORT", "8103")
+
 	// Start server
 	port := os.Getenv("JOB_SERVICE_PORT")
 	if port == "" {
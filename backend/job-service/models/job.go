@@ -16,9 +16,15 @@ type Company struct {
 	CompanySize  string    `json:"company_size,omitempty" db:"company_size"`
 	FoundedYear  int       `json:"founded_year,omitempty" db:"founded_year"`
 	Headquarters string    `json:"headquarters,omitempty" db:"headquarters"`
+	PlaceID      string    `json:"place_id,omitempty" db:"place_id"` // Gazetteer place of the headquarters
+	Latitude     *float64  `json:"latitude,omitempty" db:"latitude"`
+	Longitude    *float64  `json:"longitude,omitempty" db:"longitude"`
 	Rating       float64   `json:"rating,omitempty" db:"rating"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+
+	// Joined fields (not in database)
+	MemberRole string `json:"member_role,omitempty" db:"member_role"` // Caller's role in the company team
 }
 
 type CreateCompanyRequest struct {
@@ -49,7 +55,15 @@ type Job struct {
 	Title          string    `json:"title" db:"title"`
 	Description    string    `json:"description" db:"description"` // Supports Markdown
 	Salary         string    `json:"salary,omitempty" db:"salary"`
+	SalaryMin      *float64  `json:"salary_min,omitempty" db:"salary_min"`
+	SalaryMax      *float64  `json:"salary_max,omitempty" db:"salary_max"`
+	Currency       string    `json:"currency,omitempty" db:"currency"`           // ISO 4217, e.g. USD, VND
+	SalaryPeriod   string    `json:"salary_period,omitempty" db:"salary_period"` // hour, month, year
+	SalaryHidden   bool      `json:"salary_hidden" db:"salary_hidden"`           // Pay is left out of public listings
 	Location       string    `json:"location" db:"location"`
+	PlaceID        string    `json:"place_id,omitempty" db:"place_id"` // Gazetteer place the location resolves to
+	Latitude       *float64  `json:"latitude,omitempty" db:"latitude"`
+	Longitude      *float64  `json:"longitude,omitempty" db:"longitude"`
 	JobType        string    `json:"job_type" db:"job_type"`           // full-time, part-time, contract, internship
 	WorkLocation   string    `json:"work_location" db:"work_location"` // remote, onsite, hybrid
 	Openings       int       `json:"openings" db:"openings"`
@@ -62,14 +76,55 @@ type Job struct {
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 
 	// Joined fields (not in database)
-	CompanyName string  `json:"company_name,omitempty" db:"company_name"`
-	Similarity  float32 `json:"similarity,omitempty" db:"similarity"` // For search results
+	CompanyName string   `json:"company_name,omitempty" db:"company_name"`
+	Similarity  float32  `json:"similarity,omitempty" db:"similarity"`   // For search results
+	Rank        float64  `json:"rank,omitempty" db:"rank"`               // Keyword or hybrid search relevance
+	Snippet     string   `json:"snippet,omitempty" db:"snippet"`         // Description excerpt with matches in <mark>
+	DistanceKm  *float64 `json:"distance_km,omitempty" db:"distance_km"` // From the point of a radius search
+
+	Explanation    *SearchExplanation `json:"explanation,omitempty"`    // How a hybrid search ranked the job
+	Recommendation *Recommendation    `json:"recommendation,omitempty"` // Why the job was recommended
+}
+
+// Recommendation is why a job was recommended to a job seeker
+type Recommendation struct {
+	Score         float64  `json:"score"`          // 0.7 × similarity + 0.3 × skill_coverage
+	Similarity    float64  `json:"similarity"`     // Cosine similarity of the job to the user's profile
+	MatchedSkills []string `json:"matched_skills"` // Required skills the user has
+	SkillCoverage float64  `json:"skill_coverage"` // Share of the required skills the user has, 0 to 1
+	Reasons       []string `json:"reasons"`        // Human-readable, strongest first
+}
+
+// SearchExplanation breaks a hybrid search result's rank down into the
+// contributions of the keyword and vector retrievers
+type SearchExplanation struct {
+	Keyword *RetrieverMatch `json:"keyword,omitempty"` // Absent if the job didn't match the keyword
+	Vector  *RetrieverMatch `json:"vector,omitempty"`  // Absent if the job wasn't similar enough
+}
+
+// RetrieverMatch is where one retriever ranked a job
+type RetrieverMatch struct {
+	Position     int     `json:"position"`     // 1-based, among every job the retriever matched
+	Score        float64 `json:"score"`        // Full-text rank or cosine similarity
+	Contribution float64 `json:"contribution"` // Added to the job's rank: 1 / (60 + position)
+}
+
+// Facet is one value of a search filter and how many matching jobs have it
+type Facet struct {
+	Value string `json:"value"` // What to pass back as the filter parameter
+	Label string `json:"label"`
+	Count int    `json:"count"`
 }
 
 type CreateJobRequest struct {
 	Title          string   `json:"title" binding:"required"`
 	Description    string   `json:"description" binding:"required"` // Markdown format
-	Salary         string   `json:"salary"`
+	Salary         string   `json:"salary"`                         // Parsed into the range when that is not given
+	SalaryMin      *float64 `json:"salary_min" binding:"omitempty,gte=0"`
+	SalaryMax      *float64 `json:"salary_max" binding:"omitempty,gte=0"`
+	Currency       string   `json:"currency" binding:"omitempty,len=3"`
+	SalaryPeriod   string   `json:"salary_period" binding:"omitempty,oneof=hour month year"`
+	SalaryHidden   bool     `json:"salary_hidden"`
 	Location       string   `json:"location" binding:"required"`
 	JobType        string   `json:"job_type" binding:"required,oneof=full-time part-time contract internship"`
 	WorkLocation   string   `json:"work_location" binding:"required,oneof=remote onsite hybrid"`
@@ -82,6 +137,11 @@ type UpdateJobRequest struct {
 	Title          string   `json:"title"`
 	Description    string   `json:"description"`
 	Salary         string   `json:"salary"`
+	SalaryMin      *float64 `json:"salary_min" binding:"omitempty,gte=0"`
+	SalaryMax      *float64 `json:"salary_max" binding:"omitempty,gte=0"`
+	Currency       string   `json:"currency" binding:"omitempty,len=3"`
+	SalaryPeriod   string   `json:"salary_period" binding:"omitempty,oneof=hour month year"`
+	SalaryHidden   *bool    `json:"salary_hidden"`
 	Location       string   `json:"location"`
 	JobType        string   `json:"job_type" binding:"omitempty,oneof=full-time part-time contract internship"`
 	WorkLocation   string   `json:"work_location" binding:"omitempty,oneof=remote onsite hybrid"`
@@ -120,3 +180,38 @@ type ApplyJobRequest struct {
 type UpdateApplicationStatusRequest struct {
 	Status string `json:"status" binding:"required,oneof=pending viewed shortlisted interviewed offered rejected"`
 }
+
+// CompanyMember is a recruiter on a company's team
+type CompanyMember struct {
+	UserID    string    `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	Email     string    `json:"email" db:"email"`
+	Role      string    `json:"role" db:"role"` // owner, admin, recruiter, viewer
+	AddedBy   string    `json:"added_by,omitempty" db:"added_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CompanyInvitation invites an email address to join a company's team
+type CompanyInvitation struct {
+	ID        string    `json:"id" db:"id"`
+	CompanyID string    `json:"company_id" db:"company_id"`
+	Email     string    `json:"email" db:"email"`
+	Role      string    `json:"role" db:"role"`
+	InvitedBy string    `json:"invited_by,omitempty" db:"invited_by"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// Joined fields (not in database)
+	CompanyName   string `json:"company_name,omitempty" db:"company_name"`
+	InvitedByName string `json:"invited_by_name,omitempty" db:"invited_by_name"`
+}
+
+type InviteCompanyMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner admin recruiter viewer"`
+}
+
+type UpdateCompanyMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin recruiter viewer"`
+}
@@ -0,0 +1,177 @@
+package policy
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/pkg/policy"
+)
+
+// Authorization is declared here rather than in handlers: each role is granted
+// a set of permissions, either on any resource or only within the companies the
+// caller belongs to. Routes check that the caller's role holds a permission at
+// all with Require; handlers check the caller's role in the company once they
+// have loaded the resource with Authorize. Adding a role such as moderator
+// means adding an entry to Default; what each member of a company may do is
+// declared in CompanyRoles. The evaluation itself is shared (pkg/policy).
+
+// Permission names an action on a kind of resource
+type Permission = policy.Permission
+
+// Permissions checked by job-service
+const (
+	JobCreate               Permission = "job.create"
+	JobUpdate               Permission = "job.update"
+	JobDelete               Permission = "job.delete"
+	JobApplicationsRead     Permission = "job.applications.read"
+	JobsRecommendedRead     Permission = "job.recommended.read"
+	CompanyCreate           Permission = "company.create"
+	CompanyUpdate           Permission = "company.update"
+	CompanyDelete           Permission = "company.delete"
+	CompanyAssign           Permission = "company.assign"
+	CompanyMembersRead      Permission = "company.members.read"
+	CompanyMembersManage    Permission = "company.members.manage"
+	CompanyJoin             Permission = "company.join"
+	ApplicationCreate       Permission = "application.create"
+	ApplicationStatusUpdate Permission = "application.status.update"
+	AdminDashboardRead      Permission = "admin.dashboard.read"
+)
+
+// Grant scopes: Own covers only resources of companies the caller is a
+// member of, as far as their role in the company allows
+const (
+	Own = policy.Own
+	Any = policy.Any
+)
+
+// Default is the policy every handler is checked against
+var Default = &policy.Policy{
+	Grants: policy.Grants{
+		"recruiter": {
+			JobCreate:               Own,
+			JobUpdate:               Own,
+			JobDelete:               Own,
+			JobApplicationsRead:     Own,
+			CompanyCreate:           Own,
+			CompanyUpdate:           Own,
+			CompanyDelete:           Own,
+			CompanyMembersRead:      Own,
+			CompanyMembersManage:    Own,
+			CompanyJoin:             Own,
+			ApplicationStatusUpdate: Own,
+		},
+		"jobseeker": {
+			JobsRecommendedRead: Any,
+			ApplicationCreate:   Any,
+		},
+		"admin": {
+			CompanyAssign:      Any,
+			AdminDashboardRead: Any,
+		},
+	},
+	Explain: explain,
+}
+
+// Roles a member can hold in a company
+const (
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin"
+	RoleRecruiter = "recruiter"
+	RoleViewer    = "viewer"
+)
+
+// CompanyRoles maps each company role to the permissions it holds within the company
+var CompanyRoles = map[string]map[Permission]bool{
+	RoleOwner: {
+		JobCreate: true, JobUpdate: true, JobDelete: true, JobApplicationsRead: true,
+		CompanyUpdate: true, CompanyDelete: true, CompanyMembersRead: true, CompanyMembersManage: true,
+		ApplicationStatusUpdate: true,
+	},
+	RoleAdmin: {
+		JobCreate: true, JobUpdate: true, JobDelete: true, JobApplicationsRead: true,
+		CompanyUpdate: true, CompanyMembersRead: true, CompanyMembersManage: true,
+		ApplicationStatusUpdate: true,
+	},
+	RoleRecruiter: {
+		JobCreate: true, JobUpdate: true, JobDelete: true, JobApplicationsRead: true,
+		CompanyMembersRead: true, ApplicationStatusUpdate: true,
+	},
+	RoleViewer: {
+		JobApplicationsRead: true, CompanyMembersRead: true,
+	},
+}
+
+// ValidCompanyRole reports whether role is a company role
+func ValidCompanyRole(role string) bool {
+	_, ok := CompanyRoles[role]
+	return ok
+}
+
+// CanManageMember reports whether a member with actorRole may add, change or
+// remove a member holding role. Owners manage everyone; admins manage
+// recruiters and viewers only, so they can't promote themselves.
+func CanManageMember(actorRole, role string) bool {
+	switch actorRole {
+	case RoleOwner:
+		return true
+	case RoleAdmin:
+		return role == RoleRecruiter || role == RoleViewer
+	}
+	return false
+}
+
+// Reasons a company resource isn't covered by an Own grant
+const (
+	// NotMember: the caller isn't a member of the company
+	NotMember policy.Reason = "not_member"
+	// RoleTooLow: the caller is a member but their company role lacks the permission
+	RoleTooLow policy.Reason = "role_too_low"
+)
+
+// Resource is a company's job, application or team the caller wants to act on.
+// CompanyRole is the caller's role in the company, empty if they aren't a member.
+type Resource struct {
+	CompanyRole string
+}
+
+func (r Resource) Covers(_ policy.Subject, perm Permission) policy.Decision {
+	if r.CompanyRole == "" {
+		return policy.Decision{Reason: NotMember}
+	}
+	if CompanyRoles[r.CompanyRole][perm] {
+		return policy.Decision{Allowed: true}
+	}
+	return policy.Decision{Reason: RoleTooLow}
+}
+
+// notMemberMessages explain membership denials in terms the caller will recognise
+var notMemberMessages = map[Permission]string{
+	JobCreate:               "You can only post jobs for companies you are a member of",
+	JobUpdate:               "You can only update jobs of companies you are a member of",
+	JobDelete:               "You can only delete jobs of companies you are a member of",
+	JobApplicationsRead:     "You can only view applications for jobs of companies you are a member of",
+	CompanyUpdate:           "You can only update companies you are a member of",
+	CompanyDelete:           "You can only delete companies you are a member of",
+	CompanyMembersRead:      "You can only view the team of companies you are a member of",
+	CompanyMembersManage:    "You can only manage the team of companies you are a member of",
+	ApplicationStatusUpdate: "You can only update applications for jobs of companies you are a member of",
+}
+
+func explain(perm Permission, reason policy.Reason) string {
+	switch reason {
+	case NotMember:
+		return notMemberMessages[perm]
+	case RoleTooLow:
+		return "Your role in this company does not allow this action"
+	}
+	return ""
+}
+
+// Require middleware ensures the caller's role holds perm
+func Require(perm Permission) gin.HandlerFunc {
+	return Default.Require(perm)
+}
+
+// Authorize checks perm against a company resource, given the caller's role in
+// the company, responding with 403 and returning false if the caller may not perform it
+func Authorize(c *gin.Context, perm Permission, companyRole string) bool {
+	return Default.Authorize(c, perm, Resource{CompanyRole: companyRole})
+}
@@ -0,0 +1,148 @@
+package policy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/pkg/policy"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestEvaluateCompanyRoles(t *testing.T) {
+	recruiter := policy.Subject{UserID: "u1", Role: "recruiter"}
+	tests := []struct {
+		companyRole string
+		perm        Permission
+		want        policy.Decision
+	}{
+		{"", JobCreate, policy.Decision{Reason: NotMember}},
+		{RoleOwner, CompanyDelete, policy.Decision{Allowed: true}},
+		{RoleOwner, CompanyMembersManage, policy.Decision{Allowed: true}},
+		{RoleAdmin, CompanyMembersManage, policy.Decision{Allowed: true}},
+		{RoleAdmin, CompanyDelete, policy.Decision{Reason: RoleTooLow}},
+		{RoleRecruiter, JobUpdate, policy.Decision{Allowed: true}},
+		{RoleRecruiter, CompanyUpdate, policy.Decision{Reason: RoleTooLow}},
+		{RoleRecruiter, CompanyMembersManage, policy.Decision{Reason: RoleTooLow}},
+		{RoleViewer, JobApplicationsRead, policy.Decision{Allowed: true}},
+		{RoleViewer, JobCreate, policy.Decision{Reason: RoleTooLow}},
+		{RoleViewer, ApplicationStatusUpdate, policy.Decision{Reason: RoleTooLow}},
+	}
+	for _, tt := range tests {
+		got := Default.Evaluate(recruiter, tt.perm, Resource{CompanyRole: tt.companyRole})
+		if got != tt.want {
+			t.Errorf("company role %q %s: got %+v, want %+v", tt.companyRole, tt.perm, got, tt.want)
+		}
+	}
+
+	// Any-scoped grants don't depend on membership
+	admin := policy.Subject{UserID: "u2", Role: "admin"}
+	if got := Default.Evaluate(admin, CompanyAssign, Resource{}); !got.Allowed {
+		t.Errorf("admin %s outside the company: got %+v, want allowed", CompanyAssign, got)
+	}
+	// Nor does a company role grant what the user role lacks
+	jobseeker := policy.Subject{UserID: "u3", Role: "jobseeker"}
+	if got := Default.Evaluate(jobseeker, JobCreate, Resource{CompanyRole: RoleOwner}); got != (policy.Decision{}) {
+		t.Errorf("jobseeker owner %s: got %+v, want denied", JobCreate, got)
+	}
+}
+
+func TestCanManageMember(t *testing.T) {
+	tests := []struct {
+		actor, role string
+		want        bool
+	}{
+		{RoleOwner, RoleOwner, true},
+		{RoleOwner, RoleAdmin, true},
+		{RoleAdmin, RoleAdmin, false},
+		{RoleAdmin, RoleOwner, false},
+		{RoleAdmin, RoleRecruiter, true},
+		{RoleAdmin, RoleViewer, true},
+		{RoleRecruiter, RoleViewer, false},
+		{RoleViewer, RoleViewer, false},
+	}
+	for _, tt := range tests {
+		if got := CanManageMember(tt.actor, tt.role); got != tt.want {
+			t.Errorf("CanManageMember(%q, %q) = %v, want %v", tt.actor, tt.role, got, tt.want)
+		}
+	}
+}
+
+// callerContext is a request context as auth.Middleware leaves it
+func callerContext(values map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	for k, v := range values {
+		c.Set(k, v)
+	}
+	return c, w
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller map[string]string
+		perm   Permission
+		status int
+	}{
+		{"recruiter", map[string]string{"user_id": "u1", "user_role": "recruiter"}, JobCreate, http.StatusOK},
+		{"jobseeker", map[string]string{"user_id": "u1", "user_role": "jobseeker"}, JobCreate, http.StatusForbidden},
+		{"admin", map[string]string{"user_id": "a1", "user_role": "admin"}, CompanyAssign, http.StatusOK},
+		{"admin posting a job", map[string]string{"user_id": "a1", "user_role": "admin"}, JobCreate, http.StatusForbidden},
+		{"recruiter assigning a company", map[string]string{"user_id": "u1", "user_role": "recruiter"}, CompanyAssign, http.StatusForbidden},
+		// An impersonation token carries the impersonated user's role, so an
+		// admin acting as a recruiter has the recruiter's permissions only
+		{"admin impersonating recruiter", map[string]string{"user_id": "u1", "user_role": "recruiter", "actor_id": "a1"}, JobCreate, http.StatusOK},
+		{"admin impersonating recruiter, admin permission", map[string]string{"user_id": "u1", "user_role": "recruiter", "actor_id": "a1"}, AdminDashboardRead, http.StatusForbidden},
+		// API keys act with their owner's role; a scope doesn't add permissions
+		{"API key of a recruiter", map[string]string{"user_id": "u1", "user_role": "recruiter", "api_key_id": "k1"}, JobCreate, http.StatusOK},
+		{"API key of a jobseeker", map[string]string{"user_id": "u1", "user_role": "jobseeker", "api_key_id": "k1"}, JobCreate, http.StatusForbidden},
+		{"jobseeker recommendations", map[string]string{"user_id": "u1", "user_role": "jobseeker"}, JobsRecommendedRead, http.StatusOK},
+		{"recruiter recommendations", map[string]string{"user_id": "u1", "user_role": "recruiter"}, JobsRecommendedRead, http.StatusForbidden},
+		{"jobseeker applying", map[string]string{"user_id": "u1", "user_role": "jobseeker"}, ApplicationCreate, http.StatusOK},
+		{"recruiter applying", map[string]string{"user_id": "u1", "user_role": "recruiter"}, ApplicationCreate, http.StatusForbidden},
+		{"admin applying", map[string]string{"user_id": "a1", "user_role": "admin"}, ApplicationCreate, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		c, w := callerContext(tt.caller)
+		Require(tt.perm)(c)
+		status := http.StatusOK
+		if c.IsAborted() {
+			status = w.Code
+		}
+		if status != tt.status {
+			t.Errorf("%s: %s responded %d, want %d", tt.name, tt.perm, status, tt.status)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        string
+		companyRole string
+		perm        Permission
+		allowed     bool
+		message     string
+	}{
+		{"member", "recruiter", RoleRecruiter, JobUpdate, true, ""},
+		{"not a member", "recruiter", "", JobUpdate, false, notMemberMessages[JobUpdate]},
+		{"role too low", "recruiter", RoleViewer, JobUpdate, false, "Your role in this company does not allow this action"},
+		{"no grant", "jobseeker", RoleOwner, JobUpdate, false, "You do not have permission to perform this action"},
+	}
+	for _, tt := range tests {
+		c, w := callerContext(map[string]string{"user_id": "u1", "user_role": tt.role})
+		if got := Authorize(c, tt.perm, tt.companyRole); got != tt.allowed {
+			t.Errorf("%s: Authorize = %v, want %v", tt.name, got, tt.allowed)
+		}
+		if tt.message != "" && !strings.Contains(w.Body.String(), `"`+tt.message+`"`) {
+			t.Errorf("%s: body %s, want error %q", tt.name, w.Body.String(), tt.message)
+		}
+	}
+}
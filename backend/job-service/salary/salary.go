@@ -0,0 +1,196 @@
+package salary
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Salaries are stored as a range in one currency per hour, month or year. Parse
+// reads the free-form strings recruiters used to type, e.g. "$80k-100k/yr",
+// "20-30 triệu VND" or "Up to €4,500 per month", so old jobs can be backfilled
+// and jobs posted with only a salary string still get a structured range.
+
+// Periods a salary can be quoted in
+const (
+	Hour  = "hour"
+	Month = "month"
+	Year  = "year"
+)
+
+// Hours and months per year used to compare salaries quoted per different periods
+const (
+	HoursPerYear  = 2080
+	MonthsPerYear = 12
+)
+
+// Range is a parsed salary. Min or Max is nil when the string gave only one bound.
+type Range struct {
+	Min      *float64
+	Max      *float64
+	Currency string // ISO 4217 code, "" if the string didn't say
+	Period   string
+}
+
+var (
+	// A number, optionally followed by a magnitude suffix ("80k", "1.5m", "30 triệu"),
+	// and not by other letters except a "đ" sign ("25.000.000đ")
+	amountPattern = regexp.MustCompile(`(\d+(?:[.,]\d+)*)\s*(million|triệu|trieu|mil|tr|tỷ|ty|bn|k|m|b)?(?:[^\p{L}]|đ|$)`)
+
+	currencies = []struct {
+		code    string
+		pattern *regexp.Regexp
+	}{
+		{"VND", regexp.MustCompile(`vnd|vnđ|₫`)},
+		// Before USD, whose "$" would match "S$"; "US$" is not SGD
+		{"SGD", regexp.MustCompile(`sgd|(?:^|[^\p{L}])s\$`)},
+		{"USD", regexp.MustCompile(`usd|us\$|\$`)},
+		{"EUR", regexp.MustCompile(`eur|€`)},
+		{"GBP", regexp.MustCompile(`gbp|£`)},
+		{"JPY", regexp.MustCompile(`jpy|¥|yen`)},
+		// Last, as Vietnamese text quoting another currency has these too
+		// ("Tối đa 2000 USD"); "đ" only counts right after an amount
+		{"VND", regexp.MustCompile(`\d\s*đ(?:[^\p{L}]|$)|triệu|trieu|tỷ`)},
+	}
+
+	periods = []struct {
+		period  string
+		pattern *regexp.Regexp
+	}{
+		{Hour, regexp.MustCompile(`/\s*h(ou)?r?\b|per hour|hourly|an hour|/giờ|giờ`)},
+		{Month, regexp.MustCompile(`/\s*mo(nth)?\b|per month|monthly|a month|/tháng|tháng`)},
+		{Year, regexp.MustCompile(`/\s*y(ea)?r\b|per year|per annum|p\.a\.|yearly|annual|a year|/năm|năm`)},
+	}
+
+	upTo = regexp.MustCompile(`up to|upto|max|tối đa|toi da|under|<`)
+	from = regexp.MustCompile(`from|starting|min|từ|\+|>`)
+)
+
+var vndSuffixes = map[string]bool{"triệu": true, "trieu": true, "tr": true, "tỷ": true, "ty": true}
+
+var multipliers = map[string]float64{
+	"k": 1e3, "m": 1e6, "mil": 1e6, "million": 1e6, "triệu": 1e6, "trieu": 1e6, "tr": 1e6,
+	"b": 1e9, "bn": 1e9, "tỷ": 1e9, "ty": 1e9,
+}
+
+// Parse reads a free-form salary. It returns false for strings without an
+// amount, such as "Negotiable" or "Competitive".
+func Parse(s string) (Range, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	matches := amountPattern.FindAllStringSubmatch(s, 2)
+	if len(matches) == 0 {
+		return Range{}, false
+	}
+
+	var amounts []float64
+	var suffixes []string
+	for _, m := range matches {
+		n, ok := parseNumber(m[1])
+		if !ok {
+			return Range{}, false
+		}
+		amounts = append(amounts, n)
+		suffixes = append(suffixes, m[2])
+	}
+	// "20-30 triệu": a bare lower bound takes the upper bound's magnitude
+	if len(amounts) == 2 && suffixes[0] == "" {
+		suffixes[0] = suffixes[1]
+	}
+	for i := range amounts {
+		if mult, ok := multipliers[suffixes[i]]; ok {
+			amounts[i] *= mult
+		}
+	}
+
+	r := Range{}
+	for _, c := range currencies {
+		if c.pattern.MatchString(s) {
+			r.Currency = c.code
+			break
+		}
+	}
+	// "10-15tr": the magnitudes are Vietnamese
+	if r.Currency == "" && vndSuffixes[suffixes[len(suffixes)-1]] {
+		r.Currency = "VND"
+	}
+
+	switch {
+	case len(amounts) == 2:
+		lo, hi := amounts[0], amounts[1]
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		r.Min, r.Max = &lo, &hi
+	case upTo.MatchString(s):
+		r.Max = &amounts[0]
+	case from.MatchString(s):
+		r.Min = &amounts[0]
+	default:
+		r.Min, r.Max = &amounts[0], &amounts[0]
+	}
+
+	for _, p := range periods {
+		if p.pattern.MatchString(s) {
+			r.Period = p.period
+			break
+		}
+	}
+	if r.Period == "" {
+		r.Period = defaultPeriod(r)
+	}
+
+	return r, true
+}
+
+// defaultPeriod guesses the period of a salary that doesn't state one:
+// Vietnamese salaries are quoted per month, small amounts are hourly rates
+// and anything else is taken as yearly
+func defaultPeriod(r Range) string {
+	amount := r.Max
+	if amount == nil {
+		amount = r.Min
+	}
+	switch {
+	case r.Currency == "VND":
+		return Month
+	case *amount < 500:
+		return Hour
+	default:
+		return Year
+	}
+}
+
+// parseNumber reads "1,500", "20.000.000", "1.5" or "4,5". A separator followed
+// by exactly three digits in every group is a thousands separator; otherwise
+// the last separator is the decimal point.
+func parseNumber(s string) (float64, bool) {
+	groups := strings.FieldsFunc(s, func(r rune) bool { return r == '.' || r == ',' })
+	thousands := len(groups) > 1
+	for _, g := range groups[1:] {
+		if len(g) != 3 {
+			thousands = false
+		}
+	}
+
+	if thousands {
+		s = strings.Join(groups, "")
+	} else if i := strings.LastIndexAny(s, ".,"); i >= 0 {
+		s = strings.NewReplacer(".", "", ",", "").Replace(s[:i]) + "." + s[i+1:]
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	return n, err == nil
+}
+
+// PerYear annualizes an amount quoted per period
+func PerYear(amount float64, period string) float64 {
+	switch period {
+	case Hour:
+		return amount * HoursPerYear
+	case Month:
+		return amount * MonthsPerYear
+	default:
+		return amount
+	}
+}
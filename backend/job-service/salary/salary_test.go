@@ -0,0 +1,81 @@
+package salary
+
+import (
+	"fmt"
+	"testing"
+)
+
+func amount(n float64) *float64 { return &n }
+
+// format prints a bound for error messages, "-" when it is missing
+func format(n *float64) string {
+	if n == nil {
+		return "-"
+	}
+	return fmt.Sprint(*n)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in       string
+		min, max *float64
+		currency string
+		period   string
+	}{
+		{"$80k-100k/yr", amount(80000), amount(100000), "USD", Year},
+		{"20-30 triệu VND", amount(20e6), amount(30e6), "VND", Month},
+		{"Up to €4,500 per month", nil, amount(4500), "EUR", Month},
+		{"S$5,000/month", amount(5000), amount(5000), "SGD", Month},
+		{"SGD 6k - 8k monthly", amount(6000), amount(8000), "SGD", Month},
+		{"US$5,000/month", amount(5000), amount(5000), "USD", Month},
+		{"From 1.500 EUR", amount(1500), nil, "EUR", Year},
+		{"10-15tr", amount(10e6), amount(15e6), "VND", Month},
+		{"25.000.000đ", amount(25e6), amount(25e6), "VND", Month},
+		{"Đến 30.000.000 đ/tháng", amount(30e6), amount(30e6), "VND", Month},
+		{"Tối đa 2000 USD", nil, amount(2000), "USD", Year},
+		{"Đãi ngộ tốt, 1.500 EUR/tháng", amount(1500), amount(1500), "EUR", Month},
+		{"Lương đến 50 triệu", amount(50e6), amount(50e6), "VND", Month},
+		{"$25/hr", amount(25), amount(25), "USD", Hour},
+		{"£40,000 - £50,000", amount(40000), amount(50000), "GBP", Year},
+		{"45", amount(45), amount(45), "", Hour},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.in)
+		if !ok {
+			t.Errorf("Parse(%q) failed", tt.in)
+			continue
+		}
+		if format(got.Min) != format(tt.min) || format(got.Max) != format(tt.max) ||
+			got.Currency != tt.currency || got.Period != tt.period {
+			t.Errorf("Parse(%q) = %s-%s %q per %s, want %s-%s %q per %s", tt.in,
+				format(got.Min), format(got.Max), got.Currency, got.Period,
+				format(tt.min), format(tt.max), tt.currency, tt.period)
+		}
+	}
+
+	for _, in := range []string{"Negotiable", "Competitive", ""} {
+		if _, ok := Parse(in); ok {
+			t.Errorf("Parse(%q) succeeded, want no amount", in)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"1,500", 1500},
+		{"20.000.000", 20e6},
+		{"1.5", 1.5},
+		{"4,5", 4.5},
+		{"1,234.56", 1234.56},
+		{"1.234,56", 1234.56},
+		{"80", 80},
+	}
+	for _, tt := range tests {
+		if got, ok := parseNumber(tt.in); !ok || got != tt.want {
+			t.Errorf("parseNumber(%q) = %v, %v, want %v", tt.in, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/job-portal/job-service/config"
+	"github.com/job-portal/job-service/salary"
+	"github.com/joho/godotenv"
+)
+
+// Parses the free-form salary of every job that has no structured range yet
+// (migration 022_add_structured_salaries.sql). Safe to run again.
+func main() {
+	// Load environment variables
+	if err := godotenv.Load("../../.env"); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	// Initialize database connection
+	config.InitDB()
+	defer config.CloseDB()
+
+	rows, err := config.DB.Query(`
+		SELECT id, salary
+		FROM jobs
+		WHERE salary_min IS NULL AND salary_max IS NULL
+		  AND salary IS NOT NULL AND salary <> ''
+	`)
+	if err != nil {
+		log.Fatalf("Failed to query jobs: %v", err)
+	}
+
+	type Job struct {
+		ID     string
+		Salary string
+	}
+
+	var jobs []Job
+	for rows.Next() {
+		var job Job
+		if err := rows.Scan(&job.ID, &job.Salary); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	rows.Close()
+
+	log.Printf("📊 Found %d jobs with unparsed salaries\n", len(jobs))
+
+	parsed, skipped, failed := 0, 0, 0
+	for i, job := range jobs {
+		r, ok := salary.Parse(job.Salary)
+		if !ok {
+			log.Printf("⏭️  [%d/%d] No amount in %q", i+1, len(jobs), job.Salary)
+			skipped++
+			continue
+		}
+
+		var currency interface{}
+		if r.Currency != "" {
+			currency = r.Currency
+		}
+		_, err := config.DB.Exec(`
+			UPDATE jobs SET salary_min = $1, salary_max = $2, currency = $3, salary_period = $4
+			WHERE id = $5
+		`, r.Min, r.Max, currency, r.Period, job.ID)
+		if err != nil {
+			log.Printf("❌ [%d/%d] Failed to save %q: %v", i+1, len(jobs), job.Salary, err)
+			failed++
+			continue
+		}
+
+		parsed++
+		log.Printf("✅ [%d/%d] %q", i+1, len(jobs), job.Salary)
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 50))
+	log.Printf("🎉 Salary backfill complete!")
+	log.Printf("   Parsed:  %d", parsed)
+	log.Printf("   Skipped: %d", skipped)
+	log.Printf("   Failed:  %d", failed)
+	fmt.Println(strings.Repeat("=", 50))
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"strings"
@@ -10,7 +11,13 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// Embeds the title, skills and description chunks of every job that has none
+// yet (migration 024_add_job_chunk_embeddings.sql); -all re-embeds every job,
+// e.g. after changing how jobs are chunked.
 func main() {
+	all := flag.Bool("all", false, "re-embed jobs that already have embeddings")
+	flag.Parse()
+
 	// Load environment variables
 	if err := godotenv.Load("../../.env"); err != nil {
 		log.Println("No .env file found, using system environment variables")
@@ -29,11 +36,11 @@ func main() {
 
 	// Fetch all jobs without embeddings
 	rows, err := config.DB.Query(`
-		SELECT id, title 
-		FROM jobs 
-		WHERE title_embedding IS NULL
-		ORDER BY created_at DESC
-	`)
+		SELECT j.id, j.title
+		FROM jobs j
+		WHERE $1 OR NOT EXISTS (SELECT 1 FROM job_embeddings e WHERE e.job_id = j.id)
+		ORDER BY j.created_at DESC
+	`, *all)
 	if err != nil {
 		log.Fatalf("Failed to query jobs: %v", err)
 	}
@@ -66,26 +73,9 @@ func main() {
 	failCount := 0
 
 	for i, job := range jobs {
-		// Generate embedding
-		embedding, err := embeddingService.GetEmbedding(job.Title)
-		if err != nil {
-			log.Printf("❌ [%d/%d] Failed to generate embedding for '%s': %v",
-				i+1, len(jobs), job.Title, err)
-			failCount++
-			continue
-		}
-
-		// Format embedding as string "[v1,v2,...]" for pgvector
-		vectorStr := "[" + strings.Trim(strings.Join(strings.Fields(fmt.Sprint(embedding)), ","), "[]") + "]"
-
-		// Update database
-		_, err = config.DB.Exec(
-			"UPDATE jobs SET title_embedding = $1::vector WHERE id = $2",
-			vectorStr,
-			job.ID,
-		)
-		if err != nil {
-			log.Printf("❌ [%d/%d] Failed to save embedding for '%s': %v",
+		// Generate and save the job's chunk embeddings
+		if err := embeddingService.EmbedJob(config.DB, job.ID); err != nil {
+			log.Printf("❌ [%d/%d] Failed to embed '%s': %v",
 				i+1, len(jobs), job.Title, err)
 			failCount++
 			continue
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/job-portal/job-service/config"
+	"github.com/job-portal/job-service/geo"
+	"github.com/joho/godotenv"
+)
+
+// Geocodes the location of every job and the headquarters of every company
+// that has no place yet (migration 023_add_job_places.sql). Safe to run again,
+// e.g. after switching GAZETTEER_PATH to a larger gazetteer.
+func main() {
+	// Load environment variables
+	if err := godotenv.Load("../../.env"); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+
+	var gazetteer *geo.Gazetteer
+	var err error
+	if path := os.Getenv("GAZETTEER_PATH"); path != "" {
+		gazetteer, err = geo.OpenGazetteer(path)
+	} else {
+		gazetteer, err = geo.BundledGazetteer()
+	}
+	if err != nil {
+		log.Fatalf("Failed to load gazetteer: %v", err)
+	}
+
+	// Initialize database connection
+	config.InitDB()
+	defer config.CloseDB()
+
+	fmt.Println(strings.Repeat("=", 50))
+	geocode(gazetteer, "jobs", "location")
+	geocode(gazetteer, "companies", "headquarters")
+	fmt.Println(strings.Repeat("=", 50))
+}
+
+// geocode resolves the distinct values of a table's location column
+func geocode(gazetteer *geo.Gazetteer, table, column string) {
+	rows, err := config.DB.Query(fmt.Sprintf(`
+		SELECT DISTINCT %[1]s
+		FROM %[2]s
+		WHERE place_id IS NULL AND %[1]s IS NOT NULL AND %[1]s <> ''
+	`, column, table))
+	if err != nil {
+		log.Fatalf("Failed to query %s: %v", table, err)
+	}
+
+	var locations []string
+	for rows.Next() {
+		var location string
+		if err := rows.Scan(&location); err != nil {
+			log.Printf("Scan error: %v", err)
+			continue
+		}
+		locations = append(locations, location)
+	}
+	rows.Close()
+
+	log.Printf("📊 Found %d distinct %s values of %s without a place\n", len(locations), column, table)
+
+	resolved, unknown, failed := 0, 0, 0
+	for i, location := range locations {
+		place, err := gazetteer.Geocode(location)
+		if err != nil {
+			log.Printf("⏭️  [%d/%d] No place for %q", i+1, len(locations), location)
+			unknown++
+			continue
+		}
+
+		result, err := config.DB.Exec(fmt.Sprintf(`
+			UPDATE %s SET place_id = $1, latitude = $2, longitude = $3
+			WHERE %s = $4 AND place_id IS NULL
+		`, table, column), place.ID, place.Latitude, place.Longitude, location)
+		if err != nil {
+			log.Printf("❌ [%d/%d] Failed to save %q: %v", i+1, len(locations), location, err)
+			failed++
+			continue
+		}
+
+		n, _ := result.RowsAffected()
+		resolved++
+		log.Printf("✅ [%d/%d] %q → %s (%s, %d rows)", i+1, len(locations), location, place.Name, place.ID, n)
+	}
+
+	log.Printf("🎉 Geocoded %s: %d resolved, %d unknown, %d failed", table, resolved, unknown, failed)
+}
@@ -0,0 +1,10 @@
+-- Migration: Add account bans
+-- Banned users can't sign in or refresh; auth-service also revokes their
+-- outstanding tokens in Redis when the ban is applied.
+
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS banned_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS ban_reason TEXT;
+
+-- Partial index: bans are rare, so only index banned rows
+CREATE INDEX IF NOT EXISTS idx_users_banned_at ON users(banned_at) WHERE banned_at IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Rollback: Remove account bans
+
+DROP INDEX IF EXISTS idx_users_banned_at;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS ban_reason,
+DROP COLUMN IF EXISTS banned_at;
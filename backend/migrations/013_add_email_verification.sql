@@ -0,0 +1,17 @@
+-- Migration: Add email verification
+-- New accounts start unverified; auth-service emails a signed link that sets
+-- email_verified. Accounts that existed before this migration are trusted.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'users' AND column_name = 'email_verified'
+    ) THEN
+        ALTER TABLE users
+        ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+        ADD COLUMN email_verified_at TIMESTAMP;
+
+        UPDATE users SET email_verified = TRUE, email_verified_at = CURRENT_TIMESTAMP;
+    END IF;
+END $$;
@@ -0,0 +1,5 @@
+-- Rollback: Remove email verification
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS email_verified_at,
+DROP COLUMN IF EXISTS email_verified;
@@ -0,0 +1,27 @@
+-- Migration: Add TOTP two-factor authentication
+-- mfa_secret is encrypted by auth-service (AES-GCM, MFA_ENCRYPTION_KEY); it is
+-- set at enrollment and only becomes active once a code is confirmed.
+
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN IF NOT EXISTS mfa_secret TEXT,
+ADD COLUMN IF NOT EXISTS mfa_enabled_at TIMESTAMP;
+
+-- One-time recovery codes, stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+
+-- Roles that must use 2FA to sign in
+CREATE TABLE IF NOT EXISTS mfa_role_policies (
+    role VARCHAR(20) PRIMARY KEY,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
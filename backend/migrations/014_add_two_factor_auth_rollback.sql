@@ -0,0 +1,9 @@
+-- Rollback: Remove TOTP two-factor authentication
+
+DROP TABLE IF EXISTS mfa_role_policies;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS mfa_enabled_at,
+DROP COLUMN IF EXISTS mfa_secret,
+DROP COLUMN IF EXISTS mfa_enabled;
@@ -0,0 +1,3 @@
+-- Rollback: Remove external identities
+
+DROP TABLE IF EXISTS user_identities;
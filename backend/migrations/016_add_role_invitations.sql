@@ -0,0 +1,35 @@
+-- Migration: Add role invitations and role change audit log
+-- Public registration only creates jobseekers and recruiters; admins are
+-- created by accepting an invitation from an existing admin (or with the
+-- auth-service create-admin command). The invitation link is a signed token
+-- whose subject is the invitation ID, so rows here can be revoked.
+
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'admin';
+
+CREATE TABLE IF NOT EXISTS role_invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    accepted_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_invitations_email ON role_invitations(LOWER(email));
+
+-- Every role change, whoever made it
+CREATE TABLE IF NOT EXISTS role_change_audit (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_role VARCHAR(20),
+    new_role VARCHAR(20) NOT NULL,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    source VARCHAR(20) NOT NULL, -- admin, invitation or bootstrap
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_role_change_audit_user_id ON role_change_audit(user_id);
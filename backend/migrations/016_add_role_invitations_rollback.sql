@@ -0,0 +1,5 @@
+-- Rollback: Remove role invitations and role change audit log
+-- The 'admin' enum value stays; Postgres can't drop enum values and admins may exist.
+
+DROP TABLE IF EXISTS role_change_audit;
+DROP TABLE IF EXISTS role_invitations;
@@ -0,0 +1,3 @@
+-- Rollback: Remove API keys
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,3 @@
+-- Rollback: Remove impersonation audit log
+
+DROP TABLE IF EXISTS impersonation_sessions;
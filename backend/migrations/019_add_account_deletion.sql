@@ -0,0 +1,30 @@
+-- Migration: Account deletion with a grace period
+-- A deletion request schedules the account for erasure; until then the user
+-- can cancel it. Erasure anonymizes the user's applications instead of
+-- deleting them, so recruiters keep their hiring history, and unassigns a
+-- recruiter's companies and jobs so an admin can hand them to a colleague.
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS deletion_requested_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS deletion_scheduled_for TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled_for
+    ON users(deletion_scheduled_for) WHERE deletion_scheduled_for IS NOT NULL;
+
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMP;
+
+-- Deleting a user no longer cascades to their applications, companies and jobs
+ALTER TABLE applications
+    DROP CONSTRAINT IF EXISTS applications_applicant_id_fkey,
+    ADD CONSTRAINT applications_applicant_id_fkey
+        FOREIGN KEY (applicant_id) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE companies
+    DROP CONSTRAINT IF EXISTS companies_recruiter_id_fkey,
+    ADD CONSTRAINT companies_recruiter_id_fkey
+        FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE jobs
+    DROP CONSTRAINT IF EXISTS jobs_recruiter_id_fkey,
+    ADD CONSTRAINT jobs_recruiter_id_fkey
+        FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE SET NULL;
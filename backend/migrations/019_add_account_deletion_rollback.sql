@@ -0,0 +1,24 @@
+-- Rollback: Remove account deletion
+
+ALTER TABLE jobs
+    DROP CONSTRAINT IF EXISTS jobs_recruiter_id_fkey,
+    ADD CONSTRAINT jobs_recruiter_id_fkey
+        FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE companies
+    DROP CONSTRAINT IF EXISTS companies_recruiter_id_fkey,
+    ADD CONSTRAINT companies_recruiter_id_fkey
+        FOREIGN KEY (recruiter_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE applications
+    DROP CONSTRAINT IF EXISTS applications_applicant_id_fkey,
+    ADD CONSTRAINT applications_applicant_id_fkey
+        FOREIGN KEY (applicant_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE applications DROP COLUMN IF EXISTS anonymized_at;
+
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_for;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deletion_scheduled_for,
+    DROP COLUMN IF EXISTS deletion_requested_at;
@@ -0,0 +1,41 @@
+-- Migration: Add company teams
+-- A company can have several recruiters. Membership roles decide what each
+-- member may do: owners manage everything including the team and deleting the
+-- company, admins manage the company, its jobs and non-owner members,
+-- recruiters manage jobs and applications, viewers can only read applications.
+-- companies.recruiter_id and jobs.recruiter_id stay as the creator and poster.
+
+CREATE TABLE IF NOT EXISTS company_members (
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'admin', 'recruiter', 'viewer')),
+    added_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (company_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_members_user_id ON company_members(user_id);
+
+-- Invitations are shown to the invitee when they sign in with the invited email
+CREATE TABLE IF NOT EXISTS company_invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'admin', 'recruiter', 'viewer')),
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    accepted_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    declined_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_invitations_company_id ON company_invitations(company_id);
+CREATE INDEX IF NOT EXISTS idx_company_invitations_email ON company_invitations(LOWER(email));
+
+-- Every company's recruiter becomes its owner
+INSERT INTO company_members (company_id, user_id, role)
+SELECT id, recruiter_id, 'owner' FROM companies WHERE recruiter_id IS NOT NULL
+ON CONFLICT (company_id, user_id) DO NOTHING;
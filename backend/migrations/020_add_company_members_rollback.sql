@@ -0,0 +1,5 @@
+-- Rollback: Remove company teams
+-- companies.recruiter_id was never dropped, so single-owner checks keep working.
+
+DROP TABLE IF EXISTS company_invitations;
+DROP TABLE IF EXISTS company_members;
@@ -0,0 +1,54 @@
+-- Migration: Add full-text search to jobs
+-- Jobs get a weighted search_vector (title A, required skills B, company name C,
+-- description D) kept up to date by triggers on jobs and companies, and a GIN
+-- index, so keyword search can rank by relevance instead of scanning with ILIKE.
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- Builds a job's search vector; shared by the triggers and the backfill
+CREATE OR REPLACE FUNCTION job_search_vector(title TEXT, skills TEXT[], description TEXT, company_name TEXT)
+RETURNS tsvector AS $$
+  SELECT setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+         setweight(to_tsvector('english', COALESCE(array_to_string(skills, ' '), '')), 'B') ||
+         setweight(to_tsvector('english', COALESCE(company_name, '')), 'C') ||
+         setweight(to_tsvector('english', COALESCE(description, '')), 'D');
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION jobs_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+  NEW.search_vector := job_search_vector(NEW.title, NEW.required_skills, NEW.description,
+    (SELECT name FROM companies WHERE id = NEW.company_id));
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS jobs_search_vector_trigger ON jobs;
+CREATE TRIGGER jobs_search_vector_trigger
+BEFORE INSERT OR UPDATE OF title, required_skills, description, company_id ON jobs
+FOR EACH ROW EXECUTE FUNCTION jobs_search_vector_update();
+
+-- Renaming a company re-indexes its jobs
+CREATE OR REPLACE FUNCTION companies_name_search_vector_update()
+RETURNS TRIGGER AS $$
+BEGIN
+  UPDATE jobs
+  SET search_vector = job_search_vector(title, required_skills, description, NEW.name)
+  WHERE company_id = NEW.id;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS companies_name_search_vector_trigger ON companies;
+CREATE TRIGGER companies_name_search_vector_trigger
+AFTER UPDATE OF name ON companies
+FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+EXECUTE FUNCTION companies_name_search_vector_update();
+
+-- Populate search_vector for existing jobs
+UPDATE jobs j
+SET search_vector = job_search_vector(j.title, j.required_skills, j.description, c.name)
+FROM companies c
+WHERE c.id = j.company_id;
+
+CREATE INDEX IF NOT EXISTS jobs_search_idx ON jobs USING GIN(search_vector);
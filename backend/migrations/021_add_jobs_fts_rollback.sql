@@ -0,0 +1,9 @@
+-- Rollback: Remove full-text search from jobs
+
+DROP INDEX IF EXISTS jobs_search_idx;
+DROP TRIGGER IF EXISTS companies_name_search_vector_trigger ON companies;
+DROP TRIGGER IF EXISTS jobs_search_vector_trigger ON jobs;
+DROP FUNCTION IF EXISTS companies_name_search_vector_update();
+DROP FUNCTION IF EXISTS jobs_search_vector_update();
+DROP FUNCTION IF EXISTS job_search_vector(TEXT, TEXT[], TEXT, TEXT);
+ALTER TABLE jobs DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,27 @@
+-- Migration: Add structured salary ranges to jobs
+-- jobs.salary stays as the text shown to job seekers; salary_min/salary_max are
+-- amounts in currency per salary_period. The annualized columns let searches
+-- compare salaries quoted per hour (2080 hours a year), month or year.
+-- Existing salary strings are parsed by job-service's backfill script:
+--   cd backend/job-service && go run ./scripts/backfill_salaries
+
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS salary_min NUMERIC(15, 2) CHECK (salary_min >= 0),
+    ADD COLUMN IF NOT EXISTS salary_max NUMERIC(15, 2) CHECK (salary_max >= 0),
+    ADD COLUMN IF NOT EXISTS currency CHAR(3),
+    ADD COLUMN IF NOT EXISTS salary_period VARCHAR(10) CHECK (salary_period IN ('hour', 'month', 'year')),
+    ADD COLUMN IF NOT EXISTS salary_hidden BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_salary_range_check;
+ALTER TABLE jobs ADD CONSTRAINT jobs_salary_range_check CHECK (salary_min <= salary_max);
+
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS salary_min_annual NUMERIC GENERATED ALWAYS AS (
+        salary_min * CASE salary_period WHEN 'hour' THEN 2080 WHEN 'month' THEN 12 ELSE 1 END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS salary_max_annual NUMERIC GENERATED ALWAYS AS (
+        salary_max * CASE salary_period WHEN 'hour' THEN 2080 WHEN 'month' THEN 12 ELSE 1 END
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_salary_min_annual ON jobs(currency, salary_min_annual);
+CREATE INDEX IF NOT EXISTS idx_jobs_salary_max_annual ON jobs(currency, salary_max_annual);
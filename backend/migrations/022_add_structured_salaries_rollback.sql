@@ -0,0 +1,14 @@
+-- Rollback: Remove structured salary ranges from jobs
+-- The free-form jobs.salary column was never dropped.
+
+DROP INDEX IF EXISTS idx_jobs_salary_max_annual;
+DROP INDEX IF EXISTS idx_jobs_salary_min_annual;
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_salary_range_check;
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS salary_max_annual,
+    DROP COLUMN IF EXISTS salary_min_annual,
+    DROP COLUMN IF EXISTS salary_hidden,
+    DROP COLUMN IF EXISTS salary_period,
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS salary_max,
+    DROP COLUMN IF EXISTS salary_min;
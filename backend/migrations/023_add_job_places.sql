@@ -0,0 +1,20 @@
+-- Migration: Add geocoded places to jobs and companies
+-- job-service resolves jobs.location and companies.headquarters to a gazetteer
+-- place (place_id such as 'geonames:1581130') and its coordinates, so spellings
+-- of one city match each other and searches can filter by distance.
+-- Existing rows are geocoded by job-service's backfill script:
+--   cd backend/job-service && go run ./scripts/geocode_places
+
+ALTER TABLE jobs
+    ADD COLUMN IF NOT EXISTS place_id VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180);
+
+ALTER TABLE companies
+    ADD COLUMN IF NOT EXISTS place_id VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS latitude DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    ADD COLUMN IF NOT EXISTS longitude DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_place ON jobs(place_id);
+-- Radius searches prefilter on a bounding box before computing distances
+CREATE INDEX IF NOT EXISTS idx_jobs_coordinates ON jobs(latitude, longitude) WHERE latitude IS NOT NULL;
@@ -0,0 +1,13 @@
+-- Rollback: Remove geocoded places from jobs and companies
+-- The free-form jobs.location and companies.headquarters columns were never dropped.
+
+DROP INDEX IF EXISTS idx_jobs_coordinates;
+DROP INDEX IF EXISTS idx_jobs_place;
+ALTER TABLE companies
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude,
+    DROP COLUMN IF EXISTS place_id;
+ALTER TABLE jobs
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude,
+    DROP COLUMN IF EXISTS place_id;
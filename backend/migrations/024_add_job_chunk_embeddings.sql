@@ -0,0 +1,33 @@
+-- Migration: Embed job descriptions and skills, not just titles
+-- job-service splits each job into chunks (its title, its required skills and
+-- overlapping windows of its Markdown-stripped description) and embeds each.
+-- Semantic search scores a job by its most similar chunk (max-sim);
+-- jobs.embedding is the normalized mean of a job's chunks, a single vector per
+-- job. title_embedding is kept up to date for older clients.
+-- Existing jobs are embedded by job-service's script:
+--   cd backend/job-service && go run ./scripts/generate_embeddings
+
+CREATE TABLE IF NOT EXISTS job_embeddings (
+    job_id UUID NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    chunk_index INTEGER NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('title', 'skills', 'description')),
+    content TEXT NOT NULL,
+    embedding vector(384) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (job_id, chunk_index)
+);
+
+CREATE INDEX IF NOT EXISTS job_embeddings_embedding_idx
+ON job_embeddings
+USING ivfflat (embedding vector_cosine_ops)
+WITH (lists = 100);
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS embedding vector(384);
+
+CREATE INDEX IF NOT EXISTS jobs_embedding_idx
+ON jobs
+USING ivfflat (embedding vector_cosine_ops)
+WITH (lists = 100);
+
+COMMENT ON COLUMN jobs.embedding IS
+'Normalized mean of the job''s chunk embeddings (job_embeddings)';
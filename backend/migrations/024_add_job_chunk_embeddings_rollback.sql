@@ -0,0 +1,6 @@
+-- Rollback: Remove chunk embeddings
+-- jobs.title_embedding, used by earlier versions of semantic search, was never dropped.
+
+DROP INDEX IF EXISTS jobs_embedding_idx;
+ALTER TABLE jobs DROP COLUMN IF EXISTS embedding;
+DROP TABLE IF EXISTS job_embeddings;
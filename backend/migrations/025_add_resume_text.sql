@@ -0,0 +1,9 @@
+-- Migration: Keep the text of users' resumes for job recommendations
+-- user-service reads it from DOCX uploads; users whose resume is a PDF or DOC
+-- can paste it into their profile instead. job-service embeds it, along with
+-- the user's skills, bio and applications, to recommend jobs.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS resume_text TEXT;
+
+COMMENT ON COLUMN users.resume_text IS
+'Plain text of the user''s resume, used to recommend jobs';
@@ -0,0 +1,3 @@
+-- Rollback: Remove resume text
+
+ALTER TABLE users DROP COLUMN IF EXISTS resume_text;
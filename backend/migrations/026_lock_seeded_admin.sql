@@ -0,0 +1,32 @@
+-- Migration: Lock the seeded admin account and audit pre-existing admins
+-- 011 seeds admin@hireai.com with the published password admin123. If it
+-- still has that password it is demoted to jobseeker and its password cleared,
+-- so nobody can sign in with it until the mailbox owner resets the password.
+-- With no admin left, create the first one with auth-service's create_admin
+-- command. Deployments that already changed the password keep the account.
+--
+-- Admins from before role_change_audit existed (the seed account and
+-- accounts registered as admin when registration accepted any role) get the
+-- audit row they would have had.
+
+INSERT INTO role_change_audit (user_id, old_role, new_role, source, reason, created_at)
+SELECT id, NULL, 'admin',
+       CASE WHEN email = 'admin@hireai.com' THEN 'seed' ELSE 'registration' END,
+       CASE WHEN email = 'admin@hireai.com' THEN '011_seed_admin_user migration'
+            ELSE 'Registered as admin before 016_add_role_invitations' END,
+       created_at
+FROM users
+WHERE role = 'admin'
+  AND NOT EXISTS (SELECT 1 FROM role_change_audit a WHERE a.user_id = users.id);
+
+WITH locked AS (
+    UPDATE users
+    SET role = 'jobseeker', password_hash = '', updated_at = CURRENT_TIMESTAMP
+    WHERE email = 'admin@hireai.com'
+      AND role = 'admin'
+      AND password_hash = '$2a$10$OPgqm1xLemI2heMwwAlzkOkUtDHQe28GXmw23JPzkMlyRVKWqzhq2'
+    RETURNING id
+)
+INSERT INTO role_change_audit (user_id, old_role, new_role, source, reason)
+SELECT id, 'admin', 'jobseeker', 'migration', 'Seeded admin still had the published password'
+FROM locked;
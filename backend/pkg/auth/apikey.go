@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API keys ("jpk_..." bearer tokens) are issued by auth-service, whose internal
+// API is asked who a key belongs to, with an "api-keys" service token. Answers
+// are cached for REVOCATION_CACHE_TTL, so a revoked key may keep working that long.
+
+const apiKeyPrefix = "jpk_"
+
+var errInvalidAPIKey = errors.New("invalid API key")
+
+type apiKeyIdentity struct {
+	KeyID         string   `json:"key_id"`
+	UserID        string   `json:"user_id"`
+	Email         string   `json:"email"`
+	Role          string   `json:"role"`
+	EmailVerified bool     `json:"email_verified"`
+	Scopes        []string `json:"scopes"`
+}
+
+type apiKeyCacheEntry struct {
+	identity *apiKeyIdentity // nil for invalid keys
+	expires  time.Time
+}
+
+var (
+	apiKeyCache   = make(map[string]apiKeyCacheEntry)
+	apiKeyCacheMu sync.Mutex
+	apiKeyClient  = &http.Client{Timeout: 5 * time.Second}
+)
+
+// introspectAPIKey asks auth-service who a key belongs to, using the cache when it can
+func introspectAPIKey(key string) (*apiKeyIdentity, error) {
+	now := time.Now()
+
+	apiKeyCacheMu.Lock()
+	entry, ok := apiKeyCache[key]
+	apiKeyCacheMu.Unlock()
+	if ok && now.Before(entry.expires) {
+		if entry.identity == nil {
+			return nil, errInvalidAPIKey
+		}
+		return entry.identity, nil
+	}
+
+	token, err := ServiceToken("api-keys")
+	if err != nil {
+		return nil, err
+	}
+	body, _ := json.Marshal(map[string]string{"key": key})
+	req, err := http.NewRequest(http.MethodPost, authServiceInternalURL()+"/internal/api-keys/introspect", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := apiKeyClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var identity *apiKeyIdentity
+	switch resp.StatusCode {
+	case http.StatusOK:
+		identity = &apiKeyIdentity{}
+		if err := json.NewDecoder(resp.Body).Decode(identity); err != nil {
+			return nil, err
+		}
+	case http.StatusNotFound:
+	default:
+		return nil, fmt.Errorf("introspection returned status %d", resp.StatusCode)
+	}
+
+	apiKeyCacheMu.Lock()
+	if len(apiKeyCache) >= revocationCacheMaxEntries {
+		for k, e := range apiKeyCache {
+			if now.After(e.expires) {
+				delete(apiKeyCache, k)
+			}
+		}
+	}
+	apiKeyCache[key] = apiKeyCacheEntry{identity: identity, expires: now.Add(revocationCacheTTL())}
+	apiKeyCacheMu.Unlock()
+
+	if identity == nil {
+		return nil, errInvalidAPIKey
+	}
+	return identity, nil
+}
+
+// authenticateAPIKey sets the key owner's identity on the context, or aborts.
+// Keys are only accepted on routes that name the scopes they need.
+func authenticateAPIKey(c *gin.Context, key string, scopes []string) {
+	if len(scopes) == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot be used for this endpoint"})
+		c.Abort()
+		return
+	}
+
+	identity, err := introspectAPIKey(key)
+	if err == errInvalidAPIKey {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired API key"})
+		c.Abort()
+		return
+	} else if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to check API key"})
+		c.Abort()
+		return
+	}
+
+	for _, want := range scopes {
+		granted := false
+		for _, have := range identity.Scopes {
+			if have == want {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + want + " scope"})
+			c.Abort()
+			return
+		}
+	}
+
+	c.Set("user_id", identity.UserID)
+	c.Set("user_email", identity.Email)
+	c.Set("user_role", identity.Role)
+	c.Set("email_verified", identity.EmailVerified)
+	c.Set("api_key_id", identity.KeyID)
+
+	c.Next()
+}
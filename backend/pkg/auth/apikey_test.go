@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeAuthService serves the service token and introspection endpoints of
+// auth-service's internal API, counting introspection calls
+func fakeAuthService(t *testing.T, calls *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/internal/service-tokens":
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["service"] != "job-service" || req["secret"] != "s3cret" || req["audience"] != "api-keys" {
+				t.Errorf("service token request %v", req)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"token": "svc-token", "expires_in": 60})
+		case "/internal/api-keys/introspect":
+			*calls++
+			if r.Header.Get("Authorization") != "Bearer svc-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			switch req["key"] {
+			case "jpk_good":
+				json.NewEncoder(w).Encode(apiKeyIdentity{KeyID: "k1", UserID: "u1", Role: "recruiter", Scopes: []string{"jobs:write"}})
+			case "jpk_forbidden":
+				w.WriteHeader(http.StatusForbidden)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestIntrospectAPIKey(t *testing.T) {
+	calls := 0
+	server := fakeAuthService(t, &calls)
+	defer server.Close()
+	t.Setenv("AUTH_SERVICE_INTERNAL_URL", server.URL)
+	t.Setenv("JOB_SERVICE_SECRET", "s3cret")
+	cfg = Config{Service: "job-service"}
+
+	identity, err := introspectAPIKey("jpk_good")
+	if err != nil || identity.KeyID != "k1" || identity.UserID != "u1" {
+		t.Fatalf("valid key: got %+v, %v", identity, err)
+	}
+	if _, err := introspectAPIKey("jpk_good"); err != nil || calls != 1 {
+		t.Errorf("cached key: %v after %d calls, want 1 call", err, calls)
+	}
+
+	if _, err := introspectAPIKey("jpk_unknown"); err != errInvalidAPIKey {
+		t.Errorf("unknown key: got %v, want errInvalidAPIKey", err)
+	}
+
+	// A refused service token is an outage, not an invalid key, and isn't cached
+	calls = 0
+	for i := 0; i < 2; i++ {
+		if _, err := introspectAPIKey("jpk_forbidden"); err == nil || err == errInvalidAPIKey {
+			t.Errorf("refused service token: got %v, want an error other than errInvalidAPIKey", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("refused service token: %d calls, want 2", calls)
+	}
+}
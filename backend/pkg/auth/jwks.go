@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Tokens are signed by auth-service with RS256 or EdDSA. This service only
+// holds the public keys, fetched from auth-service's JWKS endpoint and cached.
+// A token with an unknown kid triggers a refetch so key rotation is picked up
+// without waiting for the refresh interval.
+
+const (
+	jwksRefreshInterval = 10 * time.Minute
+	jwksMinRefetchDelay = 30 * time.Second // Upper bound on refetches caused by unknown kids
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+type publicKey struct {
+	alg string
+	key interface{}
+}
+
+var (
+	jwksMu          sync.RWMutex
+	jwksKeys        = map[string]publicKey{}
+	jwksFetchedAt   time.Time
+	jwksLastAttempt time.Time
+	jwksClient      = &http.Client{Timeout: 5 * time.Second}
+)
+
+// jwksURL returns the auth-service JWKS endpoint (JWKS_URL)
+func jwksURL() string {
+	if url := os.Getenv("JWKS_URL"); url != "" {
+		return url
+	}
+	return "http://localhost:8001/.well-known/jwks.json"
+}
+
+// initJWKS prefetches the signing keys so the first request doesn't pay for it
+func initJWKS() {
+	if err := refreshJWKS(); err != nil {
+		log.Printf("⚠️  Failed to fetch JWKS from %s: %v (will retry on first request)", jwksURL(), err)
+		return
+	}
+	log.Printf("✅ JWKS loaded from %s", jwksURL())
+}
+
+// refreshJWKS fetches the key set, replacing the cache on success
+func refreshJWKS() error {
+	jwksMu.Lock()
+	jwksLastAttempt = time.Now()
+	jwksMu.Unlock()
+
+	resp, err := jwksClient.Get(jwksURL())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]publicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		pub, err := parseJWK(k)
+		if err != nil {
+			log.Printf("Skipping JWKS key %s: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+
+	jwksMu.Lock()
+	jwksKeys = keys
+	jwksFetchedAt = time.Now()
+	jwksMu.Unlock()
+	return nil
+}
+
+// parseJWK converts an RSA or Ed25519 JWK into a verification key
+func parseJWK(k jwk) (publicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return publicKey{}, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return publicKey{}, err
+		}
+		return publicKey{alg: jwt.SigningMethodRS256.Alg(), key: &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return publicKey{}, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return publicKey{}, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return publicKey{}, fmt.Errorf("invalid Ed25519 key length %d", len(x))
+		}
+		return publicKey{alg: jwt.SigningMethodEdDSA.Alg(), key: ed25519.PublicKey(x)}, nil
+	default:
+		return publicKey{}, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// lookupJWK returns the cached key for kid and whether the cache is due for a refresh
+func lookupJWK(kid string) (publicKey, bool, bool) {
+	jwksMu.RLock()
+	defer jwksMu.RUnlock()
+	pub, ok := jwksKeys[kid]
+	stale := time.Since(jwksFetchedAt) > jwksRefreshInterval
+	canRefetch := time.Since(jwksLastAttempt) > jwksMinRefetchDelay
+	return pub, ok, (stale || !ok) && canRefetch
+}
+
+// verificationKey is the default Config.Keyfunc
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, fmt.Errorf("token has no kid")
+	}
+
+	pub, ok, refresh := lookupJWK(kid)
+	if refresh {
+		if err := refreshJWKS(); err != nil {
+			log.Printf("Failed to refresh JWKS: %v", err)
+		} else {
+			pub, ok, _ = lookupJWK(kid)
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != pub.alg {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+	return pub.key, nil
+}
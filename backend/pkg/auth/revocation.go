@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+// Revocation state is written to Redis by auth-service:
+//
+//	revoked_jti:<jti>                  a single token revoked before it expired (logout)
+//	revoked_session:<sid>              every token of a session that was signed out
+//	user_tokens_valid_after:<user_id>  unix time in milliseconds; tokens issued earlier
+//	                                   are rejected (password reset, ban, role change)
+//
+// Lookups are cached in-process for a short time so most requests don't
+// cost a Redis round trip.
+
+func init() {
+	// Tokens are signed and parsed with millisecond iat so one issued in the
+	// same second as user_tokens_valid_after, but before it, is still rejected
+	jwt.TimePrecision = time.Millisecond
+}
+
+type revocationCacheEntry struct {
+	value   int64
+	expires time.Time
+}
+
+var (
+	revocationCache   = make(map[string]revocationCacheEntry)
+	revocationCacheMu sync.Mutex
+)
+
+const revocationCacheMaxEntries = 10000
+
+// revocationCacheTTL returns how long lookups are cached (REVOCATION_CACHE_TTL, default 30s)
+func revocationCacheTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("REVOCATION_CACHE_TTL")); err == nil && ttl >= 0 {
+		return ttl
+	}
+	return 30 * time.Second
+}
+
+// cachedRevocationLookup returns a cached value for key, or calls fetch and caches the result
+func cachedRevocationLookup(key string, fetch func() (int64, error)) (int64, error) {
+	now := time.Now()
+
+	revocationCacheMu.Lock()
+	entry, ok := revocationCache[key]
+	revocationCacheMu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return 0, err
+	}
+
+	revocationCacheMu.Lock()
+	if len(revocationCache) >= revocationCacheMaxEntries {
+		for k, e := range revocationCache {
+			if now.After(e.expires) {
+				delete(revocationCache, k)
+			}
+		}
+	}
+	revocationCache[key] = revocationCacheEntry{value: value, expires: now.Add(revocationCacheTTL())}
+	revocationCacheMu.Unlock()
+
+	return value, nil
+}
+
+// IsRevoked reports whether a token or its session was revoked, or it was
+// issued before the user's tokens were invalidated. Redis errors fail open so
+// an outage doesn't lock every user out; they are logged.
+func IsRevoked(claims *Claims) bool {
+	if claims.ID != "" && revocationMarkerExists("revoked_jti:"+claims.ID) {
+		return true
+	}
+	if claims.SessionID != "" && revocationMarkerExists("revoked_session:"+claims.SessionID) {
+		return true
+	}
+
+	key := fmt.Sprintf("user_tokens_valid_after:%s", claims.UserID)
+	validAfter, err := cachedRevocationLookup(key, func() (int64, error) {
+		value, err := cfg.Redis.Get(ctx, key).Result()
+		if err == redis.Nil {
+			return 0, nil
+		} else if err != nil {
+			return 0, err
+		}
+		return strconv.ParseInt(value, 10, 64)
+	})
+	if err != nil {
+		log.Printf("Revocation lookup failed for user %s: %v", claims.UserID, err)
+		return false
+	}
+
+	if validAfter > 0 && validAfter < 1e12 {
+		validAfter *= 1000 // Written in seconds before the cutoff had millisecond precision
+	}
+	if validAfter > 0 && (claims.IssuedAt == nil || claims.IssuedAt.UnixMilli() < validAfter) {
+		return true
+	}
+	return false
+}
+
+// revocationMarkerExists checks for a revocation key, failing open on Redis errors
+func revocationMarkerExists(key string) bool {
+	exists, err := cachedRevocationLookup(key, func() (int64, error) {
+		return cfg.Redis.Exists(ctx, key).Result()
+	})
+	if err != nil {
+		log.Printf("Revocation lookup failed for %s: %v", key, err)
+		return false
+	}
+	return exists > 0
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestIsRevokedValidAfter(t *testing.T) {
+	t.Setenv("REVOCATION_CACHE_TTL", "0")
+	mr := miniredis.RunT(t)
+	cfg.Redis = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer cfg.Redis.Close()
+
+	// A cutoff in the middle of a second, so second granularity can't tell
+	// the tokens below apart
+	cutoff := time.Now().Truncate(time.Second).Add(500 * time.Millisecond)
+	mr.Set("user_tokens_valid_after:u1", strconv.FormatInt(cutoff.UnixMilli(), 10))
+	mr.Set("user_tokens_valid_after:legacy", strconv.FormatInt(cutoff.Unix(), 10))
+
+	// Tokens go through signing and parsing so the iat precision is the one on the wire
+	issued := func(userID string, at time.Time) *Claims {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+			UserID:           userID,
+			RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(at)},
+		}).SignedString([]byte("test"))
+		if err != nil {
+			t.Fatalf("signing: %v", err)
+		}
+		claims := &Claims{}
+		if _, err := jwt.ParseWithClaims(signed, claims, func(*jwt.Token) (interface{}, error) {
+			return []byte("test"), nil
+		}); err != nil {
+			t.Fatalf("parsing: %v", err)
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		claims  *Claims
+		revoked bool
+	}{
+		{"issued earlier in the same second", issued("u1", cutoff.Add(-100*time.Millisecond)), true},
+		{"issued later in the same second", issued("u1", cutoff.Add(100*time.Millisecond)), false},
+		{"issued the next second", issued("u1", cutoff.Add(time.Second)), false},
+		{"without iat", &Claims{UserID: "u1"}, true},
+		{"another user", issued("u2", cutoff.Add(-time.Hour)), false},
+		// Cutoffs written in seconds still apply, at second granularity
+		{"legacy cutoff, an earlier second", issued("legacy", cutoff.Add(-time.Second)), true},
+		{"legacy cutoff, the same second", issued("legacy", cutoff.Add(100*time.Millisecond)), false},
+	}
+	for _, tt := range tests {
+		if got := IsRevoked(tt.claims); got != tt.revoked {
+			t.Errorf("%s: IsRevoked = %v, want %v", tt.name, got, tt.revoked)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Services call each other's internal APIs with short-lived service tokens
+// signed by auth-service. A token's subject is the calling service and its
+// audience the API being called (e.g. "privacy", "email"). Internal APIs are
+// mounted on a separate router, served on a port that must not be exposed
+// outside the cluster, and refuse user access tokens and API keys.
+
+// ServiceAuth middleware only admits service tokens for audience whose subject is one of callers.
+// They carry no user, so user access tokens and API keys are refused here.
+func ServiceAuth(audience string, callers ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Service token required"})
+			c.Abort()
+			return
+		}
+
+		claims := &jwt.RegisteredClaims{}
+		token, err := jwt.ParseWithClaims(tokenString, claims, cfg.Keyfunc,
+			jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+			jwt.WithAudience(audience),
+			jwt.WithExpirationRequired())
+		if err != nil || !token.Valid {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired service token"})
+			c.Abort()
+			return
+		}
+
+		allowed := false
+		for _, caller := range callers {
+			if claims.Subject == caller {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Service not allowed to call this endpoint"})
+			c.Abort()
+			return
+		}
+
+		c.Set("service_name", claims.Subject)
+		c.Next()
+	}
+}
+
+// NewInternalRouter returns a router for the APIs only other services may call,
+// using the service's own middleware (e.g. metrics) after logging and recovery
+func NewInternalRouter(middleware ...gin.HandlerFunc) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Logger(), gin.Recovery())
+	router.Use(middleware...)
+	return router
+}
+
+// StartInternalServer serves the internal router in the background on the port
+// named by portEnv, or fallback
+func StartInternalServer(router *gin.Engine, portEnv, fallback string) {
+	port := os.Getenv(portEnv)
+	if port == "" {
+		port = fallback
+	}
+
+	go func() {
+		if err := router.Run(":" + port); err != nil {
+			log.Fatal("Failed to start internal server:", err)
+		}
+	}()
+	log.Printf("🔒 Internal API listening on port %s", port)
+}
+
+type serviceToken struct {
+	token   string
+	expires time.Time
+}
+
+var (
+	serviceTokens   = map[string]serviceToken{}
+	serviceTokensMu sync.Mutex
+	serviceClient   = &http.Client{Timeout: 5 * time.Second}
+)
+
+// authServiceInternalURL returns the base URL of auth-service's internal API (AUTH_SERVICE_INTERNAL_URL)
+func authServiceInternalURL() string {
+	if url := os.Getenv("AUTH_SERVICE_INTERNAL_URL"); url != "" {
+		return strings.TrimSuffix(url, "/")
+	}
+	return "http://localhost:8101"
+}
+
+// ServiceToken returns a token for calling another service's internal API for
+// audience, fetching one from auth-service with this service's secret
+// (see Config.Service) when the cached token is about to expire
+func ServiceToken(audience string) (string, error) {
+	serviceTokensMu.Lock()
+	defer serviceTokensMu.Unlock()
+
+	if cached, ok := serviceTokens[audience]; ok && time.Until(cached.expires) > 10*time.Second {
+		return cached.token, nil
+	}
+
+	secret := os.Getenv(strings.ToUpper(strings.ReplaceAll(cfg.Service, "-", "_")) + "_SECRET")
+	if secret == "" {
+		return "", fmt.Errorf("no secret configured for %s", cfg.Service)
+	}
+
+	body, _ := json.Marshal(map[string]string{"service": cfg.Service, "secret": secret, "audience": audience})
+	resp, err := serviceClient.Post(authServiceInternalURL()+"/internal/service-tokens", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("service token request returned status %d", resp.StatusCode)
+	}
+	var issued struct {
+		Token     string `json:"token"`
+		ExpiresIn int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&issued); err != nil {
+		return "", err
+	}
+
+	serviceTokens[audience] = serviceToken{
+		token:   issued.Token,
+		expires: time.Now().Add(time.Duration(issued.ExpiresIn) * time.Second),
+	}
+	return issued.Token, nil
+}
@@ -0,0 +1,125 @@
+package policy
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authorization is declared rather than written into handlers: each service
+// grants each role a set of permissions, either on any resource or only on
+// resources the caller is related to (its own posts, its company's jobs).
+// Routes check that the caller's role holds a permission at all with Require;
+// handlers check the resource once they have loaded it with Authorize. What
+// "own" means is up to the resource, so services keep their grants, resources
+// and denial messages and share the evaluation here.
+
+// Permission names an action on a kind of resource, e.g. "job.create"
+type Permission string
+
+// Scope limits which resources a grant covers
+type Scope int
+
+const (
+	// Own covers only resources the caller is related to, as the resource decides
+	Own Scope = iota + 1
+	// Any covers every resource
+	Any
+)
+
+// Grants maps each role to the permissions it holds
+type Grants map[string]map[Permission]Scope
+
+// Subject is the caller being authorized
+type Subject struct {
+	UserID string
+	Role   string
+}
+
+// Reason says why an Own grant doesn't cover a resource
+type Reason string
+
+// NotOwner is the reason an Owner resource gives when it belongs to someone else
+const NotOwner Reason = "not_owner"
+
+// Decision is the outcome of a policy check
+type Decision struct {
+	Allowed bool
+	// Reason is set when the role holds the permission but its Own grant
+	// doesn't cover the resource; it is empty when the role lacks the permission
+	Reason Reason
+}
+
+// Resource is what the caller wants to act on. Covers decides whether an Own
+// grant of perm extends to it.
+type Resource interface {
+	Covers(subject Subject, perm Permission) Decision
+}
+
+// Owner is a resource owned by the user with this ID, e.g. a blog post by its author
+type Owner string
+
+func (o Owner) Covers(subject Subject, _ Permission) Decision {
+	if subject.UserID != "" && string(o) == subject.UserID {
+		return Decision{Allowed: true}
+	}
+	return Decision{Reason: NotOwner}
+}
+
+// Policy is what a service checks its callers against
+type Policy struct {
+	Grants Grants
+	// Explain returns the message for a denial of perm for reason, or "" for
+	// the generic one. Optional.
+	Explain func(perm Permission, reason Reason) string
+}
+
+const deniedMessage = "You do not have permission to perform this action"
+
+// Evaluate decides whether subject may perform perm. With a nil resource it only
+// checks that the role holds the permission in some scope.
+func (p *Policy) Evaluate(subject Subject, perm Permission, resource Resource) Decision {
+	scope, ok := p.Grants[subject.Role][perm]
+	if !ok {
+		return Decision{}
+	}
+	if scope == Any || resource == nil {
+		return Decision{Allowed: true}
+	}
+	return resource.Covers(subject, perm)
+}
+
+// subjectFrom reads the caller set by auth.Middleware
+func subjectFrom(c *gin.Context) Subject {
+	return Subject{UserID: c.GetString("user_id"), Role: c.GetString("user_role")}
+}
+
+// Require middleware ensures the caller's role holds perm
+func (p *Policy) Require(perm Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !p.Evaluate(subjectFrom(c), perm, nil).Allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": deniedMessage})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Authorize checks perm against a resource, responding with 403 and returning
+// false if the caller may not perform it
+func (p *Policy) Authorize(c *gin.Context, perm Permission, resource Resource) bool {
+	decision := p.Evaluate(subjectFrom(c), perm, resource)
+	if decision.Allowed {
+		return true
+	}
+
+	message := deniedMessage
+	if decision.Reason != "" && p.Explain != nil {
+		if explained := p.Explain(perm, decision.Reason); explained != "" {
+			message = explained
+		}
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": message})
+	return false
+}
@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+const (
+	postUpdate  Permission = "post.update"
+	postPublish Permission = "post.publish"
+	teamManage  Permission = "team.manage"
+)
+
+// notOnTeam is the reason team resources give for outsiders
+const notOnTeam Reason = "not_on_team"
+
+// team is a resource covered for its members
+type team []string
+
+func (t team) Covers(subject Subject, _ Permission) Decision {
+	for _, member := range t {
+		if member == subject.UserID {
+			return Decision{Allowed: true}
+		}
+	}
+	return Decision{Reason: notOnTeam}
+}
+
+var testPolicy = &Policy{
+	Grants: Grants{
+		"editor": {postUpdate: Own, teamManage: Own},
+		"admin":  {postUpdate: Any, postPublish: Any},
+	},
+	Explain: func(perm Permission, reason Reason) string {
+		if reason == NotOwner && perm == postUpdate {
+			return "You can only edit your own posts"
+		}
+		return ""
+	},
+}
+
+func TestEvaluate(t *testing.T) {
+	editor := Subject{UserID: "u1", Role: "editor"}
+	admin := Subject{UserID: "a1", Role: "admin"}
+	tests := []struct {
+		name     string
+		subject  Subject
+		perm     Permission
+		resource Resource
+		want     Decision
+	}{
+		{"role check only", editor, postUpdate, nil, Decision{Allowed: true}},
+		{"no grant", editor, postPublish, nil, Decision{}},
+		{"no grant, own resource", editor, postPublish, Owner("u1"), Decision{}},
+		{"unknown role", Subject{UserID: "u1", Role: "moderator"}, postUpdate, nil, Decision{}},
+		{"no role", Subject{UserID: "u1"}, postUpdate, nil, Decision{}},
+		{"own post", editor, postUpdate, Owner("u1"), Decision{Allowed: true}},
+		{"someone else's post", editor, postUpdate, Owner("u2"), Decision{Reason: NotOwner}},
+		{"post without owner", editor, postUpdate, Owner(""), Decision{Reason: NotOwner}},
+		{"no user ID", Subject{Role: "editor"}, postUpdate, Owner(""), Decision{Reason: NotOwner}},
+		{"any post", admin, postUpdate, Owner("u2"), Decision{Allowed: true}},
+		{"on the team", editor, teamManage, team{"u3", "u1"}, Decision{Allowed: true}},
+		{"not on the team", editor, teamManage, team{"u3"}, Decision{Reason: notOnTeam}},
+	}
+	for _, tt := range tests {
+		if got := testPolicy.Evaluate(tt.subject, tt.perm, tt.resource); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// callerContext is a request context as auth.Middleware leaves it
+func callerContext(values map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	for k, v := range values {
+		c.Set(k, v)
+	}
+	return c, w
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller map[string]string
+		perm   Permission
+		status int
+	}{
+		{"admin", map[string]string{"user_id": "a1", "user_role": "admin"}, postPublish, http.StatusOK},
+		{"editor", map[string]string{"user_id": "u1", "user_role": "editor"}, postPublish, http.StatusForbidden},
+		{"signed out", map[string]string{}, postUpdate, http.StatusForbidden},
+		// An impersonation token carries the impersonated user's role, so an
+		// admin acting as an editor has the editor's permissions only
+		{"admin impersonating editor", map[string]string{"user_id": "u1", "user_role": "editor", "actor_id": "a1"}, postPublish, http.StatusForbidden},
+		// API keys act with their owner's role; a scope doesn't add permissions
+		{"API key of an admin", map[string]string{"user_id": "a1", "user_role": "admin", "api_key_id": "k1"}, postPublish, http.StatusOK},
+		{"API key of an editor", map[string]string{"user_id": "u1", "user_role": "editor", "api_key_id": "k1"}, postPublish, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		c, w := callerContext(tt.caller)
+		testPolicy.Require(tt.perm)(c)
+		status := http.StatusOK
+		if c.IsAborted() {
+			status = w.Code
+		}
+		if status != tt.status {
+			t.Errorf("%s: %s responded %d, want %d", tt.name, tt.perm, status, tt.status)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		perm     Permission
+		resource Resource
+		allowed  bool
+		message  string
+	}{
+		{"allowed", "editor", postUpdate, Owner("u1"), true, ""},
+		{"explained", "editor", postUpdate, Owner("u2"), false, "You can only edit your own posts"},
+		{"not explained", "editor", teamManage, team{"u2"}, false, deniedMessage},
+		{"no grant", "editor", postPublish, Owner("u1"), false, deniedMessage},
+	}
+	for _, tt := range tests {
+		c, w := callerContext(map[string]string{"user_id": "u1", "user_role": tt.role})
+		if got := testPolicy.Authorize(c, tt.perm, tt.resource); got != tt.allowed {
+			t.Errorf("%s: Authorize = %v, want %v", tt.name, got, tt.allowed)
+		}
+		if !tt.allowed && (w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), `"`+tt.message+`"`)) {
+			t.Errorf("%s: %d %s, want 403 with error %q", tt.name, w.Code, w.Body.String(), tt.message)
+		}
+	}
+
+	// Without Explain every denial gets the generic message
+	unexplained := &Policy{Grants: testPolicy.Grants}
+	c, w := callerContext(map[string]string{"user_id": "u1", "user_role": "editor"})
+	if unexplained.Authorize(c, postUpdate, Owner("u2")) || !strings.Contains(w.Body.String(), deniedMessage) {
+		t.Errorf("without Explain: %s, want %q", w.Body.String(), deniedMessage)
+	}
+}
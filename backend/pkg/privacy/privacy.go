@@ -0,0 +1,96 @@
+package privacy
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/job-portal/pkg/auth"
+)
+
+// A user's data is spread over several services. Each one that stores any
+// implements Participant: it lists the tables it is responsible for, exports
+// the user's rows and erases them. auth-service orchestrates export and
+// erasure and refuses to run either if a table referencing users is not
+// claimed by some participant, so a new table can't be silently forgotten.
+//
+// Other services take part by mounting their participant with Register on
+// their internal router, authenticated with auth-service's service tokens for
+// the "privacy" audience:
+//
+//	GET    /internal/privacy/tables     {"tables": [...]}
+//	GET    /internal/privacy/users/:id  an Export
+//	DELETE /internal/privacy/users/:id  erase; must be safe to repeat
+
+// Audience of the service tokens auth-service sends to participants
+const Audience = "privacy"
+
+// Participant exports and erases the data a service holds about a user
+type Participant interface {
+	// Tables lists the tables holding user data that this participant exports and erases
+	Tables() ([]string, error)
+	Export(userID string) (*Export, error)
+	// Erase removes or anonymizes the user's data. It runs before the users row
+	// is deleted and must be safe to repeat.
+	Erase(userID string) error
+}
+
+// Export is one participant's share of a user's data
+type Export struct {
+	// Records end up in the archive as <participant>/<name>.json
+	Records map[string]json.RawMessage `json:"records"`
+	// Files are uploads auth-service fetches into the archive, e.g. the user's resume
+	Files []File `json:"files,omitempty"`
+}
+
+// File is an uploaded file belonging to the user
+type File struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Register mounts p's endpoints on the internal router; they only accept auth-service's privacy tokens
+func Register(router *gin.Engine, p Participant) {
+	internal := router.Group("/internal/privacy")
+	internal.Use(auth.ServiceAuth(Audience, "auth-service"))
+	{
+		internal.GET("/tables", func(c *gin.Context) {
+			tables, err := p.Tables()
+			if err != nil {
+				log.Printf("Privacy tables: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tables"})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"tables": tables})
+		})
+
+		internal.GET("/users/:id", func(c *gin.Context) {
+			export, err := p.Export(c.Param("id"))
+			if err != nil {
+				log.Printf("Privacy export for user %s: %v", c.Param("id"), err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export user data"})
+				return
+			}
+			c.JSON(http.StatusOK, export)
+		})
+
+		internal.DELETE("/users/:id", func(c *gin.Context) {
+			if err := p.Erase(c.Param("id")); err != nil {
+				log.Printf("Privacy erase for user %s: %v", c.Param("id"), err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to erase user data"})
+				return
+			}
+			log.Printf("User data erased for user %s", c.Param("id"))
+			c.JSON(http.StatusOK, gin.H{"message": "User data erased"})
+		})
+	}
+}
+
+// QueryRecords runs query on db and returns its rows as a JSON array
+func QueryRecords(db *sql.DB, query string, args ...interface{}) (json.RawMessage, error) {
+	var records []byte
+	err := db.QueryRow(`SELECT COALESCE(json_agg(t), '[]'::json) FROM (`+query+`) t`, args...).Scan(&records)
+	return records, err
+}
@@ -0,0 +1,262 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+// Routes are rate limited with GCRA (the generic cell rate algorithm, a token
+// bucket that stores a single timestamp) in Redis, so every replica of every
+// service shares the same budgets. Each service declares its policies at
+// startup; admins override them at runtime through auth-service, and every
+// service picks up changes within rateLimitRefreshInterval:
+//
+//	rate_limit_defaults             hash: policy name -> JSON Policy declared by a service
+//	rate_limit_policies             hash: policy name -> JSON Policy override
+//	rate_limit:<policy>:<identity>  theoretical arrival time, ms since the epoch
+//
+// Responses carry RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and
+// RateLimit-Policy headers; rejections get 429 with Retry-After.
+
+const rateLimitRefreshInterval = 30 * time.Second
+
+// Policy allows Limit requests per Period, in bursts of up to Burst
+type Policy struct {
+	Limit  int    `json:"limit"`
+	Period string `json:"period"`          // Go duration, e.g. "1m"
+	Burst  int    `json:"burst,omitempty"` // defaults to Limit
+	// By is who the budget belongs to: "ip", "user" (IP when signed out) or
+	// "api_key" (user, then IP, when not using a key)
+	By       string `json:"by"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// rateLimit is a policy ready to apply
+type rateLimit struct {
+	policy    Policy
+	period    time.Duration
+	emission  float64 // ms between requests at the sustained rate
+	tolerance float64 // ms of burst the bucket holds
+}
+
+var (
+	rateLimitRejections = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limit_rejections_total",
+			Help: "Total number of requests rejected by a rate limit",
+		},
+		[]string{"policy", "by"},
+	)
+
+	client            *redis.Client
+	ctx               = context.Background()
+	rateLimitDefaults map[string]Policy
+	rateLimits        = map[string]*rateLimit{}
+	rateLimitsMu      sync.RWMutex
+)
+
+// gcraScript admits a request if the bucket has room, returning
+// {allowed, ms the bucket is ahead of now, ms until a retry can succeed}
+var gcraScript = redis.NewScript(`
+local emission = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+local new_tat = tat + emission
+local allow_at = new_tat - tolerance
+if now < allow_at then
+	return {0, math.ceil(tat - now), math.ceil(allow_at - now)}
+end
+redis.call('SET', KEYS[1], tostring(new_tat), 'PX', math.ceil(new_tat - now))
+return {1, math.ceil(new_tat - now), 0}
+`)
+
+// Init registers the service's policies in rdb and keeps admin overrides up
+// to date. It must run before routes are set up.
+func Init(rdb *redis.Client, defaults map[string]Policy) {
+	client = rdb
+	rateLimitDefaults = defaults
+
+	// Published so admins can see which policies exist
+	for name, policy := range defaults {
+		data, _ := json.Marshal(policy)
+		if err := client.HSet(ctx, "rate_limit_defaults", name, data).Err(); err != nil {
+			log.Printf("Failed to publish rate limit policy %s: %v", name, err)
+		}
+	}
+
+	loadRateLimits()
+	go func() {
+		for range time.Tick(rateLimitRefreshInterval) {
+			loadRateLimits()
+		}
+	}()
+}
+
+// loadRateLimits applies overrides from Redis on top of the defaults. On Redis
+// errors the current policies are kept.
+func loadRateLimits() {
+	overrides, err := client.HGetAll(ctx, "rate_limit_policies").Result()
+	if err != nil {
+		log.Printf("Failed to load rate limit overrides: %v", err)
+		if len(rateLimits) > 0 {
+			return
+		}
+		overrides = nil
+	}
+
+	limits := map[string]*rateLimit{}
+	for name, policy := range rateLimitDefaults {
+		if raw, ok := overrides[name]; ok {
+			var override Policy
+			if err := json.Unmarshal([]byte(raw), &override); err != nil {
+				log.Printf("Ignoring rate limit override for %s: %v", name, err)
+			} else if limit, err := newRateLimit(override); err != nil {
+				log.Printf("Ignoring rate limit override for %s: %v", name, err)
+			} else {
+				limits[name] = limit
+				continue
+			}
+		}
+		limit, err := newRateLimit(policy)
+		if err != nil {
+			log.Fatalf("Invalid rate limit policy %s: %v", name, err)
+		}
+		limits[name] = limit
+	}
+
+	rateLimitsMu.Lock()
+	rateLimits = limits
+	rateLimitsMu.Unlock()
+}
+
+// ValidatePolicy fills in the burst and checks an enabled policy is usable,
+// returning its period
+func ValidatePolicy(policy *Policy) (time.Duration, error) {
+	period, err := time.ParseDuration(policy.Period)
+	if err != nil || period <= 0 {
+		return 0, errors.New("period must be a positive duration such as 1m")
+	}
+	if policy.Limit <= 0 {
+		return 0, errors.New("limit must be positive")
+	}
+	if policy.Burst == 0 {
+		policy.Burst = policy.Limit
+	} else if policy.Burst < 0 {
+		return 0, errors.New("burst must be positive")
+	}
+	switch policy.By {
+	case "ip", "user", "api_key":
+	default:
+		return 0, errors.New("by must be ip, user or api_key")
+	}
+	return period, nil
+}
+
+func newRateLimit(policy Policy) (*rateLimit, error) {
+	if policy.Disabled {
+		return &rateLimit{policy: policy}, nil
+	}
+	period, err := ValidatePolicy(&policy)
+	if err != nil {
+		return nil, err
+	}
+	emission := float64(period.Milliseconds()) / float64(policy.Limit)
+	return &rateLimit{
+		policy:    policy,
+		period:    period,
+		emission:  emission,
+		tolerance: emission * float64(policy.Burst),
+	}, nil
+}
+
+// rateLimitIdentity returns whose budget a request is charged to, and its kind
+func rateLimitIdentity(c *gin.Context, by string) (string, string) {
+	if by == "api_key" {
+		if keyID := c.GetString("api_key_id"); keyID != "" {
+			return "key:" + keyID, "api_key"
+		}
+	}
+	if by == "user" || by == "api_key" {
+		if userID := c.GetString("user_id"); userID != "" {
+			return "user:" + userID, "user"
+		}
+	}
+	return "ip:" + c.ClientIP(), "ip"
+}
+
+// Limit middleware enforces the named policy. Per-user and per-key policies
+// must come after auth.Middleware. Redis errors fail open; they are logged.
+func Limit(name string) gin.HandlerFunc {
+	if _, ok := rateLimitDefaults[name]; !ok {
+		log.Fatalf("Unknown rate limit policy %s", name)
+	}
+
+	return func(c *gin.Context) {
+		rateLimitsMu.RLock()
+		limit := rateLimits[name]
+		rateLimitsMu.RUnlock()
+		if limit.policy.Disabled {
+			c.Next()
+			return
+		}
+
+		identity, kind := rateLimitIdentity(c, limit.policy.By)
+		result, err := gcraScript.Run(ctx, client, []string{"rate_limit:" + name + ":" + identity},
+			limit.emission, limit.tolerance).Int64Slice()
+		if err != nil || len(result) != 3 {
+			log.Printf("Rate limit check failed for %s: %v", name, err)
+			c.Next()
+			return
+		}
+		allowed, ahead, retryAfter := result[0] == 1, float64(result[1]), float64(result[2])
+
+		remaining := int(math.Floor((limit.tolerance - ahead) / limit.emission))
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.policy.Burst))
+		c.Header("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil(ahead/1000))))
+		c.Header("RateLimit-Policy", strconv.Itoa(limit.policy.Limit)+";w="+strconv.Itoa(int(limit.period.Seconds()))+
+			";burst="+strconv.Itoa(limit.policy.Burst))
+
+		if !allowed {
+			rateLimitRejections.WithLabelValues(name, kind).Inc()
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter/1000))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// LimitWhen applies a policy only to requests matching cond, e.g. searches
+// in a mode that calls the embedding server
+func LimitWhen(name string, cond func(*gin.Context) bool) gin.HandlerFunc {
+	limit := Limit(name)
+	return func(c *gin.Context) {
+		if !cond(c) {
+			c.Next()
+			return
+		}
+		limit(c)
+	}
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var RedisClient *redis.Client
+var Ctx = context.Background()
+
+// InitRedis initializes the Redis client
+func InitRedis() {
+	redisHost := os.Getenv("REDIS_HOST")
+	if redisHost == "" {
+		redisHost = "localhost"
+	}
+
+	redisPort := os.Getenv("REDIS_PORT")
+	if redisPort == "" {
+		redisPort = "6379"
+	}
+
+	RedisClient = redis.NewClient(&redis.Options{
+		Addr:     redisHost + ":" + redisPort,
+		Password: os.Getenv("REDIS_PASSWORD"),
+		DB:       0,
+	})
+
+	// Test connection
+	if _, err := RedisClient.Ping(Ctx).Result(); err != nil {
+		log.Fatal("Failed to connect to Redis:", err)
+	}
+
+	log.Println("✅ Redis connected successfully")
+}
+
+// CloseRedis closes the Redis connection
+func CloseRedis() {
+	if RedisClient != nil {
+		RedisClient.Close()
+		log.Println("Redis connection closed")
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"path"
+
+	"github.com/job-portal/pkg/privacy"
+	"github.com/job-portal/user-service/config"
+	"github.com/job-portal/user-service/utils"
+)
+
+// PrivacyParticipant exports and erases a user's skills, uploaded files and the
+// text read from their resume. The rest of the profile lives in the users
+// table, which auth-service handles.
+type PrivacyParticipant struct{}
+
+func (PrivacyParticipant) Tables() ([]string, error) {
+	return []string{"user_skills"}, nil
+}
+
+func (PrivacyParticipant) Export(userID string) (*privacy.Export, error) {
+	skills, err := privacy.QueryRecords(config.DB, `
+		SELECT s.name, us.created_at
+		FROM user_skills us
+		JOIN skills s ON us.skill_id = s.id
+		WHERE us.user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resumeText, err := privacy.QueryRecords(config.DB, `
+		SELECT resume_text FROM users WHERE id = $1 AND resume_text IS NOT NULL
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &privacy.Export{Records: map[string]json.RawMessage{"skills": skills, "resume_text": resumeText}}
+	resumeURL, profilePicURL, err := uploadedFiles(userID)
+	if err != nil {
+		return nil, err
+	}
+	if resumeURL != "" {
+		export.Files = append(export.Files, privacy.File{Name: "resume" + path.Ext(resumeURL), URL: resumeURL})
+	}
+	if profilePicURL != "" {
+		export.Files = append(export.Files, privacy.File{Name: "profile_picture" + path.Ext(profilePicURL), URL: profilePicURL})
+	}
+	return export, nil
+}
+
+func (PrivacyParticipant) Erase(userID string) error {
+	if _, err := config.DB.Exec("DELETE FROM user_skills WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+
+	resumeURL, profilePicURL, err := uploadedFiles(userID)
+	if err != nil {
+		return err
+	}
+	for _, url := range []string{resumeURL, profilePicURL} {
+		if url == "" {
+			continue
+		}
+		err := utils.DeleteFromCloudinary(url)
+		if err == utils.ErrCloudinaryNotConfigured {
+			log.Printf("Privacy erase for user %s: not deleting %s: %v", userID, url, err)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// Cleared only once the files are gone, so a failed erase can find them again
+	_, err = config.DB.Exec(`
+		UPDATE users SET resume_url = NULL, resume_text = NULL, profile_pic_url = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, userID)
+	return err
+}
+
+// uploadedFiles returns the URLs of the user's resume and profile picture, if any
+func uploadedFiles(userID string) (string, string, error) {
+	var resumeURL, profilePicURL sql.NullString
+	err := config.DB.QueryRow("SELECT resume_url, profile_pic_url FROM users WHERE id = $1", userID).
+		Scan(&resumeURL, &profilePicURL)
+	if err == sql.ErrNoRows {
+		return "", "", nil
+	}
+	return resumeURL.String, profilePicURL.String, err
+}
@@ -21,14 +21,14 @@ func GetProfile(c *gin.Context) {
 	}
 
 	var user models.User
-	var bio, resumeURL, profilePicURL sql.NullString
+	var bio, resumeURL, resumeText, profilePicURL sql.NullString
 	query := `
-		SELECT id, name, email, phone, role, bio, resume_url, profile_pic_url, created_at, updated_at
+		SELECT id, name, email, phone, role, bio, resume_url, resume_text, profile_pic_url, created_at, updated_at
 		FROM users WHERE id = $1
 	`
 	err := config.DB.QueryRow(query, userID).Scan(
 		&user.ID, &user.Name, &user.Email, &user.Phone, &user.Role,
-		&bio, &resumeURL, &profilePicURL, &user.CreatedAt, &user.UpdatedAt,
+		&bio, &resumeURL, &resumeText, &profilePicURL, &user.CreatedAt, &user.UpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -46,6 +46,7 @@ func GetProfile(c *gin.Context) {
 	if resumeURL.Valid {
 		user.ResumeURL = resumeURL.String
 	}
+	user.ResumeText = resumeText.String
 	if profilePicURL.Valid {
 		user.ProfilePicURL = profilePicURL.String
 	}
@@ -85,6 +86,7 @@ func GetProfile(c *gin.Context) {
 		"role":                user.Role,
 		"bio":                 user.Bio,
 		"resume_url":          user.ResumeURL,
+		"resume_text":         user.ResumeText,
 		"profile_picture_url": user.ProfilePicURL,
 		"created_at":          user.CreatedAt,
 		"updated_at":          user.UpdatedAt,
@@ -117,16 +119,17 @@ func UpdateProfile(c *gin.Context) {
 		SET name = COALESCE(NULLIF($1, ''), name),
 		    phone = COALESCE(NULLIF($2, ''), phone),
 		    bio = COALESCE(NULLIF($3, ''), bio),
+		    resume_text = CASE WHEN $5::text IS NULL THEN resume_text ELSE NULLIF($5, '') END,
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE id = $4
-		RETURNING id, name, email, phone, role, bio, resume_url, profile_pic_url, created_at, updated_at
+		RETURNING id, name, email, phone, role, bio, resume_url, resume_text, profile_pic_url, created_at, updated_at
 	`
 
 	var user models.User
-	var bio, resumeURL, profilePicURL sql.NullString
-	err := config.DB.QueryRow(query, req.Name, req.Phone, req.Bio, userID).Scan(
+	var bio, resumeURL, resumeText, profilePicURL sql.NullString
+	err := config.DB.QueryRow(query, req.Name, req.Phone, req.Bio, userID, req.ResumeText).Scan(
 		&user.ID, &user.Name, &user.Email, &user.Phone, &user.Role,
-		&bio, &resumeURL, &profilePicURL, &user.CreatedAt, &user.UpdatedAt,
+		&bio, &resumeURL, &resumeText, &profilePicURL, &user.CreatedAt, &user.UpdatedAt,
 	)
 
 	if err != nil {
@@ -141,6 +144,7 @@ func UpdateProfile(c *gin.Context) {
 	if resumeURL.Valid {
 		user.ResumeURL = resumeURL.String
 	}
+	user.ResumeText = resumeText.String
 	if profilePicURL.Valid {
 		user.ProfilePicURL = profilePicURL.String
 	}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"database/sql"
+	"log"
 	"net/http"
 	"path/filepath"
 
@@ -40,10 +42,17 @@ func UploadResume(c *gin.Context) {
 		return
 	}
 
+	// Keep the resume's text for job recommendations. It replaces the text
+	// of the previous resume, so formats it can't be read from clear it.
+	resumeText, err := utils.ExtractResumeText(file)
+	if err != nil {
+		log.Printf("Failed to read resume text for user %s: %v", userID, err)
+	}
+
 	// Update user resume URL
 	_, err = config.DB.Exec(
-		"UPDATE users SET resume_url = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2",
-		uploadURL, userID,
+		"UPDATE users SET resume_url = $1, resume_text = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3",
+		uploadURL, sql.NullString{String: resumeText, Valid: resumeText != ""}, userID,
 	)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update resume URL"})
@@ -51,8 +60,9 @@ func UploadResume(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":    "Resume uploaded successfully",
-		"resume_url": uploadURL,
+		"message":               "Resume uploaded successfully",
+		"resume_url":            uploadURL,
+		"resume_text_extracted": resumeText != "",
 	})
 }
 
@@ -3,14 +3,24 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/job-portal/pkg/auth"
+	"github.com/job-portal/pkg/privacy"
+	"github.com/job-portal/pkg/ratelimit"
 	"github.com/job-portal/user-service/config"
 	"github.com/job-portal/user-service/handlers"
 	"github.com/job-portal/user-service/middleware"
 	"github.com/joho/godotenv"
 )
 
+// Rate limit policies; admins can override them at runtime (see pkg/ratelimit)
+var rateLimitPolicies = map[string]ratelimit.Policy{
+	"user-service:default": {Limit: 600, Period: "1m", Burst: 100, By: "ip"},
+	"user-service:uploads": {Limit: 20, Period: "1h", Burst: 5, By: "user"}, // Cloudinary uploads
+}
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load("../../.env"); err != nil {
@@ -21,15 +31,39 @@ func main() {
 	config.InitDB()
 	defer config.CloseDB()
 
+	// Initialize Redis connection (token revocation checks)
+	config.InitRedis()
+	defer config.CloseRedis()
+
+	// Load rate limit policies and admin overrides
+	ratelimit.Init(config.RedisClient, rateLimitPolicies)
+
+	// Verify tokens against auth-service's public signing keys
+	auth.Init(auth.Config{Service: "user-service", Redis: config.RedisClient})
+
 	// Set up Gin router
 	router := gin.Default()
 
+	// Only trust X-Forwarded-For from known proxies (by default the frontend
+	// server on a local or private network), otherwise clients could spoof the
+	// IP their rate limits are counted against
+	proxies := os.Getenv("TRUSTED_PROXIES")
+	if proxies == "" {
+		proxies = "127.0.0.1,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"
+	}
+	if err := router.SetTrustedProxies(strings.Split(proxies, ",")); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
 	// CORS middleware
 	router.Use(middleware.CORSMiddleware())
 
 	// Prometheus metrics middleware
 	router.Use(middleware.PrometheusMiddleware())
 
+	// Default per-IP rate limit; expensive routes add their own
+	router.Use(ratelimit.Limit("user-service:default"))
+
 	// Start metrics server on separate port
 	middleware.StartMetricsServer("9102")
 	log.Println("📊 Metrics server started on port 9102")
@@ -41,7 +75,7 @@ func main() {
 
 	// User routes (protected)
 	users := router.Group("/api/users")
-	users.Use(middleware.AuthMiddleware())
+	users.Use(auth.Middleware())
 	{
 		// Profile endpoints
 		users.GET("/:id", handlers.GetProfile)
@@ -49,17 +83,26 @@ func main() {
 
 		// Skills endpoints
 		users.POST("/:id/skills", handlers.AddSkills)
-		users.DELETE("/:id/skills/:skillId", handlers.RemoveSkill)
+		users.DELETE("/:id/skills/:skillId", auth.NotImpersonated(), handlers.RemoveSkill)
 		users.GET("/:id/skills", handlers.GetUserSkills)
 
 		// File upload endpoints
-		users.POST("/upload-resume", handlers.UploadResume)
-		users.POST("/upload-profile-pic", handlers.UploadProfilePic)
+		uploads := ratelimit.Limit("user-service:uploads")
+		users.POST("/upload-resume", uploads, handlers.UploadResume)
+		users.POST("/upload-profile-pic", uploads, handlers.UploadProfilePic)
 	}
 
 	// Public skill search
 	router.GET("/api/skills", handlers.SearchSkills)
 
+	// Internal API for other services, on its own port
+	internal := auth.NewInternalRouter(middleware.PrometheusMiddleware())
+
+	// Data export and erasure for auth-service
+	privacy.Register(internal, handlers.PrivacyParticipant{})
+
+	auth.StartInternalServer(internal, "USER_SERVICE_INTERNAL_PORT", "8102")
+
 	// Start server
 	port := os.Getenv("USER_SERVICE_PORT")
 	if port == "" {
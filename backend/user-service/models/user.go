@@ -10,15 +10,17 @@ type User struct {
 	Role          string    `json:"role" db:"role"`
 	Bio           string    `json:"bio,omitempty" db:"bio"`
 	ResumeURL     string    `json:"resume_url,omitempty" db:"resume_url"`
+	ResumeText    string    `json:"resume_text,omitempty" db:"resume_text"` // Read from a DOCX upload or pasted; used for job recommendations
 	ProfilePicURL string    `json:"profile_pic_url,omitempty" db:"profile_pic_url"`
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 }
 
 type UpdateProfileRequest struct {
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
-	Bio   string `json:"bio"`
+	Name       string  `json:"name"`
+	Phone      string  `json:"phone"`
+	Bio        string  `json:"bio"`
+	ResumeText *string `json:"resume_text" binding:"omitempty,max=20000"` // "" clears it
 }
 
 type Skill struct {
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"mime/multipart"
@@ -79,3 +80,73 @@ func UploadToCloudinary(file *multipart.FileHeader, folder string) (string, erro
 
 	return uploadResult.SecureURL, nil
 }
+
+// ErrCloudinaryNotConfigured is returned when the Cloudinary credentials are missing
+var ErrCloudinaryNotConfigured = errors.New("Cloudinary credentials not configured")
+
+// DeleteFromCloudinary destroys the file behind a URL returned by UploadToCloudinary.
+// Files that are already gone are not an error.
+func DeleteFromCloudinary(fileURL string) error {
+	cldName := os.Getenv("CLOUDINARY_CLOUD_NAME")
+	cldKey := os.Getenv("CLOUDINARY_API_KEY")
+	cldSecret := os.Getenv("CLOUDINARY_API_SECRET")
+
+	if cldName == "" || cldKey == "" || cldSecret == "" {
+		return ErrCloudinaryNotConfigured
+	}
+
+	resourceType, publicID, err := parseCloudinaryURL(fileURL)
+	if err != nil {
+		return err
+	}
+
+	cld, err := cloudinary.NewFromParams(cldName, cldKey, cldSecret)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	invalidate := true
+	result, err := cld.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID:     publicID,
+		ResourceType: resourceType,
+		Invalidate:   &invalidate,
+	})
+	if err != nil {
+		return err
+	}
+	if result.Result != "ok" && result.Result != "not found" {
+		return fmt.Errorf("Cloudinary destroy %s: %s", publicID, result.Result)
+	}
+
+	log.Printf("Cloudinary file deleted - PublicID: %s, ResourceType: %s", publicID, resourceType)
+	return nil
+}
+
+// parseCloudinaryURL extracts the resource type and public ID from a delivery URL like
+// https://res.cloudinary.com/<cloud>/<type>/upload/v<version>/<folder>/<name>.<ext>
+func parseCloudinaryURL(fileURL string) (string, string, error) {
+	parts := strings.SplitN(fileURL, "/upload/", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("not a Cloudinary upload URL: %s", fileURL)
+	}
+
+	resourceType := filepath.Base(parts[0])
+	publicID := parts[1]
+	// Drop the version segment
+	if segments := strings.SplitN(publicID, "/", 2); len(segments) == 2 && isVersion(segments[0]) {
+		publicID = segments[1]
+	}
+	// Raw files keep their extension in the public ID; images and videos don't
+	if resourceType != "raw" {
+		publicID = strings.TrimSuffix(publicID, filepath.Ext(publicID))
+	}
+	return resourceType, publicID, nil
+}
+
+// isVersion reports whether a URL segment is a Cloudinary version such as v1712345678
+func isVersion(segment string) bool {
+	return len(segment) > 1 && segment[0] == 'v' && strings.Trim(segment[1:], "0123456789") == ""
+}
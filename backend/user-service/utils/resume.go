@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+)
+
+// MaxResumeText caps the resume text kept for job recommendations
+const MaxResumeText = 20000
+
+// ExtractResumeText reads the text of an uploaded resume, for job-service to
+// match jobs against. Only DOCX is supported; other formats return "" and
+// users can paste their resume text into their profile instead.
+func ExtractResumeText(file *multipart.FileHeader) (string, error) {
+	if strings.ToLower(filepath.Ext(file.Filename)) != ".docx" {
+		return "", nil
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("invalid DOCX: %w", err)
+	}
+	for _, entry := range archive.File {
+		if entry.Name == "word/document.xml" {
+			document, err := entry.Open()
+			if err != nil {
+				return "", err
+			}
+			defer document.Close()
+			return docxText(document)
+		}
+	}
+	return "", fmt.Errorf("invalid DOCX: no word/document.xml")
+}
+
+// docxText collects the text runs (w:t) of a WordprocessingML document, one
+// line per paragraph
+func docxText(r io.Reader) (string, error) {
+	var b strings.Builder
+	decoder := xml.NewDecoder(r)
+	inText := false
+	for b.Len() < MaxResumeText {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", fmt.Errorf("invalid DOCX: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteString("\t")
+			case "br":
+				b.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+
+	text := strings.TrimSpace(b.String())
+	if len(text) > MaxResumeText {
+		text = strings.ToValidUTF8(text[:MaxResumeText], "")
+	}
+	return text, nil
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -14,7 +15,7 @@ type SendEmailRequest struct {
 	Type    string `json:"type"`
 }
 
-// SendEmail sends an email via Kafka (for testing)
+// SendEmail queues an email on Kafka on behalf of another service (internal router only)
 func SendEmail(c *gin.Context) {
 	var req SendEmailRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -38,6 +39,8 @@ func SendEmail(c *gin.Context) {
 		return
 	}
 
+	log.Printf("Email of type %s queued for %s by %s", req.Type, req.To, c.GetString("service_name"))
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Email event sent to Kafka successfully",
 	})
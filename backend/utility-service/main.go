@@ -3,21 +3,38 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/job-portal/pkg/auth"
+	"github.com/job-portal/pkg/ratelimit"
 	"github.com/job-portal/utility-service/ai"
+	"github.com/job-portal/utility-service/config"
 	"github.com/job-portal/utility-service/handlers"
 	"github.com/job-portal/utility-service/kafka"
 	"github.com/job-portal/utility-service/middleware"
 	"github.com/joho/godotenv"
 )
 
+// Rate limit policies; admins can override them at runtime (see pkg/ratelimit)
+var rateLimitPolicies = map[string]ratelimit.Policy{
+	"utility-service:default": {Limit: 600, Period: "1m", Burst: 100, By: "ip"},
+	"utility-service:ai":      {Limit: 20, Period: "1h", Burst: 5, By: "user"}, // Calls Gemini
+}
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load("../../.env"); err != nil {
 		log.Println("No .env file found, using system environment variables")
 	}
 
+	// Initialize Redis connection (token revocation checks)
+	config.InitRedis()
+	defer config.CloseRedis()
+
+	// Load rate limit policies and admin overrides
+	ratelimit.Init(config.RedisClient, rateLimitPolicies)
+
 	// Initialize Gemini AI client
 	ai.InitGemini()
 
@@ -28,15 +45,32 @@ func main() {
 	// Start Kafka consumer in background
 	go kafka.StartEmailConsumer()
 
+	// Verify tokens against auth-service's public signing keys
+	auth.Init(auth.Config{Service: "utility-service", Redis: config.RedisClient})
+
 	// Set up Gin router
 	router := gin.Default()
 
+	// Only trust X-Forwarded-For from known proxies (by default the frontend
+	// server on a local or private network), otherwise clients could spoof the
+	// IP their rate limits are counted against
+	proxies := os.Getenv("TRUSTED_PROXIES")
+	if proxies == "" {
+		proxies = "127.0.0.1,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"
+	}
+	if err := router.SetTrustedProxies(strings.Split(proxies, ",")); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
 	// CORS middleware
 	router.Use(middleware.CORSMiddleware())
 
 	// Prometheus metrics middleware
 	router.Use(middleware.PrometheusMiddleware())
 
+	// Default per-IP rate limit; expensive routes add their own
+	router.Use(ratelimit.Limit("utility-service:default"))
+
 	// Start metrics server on separate port
 	middleware.StartMetricsServer("9104")
 	log.Println("📊 Metrics server started on port 9104")
@@ -48,17 +82,20 @@ func main() {
 
 	// AI endpoints (require authentication)
 	aiRoutes := router.Group("/api/ai")
-	aiRoutes.Use(middleware.AuthMiddleware())
+	aiRoutes.Use(auth.Middleware(), ratelimit.Limit("utility-service:ai"))
 	{
 		aiRoutes.POST("/career-guide", handlers.CareerGuide)
 		aiRoutes.POST("/resume-analyze", handlers.ResumeAnalyze)
 	}
 
-	// Email endpoints (for testing/manual triggers)
-	emailRoutes := router.Group("/api/email")
+	// Internal API for other services, on its own port
+	internal := auth.NewInternalRouter(middleware.PrometheusMiddleware())
+	emailRoutes := internal.Group("/internal/email")
+	emailRoutes.Use(auth.ServiceAuth("email", "auth-service", "user-service", "job-service", "blog-service"))
 	{
-		emailRoutes.POST("/send", handlers.SendEmail) // For testing
+		emailRoutes.POST("/send", handlers.SendEmail)
 	}
+	auth.StartInternalServer(internal, "UTILITY_SERVICE_INTERNAL_PORT", "8104")
 
 	// Start server
 	port := os.Getenv("UTILITY_SERVICE_PORT")
@@ -12,7 +12,7 @@ DROP TYPE IF EXISTS job_type CASCADE;
 DROP TYPE IF EXISTS user_role CASCADE;
 
 -- Create ENUM types
-CREATE TYPE user_role AS ENUM ('jobseeker', 'recruiter');
+CREATE TYPE user_role AS ENUM ('jobseeker', 'recruiter', 'admin');
 CREATE TYPE job_type AS ENUM ('full-time', 'part-time', 'contract', 'internship');
 CREATE TYPE work_location AS ENUM ('remote', 'onsite', 'hybrid');
 CREATE TYPE application_status AS ENUM ('pending', 'viewed', 'shortlisted', 'interviewed', 'offered', 'rejected');
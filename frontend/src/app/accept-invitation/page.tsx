@@ -0,0 +1,115 @@
+"use client";
+
+import { Suspense, useState } from "react";
+import Link from "next/link";
+import { useRouter, useSearchParams } from "next/navigation";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Card, CardContent, CardDescription, CardFooter, CardHeader, CardTitle } from "@/components/ui/card";
+import { toast } from "sonner";
+import { authApi } from "@/lib/api";
+
+function AcceptInvitation() {
+  const router = useRouter();
+  const searchParams = useSearchParams();
+  const token = searchParams.get("token") || "";
+  const [formData, setFormData] = useState({ name: "", password: "", confirmPassword: "" });
+  const [isLoading, setIsLoading] = useState(false);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+
+    if (formData.password !== formData.confirmPassword) {
+      toast.error("Passwords don't match");
+      return;
+    }
+
+    setIsLoading(true);
+
+    try {
+      await authApi.post("/api/auth/invitations/accept", {
+        token,
+        name: formData.name,
+        password: formData.password,
+      });
+      toast.success("Account created!", {
+        description: "You can now sign in with your new account",
+      });
+      router.push("/login");
+    } catch (error: any) {
+      toast.error("Failed to accept invitation", {
+        description: error.response?.data?.error || "The invitation may have expired, please ask for a new one",
+      });
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  return (
+    <div className="flex min-h-screen items-center justify-center bg-gradient-to-br from-blue-50 to-indigo-100 dark:from-gray-900 dark:to-gray-800 p-4">
+      <Card className="w-full max-w-md">
+        <CardHeader className="space-y-1">
+          <CardTitle className="text-2xl font-bold">Accept invitation</CardTitle>
+          <CardDescription>
+            {token ? "Set up your account to get started" : "This invitation link is invalid"}
+          </CardDescription>
+        </CardHeader>
+        <form onSubmit={handleSubmit}>
+          <CardContent className="space-y-4">
+            <div className="space-y-2">
+              <Label htmlFor="name">Full name</Label>
+              <Input
+                id="name"
+                value={formData.name}
+                onChange={(e) => setFormData({ ...formData, name: e.target.value })}
+                required
+                disabled={!token}
+              />
+            </div>
+            <div className="space-y-2">
+              <Label htmlFor="password">Password</Label>
+              <Input
+                id="password"
+                type="password"
+                value={formData.password}
+                onChange={(e) => setFormData({ ...formData, password: e.target.value })}
+                minLength={6}
+                required
+                disabled={!token}
+              />
+            </div>
+            <div className="space-y-2">
+              <Label htmlFor="confirmPassword">Confirm password</Label>
+              <Input
+                id="confirmPassword"
+                type="password"
+                value={formData.confirmPassword}
+                onChange={(e) => setFormData({ ...formData, confirmPassword: e.target.value })}
+                minLength={6}
+                required
+                disabled={!token}
+              />
+            </div>
+          </CardContent>
+          <CardFooter className="flex flex-col space-y-4">
+            <Button type="submit" className="w-full" disabled={isLoading || !token}>
+              {isLoading ? "Creating account..." : "Create account"}
+            </Button>
+            <Link href="/login" className="text-sm text-center text-blue-600 hover:underline dark:text-blue-400">
+              Already have an account? Sign in
+            </Link>
+          </CardFooter>
+        </form>
+      </Card>
+    </div>
+  );
+}
+
+export default function AcceptInvitationPage() {
+  return (
+    <Suspense>
+      <AcceptInvitation />
+    </Suspense>
+  );
+}
@@ -2,6 +2,34 @@ import NextAuth from "next-auth";
 import CredentialsProvider from "next-auth/providers/credentials";
 import { JWT } from "next-auth/jwt";
 
+// Exchange the backend refresh token for a new access/refresh token pair
+async function refreshAccessToken(token: JWT): Promise<JWT> {
+  try {
+    const res = await fetch(`${process.env.BACKEND_AUTH_URL}/api/auth/refresh`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ refresh_token: token.refreshToken }),
+    });
+
+    const data = await res.json();
+    if (!res.ok || !data.token) {
+      throw data;
+    }
+
+    return {
+      ...token,
+      role: data.user.role,
+      accessToken: data.token,
+      refreshToken: data.refresh_token,
+      accessTokenExpires: Date.now() + data.expires_in * 1000,
+      error: undefined,
+    };
+  } catch (error) {
+    console.error("Token refresh error:", error);
+    return { ...token, error: "RefreshAccessTokenError" };
+  }
+}
+
 export const authOptions = {
   providers: [
     CredentialsProvider({
@@ -9,26 +37,86 @@ export const authOptions = {
       credentials: {
         email: { label: "Email", type: "email" },
         password: { label: "Password", type: "password" },
+        code: { label: "Authentication code", type: "text" },
+        oauth_code: { label: "Social login code", type: "text" },
+        magic_token: { label: "Magic link token", type: "text" },
+        mfa_token: { label: "Pending 2FA token", type: "text" },
       },
-      async authorize(credentials) {
-        if (!credentials?.email || !credentials?.password) {
+      async authorize(credentials, req) {
+        const socialLogin = Boolean(credentials?.oauth_code || credentials?.magic_token || credentials?.mfa_token);
+        if (!socialLogin && (!credentials?.email || !credentials?.password)) {
           return null;
         }
 
+        // Pass on the browser's IP (for login rate limits) and user agent (for the session list)
+        const forwardedFor = req?.headers?.["x-forwarded-for"];
+        const userAgent = req?.headers?.["user-agent"];
+        const cookie = req?.headers?.cookie;
+        const clientHeaders = {
+          "Content-Type": "application/json",
+          ...(forwardedFor ? { "X-Forwarded-For": forwardedFor } : {}),
+          ...(userAgent ? { "User-Agent": userAgent } : {}),
+        };
+
         try {
-          // Call your backend auth API
-          const res = await fetch(`${process.env.BACKEND_AUTH_URL}/api/auth/login`, {
-            method: "POST",
-            headers: { "Content-Type": "application/json" },
-            body: JSON.stringify({
-              email: credentials.email,
-              password: credentials.password,
-            }),
-          });
+          let data;
+          if (credentials?.mfa_token) {
+            // Finishing a social login that stopped at the 2FA step
+            data = { mfa_required: true, mfa_token: credentials.mfa_token };
+          } else {
+            // Call your backend auth API
+            const res = credentials?.oauth_code
+              ? await fetch(`${process.env.BACKEND_AUTH_URL}/api/auth/oauth/exchange`, {
+                  method: "POST",
+                  headers: clientHeaders,
+                  body: JSON.stringify({ code: credentials.oauth_code }),
+                })
+              : credentials?.magic_token
+              ? // The link is bound to the nonce cookie set when it was requested
+                await fetch(`${process.env.BACKEND_AUTH_URL}/api/auth/magic-link/verify`, {
+                  method: "POST",
+                  headers: { ...clientHeaders, ...(cookie ? { Cookie: cookie } : {}) },
+                  body: JSON.stringify({ token: credentials.magic_token }),
+                })
+              : await fetch(`${process.env.BACKEND_AUTH_URL}/api/auth/login`, {
+                  method: "POST",
+                  headers: clientHeaders,
+                  body: JSON.stringify({
+                    email: credentials?.email,
+                    password: credentials?.password,
+                  }),
+                });
 
-          const data = await res.json();
+            data = await res.json();
+            if (!res.ok) {
+              if (credentials?.magic_token && res.status === 401) {
+                throw new Error("MAGIC_LINK_INVALID");
+              }
+              return null;
+            }
+          }
+
+          // Two-step login: trade the mfa_pending token and code for the session
+          if (data.mfa_required) {
+            if (data.mfa_enrollment_required) {
+              throw new Error("MFA_ENROLLMENT_REQUIRED");
+            }
+            if (!credentials?.code) {
+              // A social login code is single-use, so hand back the pending token to retry with
+              throw new Error(socialLogin ? `MFA_REQUIRED:${data.mfa_token}` : "MFA_REQUIRED");
+            }
+            const mfaRes = await fetch(`${process.env.BACKEND_AUTH_URL}/api/auth/mfa/verify`, {
+              method: "POST",
+              headers: clientHeaders,
+              body: JSON.stringify({ mfa_token: data.mfa_token, code: credentials.code }),
+            });
+            if (!mfaRes.ok) {
+              throw new Error("MFA_INVALID_CODE");
+            }
+            data = await mfaRes.json();
+          }
 
-          if (res.ok && data.token) {
+          if (data.token) {
             // Return user object with token
             return {
               id: data.user.id,
@@ -36,11 +124,16 @@ export const authOptions = {
               name: data.user.name,
               role: data.user.role,
               accessToken: data.token,
+              refreshToken: data.refresh_token,
+              accessTokenExpires: Date.now() + data.expires_in * 1000,
             };
           }
 
           return null;
         } catch (error) {
+          if (error instanceof Error && /^(MFA_|MAGIC_LINK_)/.test(error.message)) {
+            throw error;
+          }
           console.error("Auth error:", error);
           return null;
         }
@@ -53,25 +146,50 @@ export const authOptions = {
         token.id = user.id;
         token.role = user.role;
         token.accessToken = user.accessToken;
+        token.refreshToken = user.refreshToken;
+        token.accessTokenExpires = user.accessTokenExpires;
+        return token;
+      }
+
+      // Refresh a minute before the access token expires
+      if (Date.now() < token.accessTokenExpires - 60 * 1000) {
+        return token;
       }
-      return token;
+      return refreshAccessToken(token);
     },
     async session({ session, token }: { session: any; token: JWT }) {
       if (token) {
         session.user.id = token.id;
         session.user.role = token.role;
         session.accessToken = token.accessToken;
+        session.error = token.error;
       }
       return session;
     },
   },
+  events: {
+    async signOut({ token }: { token: JWT }) {
+      if (!token?.refreshToken) {
+        return;
+      }
+      try {
+        await fetch(`${process.env.BACKEND_AUTH_URL}/api/auth/logout`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ refresh_token: token.refreshToken }),
+        });
+      } catch (error) {
+        console.error("Logout error:", error);
+      }
+    },
+  },
   pages: {
     signIn: "/login",
     error: "/login",
   },
   session: {
     strategy: "jwt" as const,
-    maxAge: 24 * 60 * 60, // 24 hours
+    maxAge: 7 * 24 * 60 * 60, // 7 days, matches the backend refresh token lifetime
   },
   secret: process.env.NEXTAUTH_SECRET,
 };
@@ -0,0 +1,30 @@
+import { NextRequest, NextResponse } from "next/server";
+
+// Magic links only work in the browser that requested them. auth-service binds
+// the link to a nonce cookie, so the request goes through this route to set
+// that cookie on the frontend's own origin, where NextAuth can forward it.
+export async function POST(req: NextRequest) {
+  const forwardedFor = req.headers.get("x-forwarded-for");
+  const userAgent = req.headers.get("user-agent");
+
+  const res = await fetch(`${process.env.BACKEND_AUTH_URL}/api/auth/magic-link`, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      ...(forwardedFor ? { "X-Forwarded-For": forwardedFor } : {}),
+      ...(userAgent ? { "User-Agent": userAgent } : {}),
+    },
+    body: JSON.stringify(await req.json()),
+  });
+
+  const response = NextResponse.json(await res.json(), { status: res.status });
+  const cookie = res.headers.get("set-cookie");
+  if (cookie) {
+    response.headers.set("Set-Cookie", cookie);
+  }
+  const retryAfter = res.headers.get("retry-after");
+  if (retryAfter) {
+    response.headers.set("Retry-After", retryAfter);
+  }
+  return response;
+}
@@ -47,19 +47,13 @@ export default function JobsPage() {
       if (filters.location) params.location = filters.location;
       if (filters.job_type !== "all") params.job_type = filters.job_type;
       if (filters.work_location !== "all") params.work_location = filters.work_location;
+      // Hybrid search fuses keyword and semantic matches under the same filters
+      if (filters.isSemantic && filters.keyword) params.mode = "hybrid";
 
-      let response;
-      if (filters.isSemantic && filters.keyword) {
-        response = await jobApi.get("/api/jobs/semantic", {
-          params: { q: filters.keyword },
-        });
-      } else {
-        response = await jobApi.get("/api/jobs", { params });
-      }
+      const response = await jobApi.get("/api/jobs", { params });
 
       setJobs(response.data.jobs || []);
-      const total = response.data.count || 0;
-      setTotalPages(Math.ceil(total / 20) || 1);
+      setTotalPages(response.data.total_pages || 1);
     } catch (error) {
       console.error("Failed to fetch jobs:", error);
       toast.error("Failed to load jobs", {
@@ -9,8 +9,9 @@ import { Button } from "@/components/ui/button";
 import { Input } from "@/components/ui/input";
 import { Label } from "@/components/ui/label";
 import { toast } from "sonner";
-import { Mail, Lock, ArrowRight, Loader2, Brain, Sparkles, Shield, Zap } from "lucide-react";
+import { Mail, Lock, ArrowRight, Loader2, Brain, Sparkles, Shield, Zap, KeyRound } from "lucide-react";
 import { Logo } from "@/components/ui/Logo";
+import { authApi, API_URLS } from "@/lib/api";
 
 export default function LoginPage() {
   const { data: session, status } = useSession();
@@ -19,7 +20,17 @@ export default function LoginPage() {
   const [formData, setFormData] = useState({
     email: "",
     password: "",
+    code: "",
   });
+  const [mfaStep, setMfaStep] = useState(false);
+  const [oauthProviders, setOauthProviders] = useState<string[]>([]);
+
+  useEffect(() => {
+    authApi
+      .get("/api/auth/oauth/providers")
+      .then((res) => setOauthProviders(res.data.providers || []))
+      .catch(() => setOauthProviders([]));
+  }, []);
 
   useEffect(() => {
     if (status === "authenticated") {
@@ -36,9 +47,20 @@ export default function LoginPage() {
         redirect: false,
         email: formData.email,
         password: formData.password,
+        code: mfaStep ? formData.code : "",
       });
 
-      if (result?.error) {
+      if (result?.error === "MFA_REQUIRED") {
+        setMfaStep(true);
+      } else if (result?.error === "MFA_INVALID_CODE") {
+        toast.error("Login failed", {
+          description: "Invalid authentication code",
+        });
+      } else if (result?.error === "MFA_ENROLLMENT_REQUIRED") {
+        toast.error("Two-factor authentication required", {
+          description: "Your account must set up two-factor authentication. Please contact an administrator.",
+        });
+      } else if (result?.error) {
         toast.error("Login failed", {
           description: "Invalid email or password",
         });
@@ -188,6 +210,30 @@ export default function LoginPage() {
                   />
                 </div>
               </div>
+
+              {mfaStep && (
+                <div className="space-y-2">
+                  <Label htmlFor="code" className="text-gray-700 dark:text-gray-300">Authentication code</Label>
+                  <div className="relative">
+                    <KeyRound className="absolute left-3 top-3.5 h-4 w-4 text-gray-400" />
+                    <Input
+                      id="code"
+                      type="text"
+                      inputMode="text"
+                      autoComplete="one-time-code"
+                      placeholder="123456 or recovery code"
+                      value={formData.code}
+                      onChange={(e) => setFormData({ ...formData, code: e.target.value })}
+                      className="pl-10 h-12 bg-white dark:bg-white/5 border-gray-200 dark:border-white/10 rounded-xl focus:ring-2 focus:ring-indigo-500/20"
+                      autoFocus
+                      required
+                    />
+                  </div>
+                  <p className="text-xs text-gray-500 dark:text-gray-400">
+                    Enter the code from your authenticator app, or one of your recovery codes.
+                  </p>
+                </div>
+              )}
             </div>
 
             <Button 
@@ -209,6 +255,32 @@ export default function LoginPage() {
             </Button>
           </form>
 
+          <Link href="/magic-link" className="block">
+            <Button type="button" variant="outline" className="w-full h-11 rounded-xl">
+              <Mail className="mr-2 h-4 w-4" />
+              Email me a sign-in link
+            </Button>
+          </Link>
+
+          {oauthProviders.length > 0 && (
+            <div className="space-y-3">
+              <div className="flex items-center gap-3 text-xs uppercase text-gray-400">
+                <div className="h-px flex-1 bg-gray-200 dark:bg-gray-700" />
+                <span>or continue with</span>
+                <div className="h-px flex-1 bg-gray-200 dark:bg-gray-700" />
+              </div>
+              <div className="grid gap-3" style={{ gridTemplateColumns: `repeat(${Math.min(oauthProviders.length, 3)}, minmax(0, 1fr))` }}>
+                {oauthProviders.map((provider) => (
+                  <a key={provider} href={`${API_URLS.auth}/api/auth/oauth/${provider}/login`}>
+                    <Button type="button" variant="outline" className="w-full h-11 rounded-xl capitalize">
+                      {provider === "github" ? "GitHub" : provider}
+                    </Button>
+                  </a>
+                ))}
+              </div>
+            </div>
+          )}
+
           <div className="text-center text-sm">
             <span className="text-gray-500 dark:text-gray-400">Don&apos;t have an account? </span>
             <Link href="/register" className="font-semibold text-indigo-600 dark:text-indigo-400 hover:underline">
@@ -0,0 +1,198 @@
+"use client";
+
+import { Suspense, useEffect, useRef, useState } from "react";
+import Link from "next/link";
+import { signIn } from "next-auth/react";
+import { useRouter, useSearchParams } from "next/navigation";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Card, CardContent, CardDescription, CardFooter, CardHeader, CardTitle } from "@/components/ui/card";
+import { toast } from "sonner";
+
+const ERROR_MESSAGES: Record<string, string> = {
+  MAGIC_LINK_INVALID:
+    "This link has expired, was already used, or was opened in a different browser from the one you requested it in.",
+  MFA_ENROLLMENT_REQUIRED: "Your account must set up two-factor authentication. Please contact an administrator.",
+};
+
+function MagicLink() {
+  const router = useRouter();
+  const searchParams = useSearchParams();
+  const token = searchParams.get("token");
+  const started = useRef(false);
+  const [email, setEmail] = useState("");
+  const [isSubmitted, setIsSubmitted] = useState(false);
+  const [error, setError] = useState("");
+  const [mfaToken, setMfaToken] = useState("");
+  const [code, setCode] = useState("");
+  const [isLoading, setIsLoading] = useState(false);
+
+  const finish = async (credentials: Record<string, string>) => {
+    const result = await signIn("credentials", { redirect: false, ...credentials });
+
+    if (result?.error?.startsWith("MFA_REQUIRED:")) {
+      setMfaToken(result.error.slice("MFA_REQUIRED:".length));
+    } else if (result?.error === "MFA_INVALID_CODE") {
+      toast.error("Invalid authentication code");
+    } else if (result?.error) {
+      setError(result.error);
+    } else {
+      router.refresh();
+      router.push("/dashboard");
+    }
+  };
+
+  useEffect(() => {
+    // The link is single-use, so don't redeem it twice in development's double effects
+    if (!token || started.current) return;
+    started.current = true;
+    finish({ magic_token: token });
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [token]);
+
+  const requestLink = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setIsLoading(true);
+    try {
+      const res = await fetch("/api/magic-link", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ email }),
+      });
+      if (res.status === 429) {
+        toast.error("Too many requests", { description: "Please wait a while before asking for another link" });
+        return;
+      }
+      if (!res.ok) {
+        throw new Error();
+      }
+      setIsSubmitted(true);
+    } catch {
+      toast.error("Failed to send sign-in link", { description: "Please try again later" });
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  const verifyCode = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setIsLoading(true);
+    try {
+      await finish({ mfa_token: mfaToken, code });
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  let content;
+  if (error) {
+    content = (
+      <>
+        <CardHeader className="space-y-1">
+          <CardTitle className="text-2xl font-bold">Sign-in failed</CardTitle>
+          <CardDescription>
+            {ERROR_MESSAGES[error] || "We couldn't sign you in with this link. Please request a new one."}
+          </CardDescription>
+        </CardHeader>
+        <CardFooter>
+          <Link href="/magic-link" className="w-full">
+            <Button className="w-full">Request a new link</Button>
+          </Link>
+        </CardFooter>
+      </>
+    );
+  } else if (mfaToken) {
+    content = (
+      <form onSubmit={verifyCode}>
+        <CardHeader className="space-y-1">
+          <CardTitle className="text-2xl font-bold">Two-factor authentication</CardTitle>
+          <CardDescription>Enter the code from your authenticator app, or a recovery code</CardDescription>
+        </CardHeader>
+        <CardContent className="space-y-2">
+          <Label htmlFor="code">Authentication code</Label>
+          <Input
+            id="code"
+            autoComplete="one-time-code"
+            value={code}
+            onChange={(e) => setCode(e.target.value)}
+            autoFocus
+            required
+          />
+        </CardContent>
+        <CardFooter>
+          <Button type="submit" className="w-full" disabled={isLoading}>
+            {isLoading ? "Verifying..." : "Verify"}
+          </Button>
+        </CardFooter>
+      </form>
+    );
+  } else if (token) {
+    content = (
+      <CardHeader className="space-y-1">
+        <CardTitle className="text-2xl font-bold">Signing you in...</CardTitle>
+      </CardHeader>
+    );
+  } else if (isSubmitted) {
+    content = (
+      <>
+        <CardHeader className="space-y-1">
+          <CardTitle className="text-2xl font-bold">Check your email</CardTitle>
+          <CardDescription>We&apos;ve sent a sign-in link to {email}</CardDescription>
+        </CardHeader>
+        <CardContent>
+          <p className="text-sm text-gray-600 dark:text-gray-400">
+            Open the link in this browser to sign in. It works once and expires in 10 minutes.
+          </p>
+        </CardContent>
+        <CardFooter>
+          <Link href="/login" className="w-full">
+            <Button className="w-full">Back to login</Button>
+          </Link>
+        </CardFooter>
+      </>
+    );
+  } else {
+    content = (
+      <form onSubmit={requestLink}>
+        <CardHeader className="space-y-1">
+          <CardTitle className="text-2xl font-bold">Sign in with email</CardTitle>
+          <CardDescription>We&apos;ll email you a link that signs you in, no password needed</CardDescription>
+        </CardHeader>
+        <CardContent className="space-y-2">
+          <Label htmlFor="email">Email</Label>
+          <Input
+            id="email"
+            type="email"
+            placeholder="name@example.com"
+            value={email}
+            onChange={(e) => setEmail(e.target.value)}
+            required
+          />
+        </CardContent>
+        <CardFooter className="flex flex-col space-y-4">
+          <Button type="submit" className="w-full" disabled={isLoading}>
+            {isLoading ? "Sending..." : "Send sign-in link"}
+          </Button>
+          <Link href="/login" className="text-sm text-center text-blue-600 hover:underline dark:text-blue-400">
+            Back to login
+          </Link>
+        </CardFooter>
+      </form>
+    );
+  }
+
+  return (
+    <div className="flex min-h-screen items-center justify-center bg-gradient-to-br from-blue-50 to-indigo-100 dark:from-gray-900 dark:to-gray-800 p-4">
+      <Card className="w-full max-w-md">{content}</Card>
+    </div>
+  );
+}
+
+export default function MagicLinkPage() {
+  return (
+    <Suspense>
+      <MagicLink />
+    </Suspense>
+  );
+}
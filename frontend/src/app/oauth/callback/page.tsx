@@ -0,0 +1,119 @@
+"use client";
+
+import { Suspense, useEffect, useRef, useState } from "react";
+import Link from "next/link";
+import { signIn } from "next-auth/react";
+import { useRouter, useSearchParams } from "next/navigation";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { Card, CardContent, CardDescription, CardFooter, CardHeader, CardTitle } from "@/components/ui/card";
+import { toast } from "sonner";
+
+const ERROR_MESSAGES: Record<string, string> = {
+  access_denied: "Sign-in was cancelled.",
+  email_unverified: "Your account with this provider has no verified email address.",
+  invalid_state: "This sign-in link has expired. Please try again.",
+  MFA_ENROLLMENT_REQUIRED: "Your account must set up two-factor authentication. Please contact an administrator.",
+};
+
+function OAuthCallback() {
+  const router = useRouter();
+  const searchParams = useSearchParams();
+  const started = useRef(false);
+  const [error, setError] = useState(searchParams.get("error"));
+  const [mfaToken, setMfaToken] = useState("");
+  const [code, setCode] = useState("");
+  const [isLoading, setIsLoading] = useState(false);
+
+  const finish = async (credentials: Record<string, string>) => {
+    const result = await signIn("credentials", { redirect: false, ...credentials });
+
+    if (result?.error?.startsWith("MFA_REQUIRED:")) {
+      setMfaToken(result.error.slice("MFA_REQUIRED:".length));
+    } else if (result?.error === "MFA_INVALID_CODE") {
+      toast.error("Invalid authentication code");
+    } else if (result?.error) {
+      setError(result.error);
+    } else {
+      router.refresh();
+      router.push("/dashboard");
+    }
+  };
+
+  useEffect(() => {
+    const oauthCode = searchParams.get("token");
+    // The code is single-use, so don't redeem it twice in development's double effects
+    if (!oauthCode || started.current) return;
+    started.current = true;
+    finish({ oauth_code: oauthCode });
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [searchParams]);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setIsLoading(true);
+    try {
+      await finish({ mfa_token: mfaToken, code });
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  return (
+    <div className="flex min-h-screen items-center justify-center bg-gradient-to-br from-blue-50 to-indigo-100 dark:from-gray-900 dark:to-gray-800 p-4">
+      <Card className="w-full max-w-md">
+        {error ? (
+          <>
+            <CardHeader className="space-y-1">
+              <CardTitle className="text-2xl font-bold">Sign-in failed</CardTitle>
+              <CardDescription>
+                {ERROR_MESSAGES[error] || "We couldn't sign you in with that account. Please try again."}
+              </CardDescription>
+            </CardHeader>
+            <CardFooter>
+              <Link href="/login" className="w-full">
+                <Button className="w-full">Back to login</Button>
+              </Link>
+            </CardFooter>
+          </>
+        ) : mfaToken ? (
+          <form onSubmit={handleSubmit}>
+            <CardHeader className="space-y-1">
+              <CardTitle className="text-2xl font-bold">Two-factor authentication</CardTitle>
+              <CardDescription>Enter the code from your authenticator app, or a recovery code</CardDescription>
+            </CardHeader>
+            <CardContent className="space-y-2">
+              <Label htmlFor="code">Authentication code</Label>
+              <Input
+                id="code"
+                autoComplete="one-time-code"
+                value={code}
+                onChange={(e) => setCode(e.target.value)}
+                autoFocus
+                required
+              />
+            </CardContent>
+            <CardFooter>
+              <Button type="submit" className="w-full" disabled={isLoading}>
+                {isLoading ? "Verifying..." : "Verify"}
+              </Button>
+            </CardFooter>
+          </form>
+        ) : (
+          <CardHeader className="space-y-1">
+            <CardTitle className="text-2xl font-bold">Signing you in...</CardTitle>
+          </CardHeader>
+        )}
+      </Card>
+    </div>
+  );
+}
+
+export default function OAuthCallbackPage() {
+  return (
+    <Suspense>
+      <OAuthCallback />
+    </Suspense>
+  );
+}
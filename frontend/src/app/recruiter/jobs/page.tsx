@@ -61,7 +61,7 @@ export default function RecruiterJobsPage() {
   const fetchJobs = async () => {
     try {
       const response = await jobApi.get("/api/jobs", {
-        params: { recruiter_id: session?.user?.id },
+        params: { member_id: session?.user?.id },
       });
       setJobs(response.data.jobs || []);
     } catch {
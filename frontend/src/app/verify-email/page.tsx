@@ -0,0 +1,67 @@
+"use client";
+
+import { Suspense, useEffect, useState } from "react";
+import Link from "next/link";
+import { useSearchParams } from "next/navigation";
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardDescription, CardFooter, CardHeader, CardTitle } from "@/components/ui/card";
+import { authApi } from "@/lib/api";
+
+type Status = "verifying" | "verified" | "failed";
+
+function VerifyEmail() {
+  const searchParams = useSearchParams();
+  const token = searchParams.get("token");
+  const [status, setStatus] = useState<Status>(token ? "verifying" : "failed");
+
+  useEffect(() => {
+    if (!token) return;
+    authApi
+      .post("/api/auth/verify-email", { token })
+      .then(() => setStatus("verified"))
+      .catch(() => setStatus("failed"));
+  }, [token]);
+
+  return (
+    <div className="flex min-h-screen items-center justify-center bg-gradient-to-br from-blue-50 to-indigo-100 dark:from-gray-900 dark:to-gray-800 p-4">
+      <Card className="w-full max-w-md">
+        <CardHeader className="space-y-1">
+          <CardTitle className="text-2xl font-bold">
+            {status === "verifying" && "Verifying your email..."}
+            {status === "verified" && "Email verified"}
+            {status === "failed" && "Verification failed"}
+          </CardTitle>
+          <CardDescription>
+            {status === "verified" && "Thanks for confirming your email address"}
+            {status === "failed" && "This link is invalid or has expired"}
+          </CardDescription>
+        </CardHeader>
+        <CardContent className="space-y-4">
+          {status === "verified" && (
+            <p className="text-sm text-gray-600 dark:text-gray-400">
+              Sign in again to unlock features that require a verified account.
+            </p>
+          )}
+          {status === "failed" && (
+            <p className="text-sm text-gray-600 dark:text-gray-400">
+              Verification links expire after 24 hours. Ask for a new one if yours has expired.
+            </p>
+          )}
+        </CardContent>
+        <CardFooter>
+          <Link href="/login" className="w-full">
+            <Button className="w-full">Go to login</Button>
+          </Link>
+        </CardFooter>
+      </Card>
+    </div>
+  );
+}
+
+export default function VerifyEmailPage() {
+  return (
+    <Suspense>
+      <VerifyEmail />
+    </Suspense>
+  );
+}
@@ -2,7 +2,7 @@ import axios, { AxiosInstance, AxiosRequestConfig, AxiosError } from "axios";
 import { getSession } from "next-auth/react";
 
 // API Base URLs
-const API_URLS = {
+export const API_URLS = {
   auth: process.env.NEXT_PUBLIC_AUTH_URL || "http://localhost:8001",
   user: process.env.NEXT_PUBLIC_USER_URL || "http://localhost:8002",
   job: process.env.NEXT_PUBLIC_JOB_URL || "http://localhost:8003",
@@ -7,6 +7,7 @@ declare module "next-auth" {
       role: "jobseeker" | "recruiter";
     };
     accessToken: string;
+    error?: "RefreshAccessTokenError";
   }
 
   interface User {
@@ -15,6 +16,8 @@ declare module "next-auth" {
     name: string;
     role: "jobseeker" | "recruiter";
     accessToken: string;
+    refreshToken: string;
+    accessTokenExpires: number;
   }
 }
 
@@ -23,6 +26,9 @@ declare module "next-auth/jwt" {
     id: string;
     role: "jobseeker" | "recruiter";
     accessToken: string;
+    refreshToken: string;
+    accessTokenExpires: number;
+    error?: "RefreshAccessTokenError";
   }
 }
 